@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/marco-arnold/lnka/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var completionCmd = &cobra.Command{
+	Use:   "completion [bash|zsh|fish|powershell]",
+	Short: "Generate the shell completion script",
+	Long: `Generate the completion script for the given shell and write it to stdout.
+
+  bash:       lnka completion bash > /etc/bash_completion.d/lnka
+  zsh:        lnka completion zsh > "${fpath[1]}/_lnka"
+  fish:       lnka completion fish > ~/.config/fish/completions/lnka.fish
+  powershell: lnka completion powershell | Out-String | Invoke-Expression`,
+	ValidArgs:             []string{"bash", "zsh", "fish", "powershell"},
+	Args:                  cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+	DisableFlagsInUseLine: true,
+	RunE:                  runCompletion,
+}
+
+func init() {
+	rootCmd.AddCommand(completionCmd)
+
+	// SOURCE and TARGET complete directory names
+	rootCmd.ValidArgsFunction = completeDirs(-1)
+	statusCmd.ValidArgsFunction = completeDirs(2)
+	undoCmd.ValidArgsFunction = completeDirs(1)
+}
+
+func runCompletion(cmd *cobra.Command, args []string) error {
+	out := os.Stdout
+	switch args[0] {
+	case "bash":
+		return rootCmd.GenBashCompletionV2(out, true)
+	case "zsh":
+		return rootCmd.GenZshCompletion(out)
+	case "fish":
+		return rootCmd.GenFishCompletion(out, true)
+	case "powershell":
+		return rootCmd.GenPowerShellCompletionWithDesc(out)
+	}
+	return fmt.Errorf("unsupported shell %q", args[0])
+}
+
+// completeDirs returns a completion function offering directories for the
+// first n positional arguments (n < 0 = all of them)
+func completeDirs(n int) cobra.CompletionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if n >= 0 && len(args) >= n {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return nil, cobra.ShellCompDirectiveFilterDirs
+	}
+}
+
+// completeProfiles offers the profile names of the config file
+// (--config when given, otherwise the default location)
+func completeProfiles(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	path, _ := cmd.Flags().GetString("config")
+	if path == "" {
+		var err error
+		path, err = config.DefaultConfigPath()
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+	}
+
+	fc, err := config.LoadFile(path)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	return fc.ProfileNames(), cobra.ShellCompDirectiveNoFileComp
+}
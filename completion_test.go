@@ -0,0 +1,47 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+// TestCompleteDirs tests completing directories only for the positional arguments
+func TestCompleteDirs(t *testing.T) {
+	complete := completeDirs(2)
+
+	if _, directive := complete(rootCmd, []string{"/src"}, ""); directive != cobra.ShellCompDirectiveFilterDirs {
+		t.Errorf("directive for TARGET = %v, want FilterDirs", directive)
+	}
+	if _, directive := complete(rootCmd, []string{"/src", "/dst"}, ""); directive != cobra.ShellCompDirectiveNoFileComp {
+		t.Errorf("directive after TARGET = %v, want NoFileComp", directive)
+	}
+
+	complete = completeDirs(-1)
+	if _, directive := complete(rootCmd, []string{"/src", "/dst"}, ""); directive != cobra.ShellCompDirectiveFilterDirs {
+		t.Errorf("directive for more targets = %v, want FilterDirs", directive)
+	}
+}
+
+// TestCompleteProfiles tests offering the profile names of the config file
+func TestCompleteProfiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	content := "profiles:\n  web:\n    source: /a\n    target: /b\n  dots:\n    source: /c\n    target: /d\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := &cobra.Command{}
+	cmd.Flags().String("config", path, "")
+
+	names, directive := completeProfiles(cmd, nil, "")
+	if want := []string{"dots", "web"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("completeProfiles() = %v, want %v", names, want)
+	}
+	if directive != cobra.ShellCompDirectiveNoFileComp {
+		t.Errorf("directive = %v, want NoFileComp", directive)
+	}
+}
@@ -0,0 +1,282 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/marco-arnold/lnka/internal/config"
+	"github.com/marco-arnold/lnka/internal/filesystem"
+	"github.com/spf13/cobra"
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor SOURCE TARGET",
+	Short: "Audit the target directory and exit non-zero if problems are found",
+	Args:  cobra.ExactArgs(2),
+	RunE:  quietRunE(runDoctor),
+}
+
+func init() {
+	doctorCmd.Flags().String("format", config.OutputText, "Output format: text or json")
+	doctorCmd.Flags().Bool("fix", false, "Repair the problems found: remove broken links, re-point mismatched links and replace shadowing files")
+	doctorCmd.Flags().BoolP("yes", "y", false, "Fix without asking for confirmation")
+	doctorCmd.ValidArgsFunction = completeDirs(2)
+	rootCmd.AddCommand(doctorCmd)
+}
+
+// doctorReport is the result of auditing a target directory
+type doctorReport struct {
+	Valid      int               `json:"valid"`      // Symlinks to source files
+	Broken     []string          `json:"broken"`     // Symlinks whose target is missing
+	Mismatched map[string]string `json:"mismatched"` // Symlinks pointing outside the source, with their target
+	Shadowed   []string          `json:"shadowed"`   // Regular files named like a source file
+	Unlinked   []string          `json:"unlinked"`   // Source files not linked yet (no problem)
+	Fixed      int               `json:"fixed"`      // Problems repaired by --fix
+}
+
+// problems returns the number of problems in the report
+func (r *doctorReport) problems() int {
+	return len(r.Broken) + len(r.Mismatched) + len(r.Shadowed)
+}
+
+func runDoctor(cmd *cobra.Command, args []string) error {
+	format, err := cmd.Flags().GetString("format")
+	if err != nil {
+		return fmt.Errorf("failed to get format flag: %w", err)
+	}
+	if format != config.OutputText && format != config.OutputJSON {
+		return fmt.Errorf("invalid format %q: expected %s or %s", format, config.OutputText, config.OutputJSON)
+	}
+	fix, err := cmd.Flags().GetBool("fix")
+	if err != nil {
+		return fmt.Errorf("failed to get fix flag: %w", err)
+	}
+	assumeYes, err := cmd.Flags().GetBool("yes")
+	if err != nil {
+		return fmt.Errorf("failed to get yes flag: %w", err)
+	}
+	if fix && !assumeYes && format == config.OutputJSON {
+		// The prompts would end up in the JSON output
+		return fmt.Errorf("--fix with --format %s needs --yes", config.OutputJSON)
+	}
+
+	sourceDir, targetDir := args[0], args[1]
+	if err := filesystem.CheckDirExists(sourceDir); err != nil {
+		return fmt.Errorf("source directory error: %w", err)
+	}
+	if err := filesystem.CheckDirExists(targetDir); err != nil {
+		return fmt.Errorf("target directory error: %w", err)
+	}
+
+	// Audit with the link options of the last apply to the target
+	opts := recordedOptions(sourceDir, targetDir)
+	report, err := collectDoctor(sourceDir, targetDir, opts)
+	if err != nil {
+		return err
+	}
+	if fix && report.problems() > 0 {
+		if format == config.OutputText {
+			if err := writeDoctor(os.Stdout, report, format); err != nil {
+				return err
+			}
+			fmt.Println()
+		}
+		fixed, err := fixDoctor(sourceDir, targetDir, report, opts, assumeYes)
+		if err != nil {
+			return err
+		}
+
+		// Report what is left after the repairs
+		report, err = collectDoctor(sourceDir, targetDir, opts)
+		if err != nil {
+			return err
+		}
+		report.Fixed = fixed
+	}
+
+	if err := writeDoctor(os.Stdout, report, format); err != nil {
+		return err
+	}
+	if report.problems() > 0 {
+		return &exitError{code: exitCodeProblems}
+	}
+	return nil
+}
+
+// collectDoctor audits the target directory against the source directory
+func collectDoctor(sourceDir, targetDir string, opts filesystem.Options) (*doctorReport, error) {
+	available, err := filesystem.ListAvailableFilesWithOptions(sourceDir, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list available files: %w", err)
+	}
+	enabled, err := filesystem.GetEnabledFilesWithOptions(sourceDir, targetDir, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get enabled files: %w", err)
+	}
+	broken, err := filesystem.ValidateSymlinksWithOptions(sourceDir, targetDir, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to validate symlinks: %w", err)
+	}
+	mismatched, err := filesystem.FindMismatchedSymlinksWithOptions(sourceDir, targetDir, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find mismatched symlinks: %w", err)
+	}
+	shadowed, err := filesystem.FindShadowFilesWithOptions(sourceDir, targetDir, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find shadow files: %w", err)
+	}
+
+	isLinked := make(map[string]bool, len(enabled)+len(shadowed))
+	for _, name := range enabled {
+		isLinked[name] = true
+	}
+	for _, name := range shadowed {
+		isLinked[name] = true
+	}
+
+	// Mismatched links occupy the name of a source file as well
+	unlinked := []string{}
+	for _, name := range available {
+		if _, ok := mismatched[name]; !ok && !isLinked[name] {
+			unlinked = append(unlinked, name)
+		}
+	}
+
+	sort.Strings(broken)
+	if broken == nil {
+		broken = []string{}
+	}
+	if shadowed == nil {
+		shadowed = []string{}
+	}
+	return &doctorReport{
+		Valid:      len(enabled),
+		Broken:     broken,
+		Mismatched: mismatched,
+		Shadowed:   shadowed,
+		Unlinked:   unlinked,
+	}, nil
+}
+
+// fixDoctor repairs the problems of the report, asking for each kind unless
+// assumeYes is set, and returns the number of problems repaired. Mismatched
+// links without a source file of the same name are left alone.
+func fixDoctor(sourceDir, targetDir string, report *doctorReport, opts filesystem.Options, assumeYes bool) (int, error) {
+	// The doctor has no --no-color flag, only NO_COLOR turns off styling
+	theme := config.Theme{NoColor: os.Getenv(config.NoColorEnvVar) != ""}
+	confirm := func(message string) (bool, error) {
+		if assumeYes {
+			return true, nil
+		}
+		return showConfirmationWithDefault(message, false, theme)
+	}
+
+	fixed := 0
+	if len(report.Broken) > 0 {
+		ok, err := confirm(fmt.Sprintf("Remove %d broken symlink(s)?", len(report.Broken)))
+		if err != nil {
+			return fixed, err
+		}
+		if ok {
+			if err := filesystem.CleanOrphanedSymlinks(targetDir, report.Broken); err != nil {
+				return fixed, fmt.Errorf("failed to remove broken symlinks: %w", err)
+			}
+			fixed += len(report.Broken)
+		}
+	}
+
+	repointable, err := findRepointable(sourceDir, targetDir, opts)
+	if err != nil {
+		return fixed, err
+	}
+	if len(repointable) > 0 {
+		ok, err := confirm(fmt.Sprintf("Re-point %d symlink(s) to the source directory?", len(repointable)))
+		if err != nil {
+			return fixed, err
+		}
+		if ok {
+			if err := filesystem.RepointSymlinks(sourceDir, targetDir, sortedKeys(repointable), opts); err != nil {
+				return fixed, err
+			}
+			fixed += len(repointable)
+		}
+	}
+
+	if len(report.Shadowed) > 0 {
+		ok, err := confirm(fmt.Sprintf("Replace %d shadowing file(s) with symlinks (keeping *%s backups)?",
+			len(report.Shadowed), filesystem.BackupSuffix))
+		if err != nil {
+			return fixed, err
+		}
+		if ok {
+			if err := filesystem.ReplaceShadowFiles(sourceDir, targetDir, report.Shadowed, opts); err != nil {
+				return fixed, err
+			}
+			fixed += len(report.Shadowed)
+		}
+	}
+
+	return fixed, nil
+}
+
+// writeDoctor writes the report as a summary per kind or as a JSON object
+func writeDoctor(w io.Writer, report *doctorReport, format string) error {
+	if format == config.OutputJSON {
+		data, err := json.Marshal(report)
+		if err != nil {
+			return fmt.Errorf("failed to encode report: %w", err)
+		}
+		_, err = fmt.Fprintln(w, string(data))
+		return err
+	}
+
+	fmt.Fprintf(w, "%d valid symlink(s)\n", report.Valid)
+	writeDoctorList(w, "broken symlink(s)", report.Broken)
+	mismatched := sortedKeys(report.Mismatched)
+	for i, name := range mismatched {
+		mismatched[i] = name + " -> " + report.Mismatched[name]
+	}
+	writeDoctorList(w, "symlink(s) pointing outside the source", mismatched)
+	writeDoctorList(w, "regular file(s) shadowing source files", report.Shadowed)
+	writeDoctorList(w, "source file(s) not linked", report.Unlinked)
+
+	if report.Fixed > 0 {
+		fmt.Fprintf(w, "Fixed %d problem(s)\n", report.Fixed)
+	}
+	switch problems := report.problems(); {
+	case problems == 0:
+		_, err := fmt.Fprintln(w, "No problems found")
+		return err
+	case report.Fixed > 0:
+		_, err := fmt.Fprintf(w, "%d problem(s) left\n", problems)
+		return err
+	default:
+		_, err := fmt.Fprintf(w, "Found %d problem(s), run with --fix to repair them\n", problems)
+		return err
+	}
+}
+
+// writeDoctorList writes the count and the names of one kind of finding,
+// nothing if there are none
+func writeDoctorList(w io.Writer, label string, names []string) {
+	if len(names) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "%d %s:\n", len(names), label)
+	for _, name := range names {
+		fmt.Fprintf(w, "  - %s\n", name)
+	}
+}
+
+// sortedKeys returns the keys of the map in sorted order
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/marco-arnold/lnka/internal/config"
+	"github.com/marco-arnold/lnka/internal/filesystem"
+	"github.com/marco-arnold/lnka/internal/testutil"
+)
+
+// setupDoctorDirs creates a target with one of each finding: a valid link
+// (a.conf), a broken link (gone.conf), a link outside the source (b.conf),
+// a shadowing file (c.conf) and an unlinked source file (d.conf)
+func setupDoctorDirs(t *testing.T) (sourceDir, targetDir string) {
+	t.Helper()
+
+	sourceDir, targetDir, otherDir := t.TempDir(), t.TempDir(), t.TempDir()
+	for _, name := range []string{"a.conf", "b.conf", "c.conf", "d.conf"} {
+		if err := os.WriteFile(filepath.Join(sourceDir, name), []byte("source"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(otherDir, "b.conf"), []byte("other"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(targetDir, "c.conf"), []byte("local"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	links := map[string]string{
+		"a.conf":    filepath.Join(sourceDir, "a.conf"),
+		"gone.conf": filepath.Join(sourceDir, "gone.conf"),
+		"b.conf":    filepath.Join(otherDir, "b.conf"),
+	}
+	for name, target := range links {
+		if err := os.Symlink(target, filepath.Join(targetDir, name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return sourceDir, targetDir
+}
+
+// TestCollectDoctor tests the findings of each kind
+func TestCollectDoctor(t *testing.T) {
+	sourceDir, targetDir := setupDoctorDirs(t)
+
+	report, err := collectDoctor(sourceDir, targetDir, filesystem.Options{})
+	if err != nil {
+		t.Fatalf("collectDoctor failed: %v", err)
+	}
+	if report.Valid != 1 {
+		t.Errorf("Valid = %d, want 1", report.Valid)
+	}
+	if want := []string{"gone.conf"}; !reflect.DeepEqual(report.Broken, want) {
+		t.Errorf("Broken = %v, want %v", report.Broken, want)
+	}
+	if _, ok := report.Mismatched["b.conf"]; !ok || len(report.Mismatched) != 1 {
+		t.Errorf("Mismatched = %v, want b.conf", report.Mismatched)
+	}
+	if want := []string{"c.conf"}; !reflect.DeepEqual(report.Shadowed, want) {
+		t.Errorf("Shadowed = %v, want %v", report.Shadowed, want)
+	}
+	if want := []string{"d.conf"}; !reflect.DeepEqual(report.Unlinked, want) {
+		t.Errorf("Unlinked = %v, want %v", report.Unlinked, want)
+	}
+	if report.problems() != 3 {
+		t.Errorf("problems() = %d, want 3", report.problems())
+	}
+}
+
+// TestCollectDoctor_RecordedOptions tests that the audit uses the link options
+// of the last apply to the target
+func TestCollectDoctor_RecordedOptions(t *testing.T) {
+	testutil.UseTempConfigDir(t)
+
+	sourceDir, targetDir := setupDoctorDirs(t)
+	absSource, err := filepath.Abs(sourceDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	record := filesystem.UndoRecord{SourceDir: absSource, Exclude: []string{"d.*"}}
+	if err := filesystem.SaveUndoRecord(targetDir, record); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := collectDoctor(sourceDir, targetDir, recordedOptions(sourceDir, targetDir))
+	if err != nil {
+		t.Fatalf("collectDoctor failed: %v", err)
+	}
+	if len(report.Unlinked) != 0 {
+		t.Errorf("Unlinked = %v, want none (d.conf is excluded)", report.Unlinked)
+	}
+}
+
+// TestWriteDoctor tests the text and JSON reports
+func TestWriteDoctor(t *testing.T) {
+	report := &doctorReport{
+		Valid:      2,
+		Broken:     []string{"gone.conf"},
+		Mismatched: map[string]string{"b.conf": "/old/b.conf"},
+		Shadowed:   []string{},
+		Unlinked:   []string{"d.conf"},
+	}
+
+	var text bytes.Buffer
+	if err := writeDoctor(&text, report, config.OutputText); err != nil {
+		t.Fatalf("writeDoctor(text) failed: %v", err)
+	}
+	want := strings.Join([]string{
+		"2 valid symlink(s)",
+		"1 broken symlink(s):",
+		"  - gone.conf",
+		"1 symlink(s) pointing outside the source:",
+		"  - b.conf -> /old/b.conf",
+		"1 source file(s) not linked:",
+		"  - d.conf",
+		"Found 2 problem(s), run with --fix to repair them",
+	}, "\n") + "\n"
+	if text.String() != want {
+		t.Errorf("text output = %q, want %q", text.String(), want)
+	}
+
+	var js bytes.Buffer
+	if err := writeDoctor(&js, report, config.OutputJSON); err != nil {
+		t.Fatalf("writeDoctor(json) failed: %v", err)
+	}
+	wantJSON := `{"valid":2,"broken":["gone.conf"],"mismatched":{"b.conf":"/old/b.conf"},"shadowed":[],"unlinked":["d.conf"],"fixed":0}` + "\n"
+	if js.String() != wantJSON {
+		t.Errorf("json output = %s, want %s", js.String(), wantJSON)
+	}
+}
+
+// TestRunDoctor tests the exit codes of an audit and of fixing its problems
+func TestRunDoctor(t *testing.T) {
+	sourceDir, targetDir := setupDoctorDirs(t)
+
+	got, _, stderr := runLnkaOutput(t, "doctor", sourceDir, targetDir)
+	if got != exitCodeProblems {
+		t.Errorf("doctor exit code = %d, want %d", got, exitCodeProblems)
+	}
+	if strings.Contains(stderr, "Usage:") || strings.Contains(stderr, "exit code") {
+		t.Errorf("doctor stderr = %q, want no usage or error", stderr)
+	}
+
+	// Declining a fix leaves its problem
+	stubPrompts(t, nil, nil, false)
+	if got := runLnka(t, "doctor", sourceDir, targetDir, "--fix"); got != exitCodeProblems {
+		t.Errorf("declined fix exit code = %d, want %d", got, exitCodeProblems)
+	}
+
+	if got := runLnka(t, "doctor", sourceDir, targetDir, "--fix", "--yes", "--format", "json"); got != exitCodeOK {
+		t.Errorf("fix exit code = %d, want %d", got, exitCodeOK)
+	}
+	report, err := collectDoctor(sourceDir, targetDir, filesystem.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.problems() != 0 || report.Valid != 3 {
+		t.Errorf("report after fix = %+v, want 3 valid links and no problems", report)
+	}
+	if _, err := os.Stat(filepath.Join(targetDir, "c.conf.bak")); err != nil {
+		t.Errorf("shadowing file should be kept as a backup: %v", err)
+	}
+
+	if got := runLnka(t, "doctor", sourceDir, targetDir, "--fix", "--format", "json"); got != exitCodeError {
+		t.Errorf("json fix without --yes exit code = %d, want %d", got, exitCodeError)
+	}
+}
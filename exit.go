@@ -0,0 +1,80 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/marco-arnold/lnka/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// Exit codes of lnka (exitCodeChangesPending is used by --detailed-exitcode)
+const (
+	exitCodeOK        = 0   // Success, also when nothing had to change
+	exitCodeError     = 1   // Any other error
+	exitCodeConflicts = 2   // Conflicting files were not overwritten
+	exitCodeProblems  = 3   // lnka doctor found problems
+	exitCodeAborted   = 130 // Aborted with ctrl+c (128 + SIGINT, like a shell reports an interrupted command)
+)
+
+// exitError ends a run with a specific exit code
+type exitError struct {
+	code int
+	err  error // Error to report (nil = exit quietly)
+}
+
+func (e *exitError) Error() string {
+	if e.err == nil {
+		return fmt.Sprintf("exit code %d", e.code)
+	}
+	return e.err.Error()
+}
+
+func (e *exitError) Unwrap() error {
+	return e.err
+}
+
+// exitCode returns the exit code ending a run with err
+func exitCode(err error) int {
+	var exit *exitError
+	switch {
+	case err == nil:
+		return exitCodeOK
+	case errors.Is(err, ui.ErrUserAborted):
+		return exitCodeAborted
+	case errors.As(err, &exit):
+		return exit.code
+	default:
+		return exitCodeError
+	}
+}
+
+// quietExit keeps cobra from printing usage for runs ending with an abort or
+// a specific exit code, and from printing errors that carry no message
+func quietExit(cmd *cobra.Command, err error) {
+	var exit *exitError
+	switch {
+	case errors.Is(err, ui.ErrUserAborted):
+		cmd.SilenceUsage, cmd.SilenceErrors = true, true
+	case errors.As(err, &exit):
+		cmd.SilenceUsage, cmd.SilenceErrors = true, exit.err == nil
+	default:
+		cmd.SilenceUsage, cmd.SilenceErrors = false, false
+	}
+}
+
+// quietRunE wraps run as a RunE, applying quietExit to the error it returns
+func quietRunE(run func(*cobra.Command, []string) error) func(*cobra.Command, []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		err := run(cmd, args)
+		quietExit(cmd, err)
+		return err
+	}
+}
+
+// execute runs the command with the given arguments and returns the exit code
+func execute(cmd *cobra.Command, args []string) int {
+	cmd.SetArgs(args)
+	_, err := cmd.ExecuteC()
+	return exitCode(err)
+}
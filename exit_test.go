@@ -0,0 +1,175 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/marco-arnold/lnka/internal/config"
+	"github.com/marco-arnold/lnka/internal/testutil"
+	"github.com/marco-arnold/lnka/internal/ui"
+	"github.com/spf13/pflag"
+)
+
+// runLnka runs the root command with args and returns its exit code, its
+// output is discarded
+func runLnka(t *testing.T, args ...string) int {
+	t.Helper()
+
+	code, _, _ := runLnkaOutput(t, args...)
+	return code
+}
+
+// tempConfigTests are the tests whose runs already share a temp config dir
+var tempConfigTests = map[*testing.T]bool{}
+
+// runLnkaOutput runs the root command with args and returns its exit code and
+// what it wrote to stdout and stderr. Flags (also those of the subcommands)
+// are reset first. All runs of a test share a temp config dir, so like for a
+// user a later run sees the undo journal of an earlier one.
+func runLnkaOutput(t *testing.T, args ...string) (code int, stdout, stderr string) {
+	t.Helper()
+
+	if !tempConfigTests[t] {
+		testutil.UseTempConfigDir(t)
+		tempConfigTests[t] = true
+		t.Cleanup(func() { delete(tempConfigTests, t) })
+	}
+
+	resetFlag := func(f *pflag.Flag) {
+		if !f.Changed {
+			return
+		}
+		if slice, ok := f.Value.(pflag.SliceValue); ok {
+			_ = slice.Replace(nil)
+		} else {
+			_ = f.Value.Set(f.DefValue)
+		}
+		f.Changed = false
+	}
+	rootCmd.Flags().VisitAll(resetFlag)
+	for _, cmd := range rootCmd.Commands() {
+		cmd.Flags().VisitAll(resetFlag)
+	}
+
+	// cobra prints errors and usage to os.Stderr, as no writer is set
+	outputDir := t.TempDir()
+	capture := func(name string) *os.File {
+		f, err := os.Create(filepath.Join(outputDir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return f
+	}
+	outFile, errFile := capture("stdout"), capture("stderr")
+	oldStdout, oldStderr := os.Stdout, os.Stderr
+	os.Stdout, os.Stderr = outFile, errFile
+	code = execute(rootCmd, args)
+	os.Stdout, os.Stderr = oldStdout, oldStderr
+	outFile.Close()
+	errFile.Close()
+
+	out, err := os.ReadFile(outFile.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	errOut, err := os.ReadFile(errFile.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return code, string(out), string(errOut)
+}
+
+// stubPrompts replaces the interactive prompts for the duration of the test
+func stubPrompts(t *testing.T, selected []string, selectErr error, confirmed bool) {
+	t.Helper()
+
+	oldFileSelect, oldConfirmation, oldConfirmationWithDefault := showFileSelect, showConfirmation, showConfirmationWithDefault
+	t.Cleanup(func() {
+		showFileSelect, showConfirmation, showConfirmationWithDefault = oldFileSelect, oldConfirmation, oldConfirmationWithDefault
+	})
+	showFileSelect = func(string, string, string, ui.FileSelectOptions) ([]string, error) {
+		return selected, selectErr
+	}
+	showConfirmation = func(string, config.Theme) (bool, error) {
+		return confirmed, nil
+	}
+	showConfirmationWithDefault = func(string, bool, config.Theme) (bool, error) {
+		return confirmed, nil
+	}
+}
+
+// TestExitCode tests the mapping of run errors to exit codes
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{name: "success", err: nil, want: exitCodeOK},
+		{name: "error", err: errors.New("boom"), want: exitCodeError},
+		{name: "abort", err: ui.ErrUserAborted, want: exitCodeAborted},
+		{name: "wrapped abort", err: fmt.Errorf("failed to pick: %w", ui.ErrUserAborted), want: exitCodeAborted},
+		{name: "conflicts", err: &exitError{code: exitCodeConflicts}, want: exitCodeConflicts},
+		{name: "pending changes", err: &exitError{code: exitCodeChangesPending}, want: exitCodeChangesPending},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := exitCode(tt.err); got != tt.want {
+				t.Errorf("exitCode() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestExecute_ExitCodes tests the exit codes of complete runs
+func TestExecute_ExitCodes(t *testing.T) {
+	tests := []struct {
+		name      string
+		conflict  bool // Put a regular file named a.conf in the target
+		selectErr error
+		confirmed bool
+		args      []string
+		want      int
+	}{
+		{name: "applied selection", want: exitCodeOK},
+		{name: "nothing to change", args: []string{"--disable", "b.conf"}, want: exitCodeOK},
+		{name: "aborted selection", selectErr: ui.ErrUserAborted, want: exitCodeAborted},
+		{name: "failed selection", selectErr: errors.New("no terminal"), want: exitCodeError},
+		{name: "declined conflicts", conflict: true, want: exitCodeConflicts},
+		{name: "confirmed conflicts", conflict: true, confirmed: true, want: exitCodeOK},
+		{name: "refused conflicts", conflict: true, args: []string{"--enable", "a.conf"}, want: exitCodeConflicts},
+		{name: "pending changes", args: []string{"--dry-run", "--detailed-exitcode", "--enable", "a.conf"}, want: exitCodeChangesPending},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sourceDir, targetDir := t.TempDir(), t.TempDir()
+			if err := os.WriteFile(filepath.Join(sourceDir, "a.conf"), []byte("a"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if tt.conflict {
+				if err := os.WriteFile(filepath.Join(targetDir, "a.conf"), []byte("local"), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			stubPrompts(t, []string{"a.conf"}, tt.selectErr, tt.confirmed)
+
+			args := append([]string{sourceDir, targetDir}, tt.args...)
+			if got := runLnka(t, args...); got != tt.want {
+				t.Errorf("exit code = %d, want %d", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("missing source", func(t *testing.T) {
+		stubPrompts(t, nil, nil, false)
+		missing := filepath.Join(t.TempDir(), "missing")
+		if got := runLnka(t, missing, t.TempDir()); got != exitCodeError {
+			t.Errorf("exit code = %d, want %d", got, exitCodeError)
+		}
+	})
+}
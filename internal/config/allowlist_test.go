@@ -0,0 +1,35 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// TestLoadAllowlist tests reading names while skipping blanks and comments
+func TestLoadAllowlist(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "allowlist.txt")
+	content := "# links lnka may remove\napp.conf\n\n  db.conf  \n#old.conf\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create allowlist: %v", err)
+	}
+
+	allowlist, err := LoadAllowlist(path)
+	if err != nil {
+		t.Fatalf("LoadAllowlist() unexpected error = %v", err)
+	}
+
+	expected := map[string]bool{"app.conf": true, "db.conf": true}
+	if !reflect.DeepEqual(allowlist, expected) {
+		t.Errorf("LoadAllowlist() = %v, want %v", allowlist, expected)
+	}
+}
+
+// TestLoadAllowlist_Missing tests that a missing allowlist is an error
+func TestLoadAllowlist_Missing(t *testing.T) {
+	_, err := LoadAllowlist(filepath.Join(t.TempDir(), "nonexistent.txt"))
+	if err == nil {
+		t.Error("LoadAllowlist() expected error for missing file")
+	}
+}
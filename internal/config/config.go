@@ -4,15 +4,108 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/marco-arnold/lnka/internal/filesystem"
 	"github.com/spf13/cobra"
 )
 
+// TitleEnvVar is the environment variable providing the default title
+const TitleEnvVar = "LNKA_TITLE"
+
+// Sort keys for --sort
+const (
+	SortName    = "name"
+	SortModTime = "mtime"
+	SortSize    = "size"
+)
+
+// Filter modes for --filter-mode
+const (
+	FilterFuzzy = "fuzzy"
+	FilterRegex = "regex"
+)
+
 // Config holds the application configuration
 type Config struct {
-	SourceDir string
-	TargetDir string
-	Title     string
+	SourceDir  string
+	TargetDir  string
+	TargetDirs []string // All target directories getting the selection, TargetDir first (set by Load)
+	AlsoCheck  []string // Directories managed elsewhere whose links to source files are shown
+	Title      string
+	TagsFile   string   // Optional JSON file mapping file names to tags
+	Include    []string // Glob patterns restricting the source files (empty = all)
+	Exclude    []string // Glob patterns of source files to ignore
+	Recap      bool     // Print a one-line recap of directories and counts before the UI
+
+	CheckboxASCII bool   // Render ASCII checkboxes instead of unicode glyphs
+	Sort          string // Initial sort order of the UI (name, mtime or size)
+	EnabledFirst  bool   // Group the enabled files before the others in the UI
+	NoMouse       bool   // Disable mouse support in the UI
+	Watch         bool   // Refresh the UI list when the source or target changes
+	FilterMode    string // Initial matching of the / filter (fuzzy or regex)
+	CaseSensitive bool   // Whether the / filter matches the case of the term
+	ContentSearch bool   // Whether the / filter also matches the beginning of the file contents
+	PageSize      int    // Items per page of the UI (0 = fit the window)
+	ColorCursor   string // Color of the item under the cursor (empty = LNKA_THEME or default)
+	ColorLinked   string // Color of linked items (empty = LNKA_THEME or default)
+	ColorUnlinked string // Color of unlinked items (empty = LNKA_THEME or default)
+	NoColor       bool   // Render the UI without colors and styling (also set by NO_COLOR)
+
+	// Selection input/output
+	SelectJSON     string // Read the selection as JSON from this file ("-" = stdin) instead of the UI
+	Stdin          bool   // Read the selection from stdin, one name per line, instead of the UI
+	IgnoreMissing  bool   // Skip --stdin names missing from the source instead of failing
+	PrintSelection bool   // Print the selection instead of applying it
+	Output         string // Output format of the printed selection and the summary of applied changes (text or json)
+	Quiet          bool   // Print nothing but errors and warnings (only without the UI)
+	AllowOpen      bool   // Enable the key revealing a link in the file manager
+
+	// Non-interactive selection (replaces the UI when any is set)
+	Enable    []string // Files to link in addition to the current links
+	Disable   []string // Files to unlink
+	EnableAll bool     // Link every available file
+
+	// Presets
+	Preset      string // Named preset preselected in the UI
+	ApplyPreset bool   // Apply the preset directly instead of showing the UI
+
+	// Apply behavior
+	DryRun           bool                      // Report planned changes without touching the filesystem
+	DetailedExitCode bool                      // Exit with a distinct code when a dry run finds pending changes
+	Bootstrap        bool                      // Only create symlinks on a target without managed symlinks
+	Mkdir            bool                      // Create a missing target directory
+	Recursive        bool                      // Manage source and target subdirectories recursively
+	Dirs             bool                      // List source directories too, linking them as a whole
+	Hidden           bool                      // Include source files starting with a dot
+	Follow           bool                      // Follow symlink chains when detecting enabled files
+	Timeout          time.Duration             // Time limit for each scan of the source and target (0 = no limit)
+	PruneEmptyDirs   bool                      // Remove target subdirectories left empty by removals
+	SourceMode       os.FileMode               // Permission bits enforced on linked source files (0 = disabled)
+	LinkPrefix       string                    // Fixed prefix used as symlink target directory (empty = computed)
+	LinkStyle        filesystem.LinkStyle      // Auto, relative or absolute symlink targets
+	MaxUpLevels      int                       // Levels up before relative targets turn absolute (-1 = no limit, 0 = default of the style)
+	Rename           *filesystem.RenamePattern // Derives link names from source names (nil = same name)
+	LinkMode         filesystem.LinkMode       // Symlink, copy or hard link selected files
+	Backup           bool                      // Move regular files in place of new links to a backup
+	AllowlistFile    string                    // Optional file listing the symlink names that may be removed
+	Strict           bool                      // Treat warnings as errors
+	Verbose          bool                      // Log each filesystem operation to stderr
+	Add              bool                      // Only add selected links, never remove existing ones
+	VerifyAfter      bool                      // Check for dangling symlinks after applying
+	OnlyChanged      bool                      // Relink enabled files whose source is newer than the link
+	EmitSystemd      bool                      // Print the plan as systemctl commands instead of applying it
+	IncludeShadows   bool                      // Treat regular files shadowing source files as orphans
+	ContinueOnError  bool                      // Keep applying remaining changes after a failure
+	Normalize        bool                      // Rewrite links into the source to the canonical relative form
+
+	// Confirmation prompts
+	ConfirmApply  bool // Ask for confirmation with a change summary after confirming the UI
+	AssumeYes     bool // Answer yes to all confirmation prompts
+	AllowTeardown bool // Allow removing all managed symlinks without confirmation
 }
 
 // Load loads configuration from cobra command
@@ -28,6 +121,12 @@ func Load(cmd *cobra.Command, args []string) (*Config, error) {
 		cfg.TargetDir = args[1]
 	}
 
+	// Further arguments are more targets getting the same selection
+	// (e.g. a shell-expanded glob like /etc/nginx/*-enabled)
+	if len(args) > 2 {
+		cfg.TargetDirs = args[1:]
+	}
+
 	// Get flags
 	var err error
 	cfg.Title, err = cmd.Flags().GetString("title")
@@ -35,6 +134,401 @@ func Load(cmd *cobra.Command, args []string) (*Config, error) {
 		return nil, fmt.Errorf("failed to get title flag: %w", err)
 	}
 
+	// Fill remaining values from a config file profile
+	// Precedence: CLI args > env vars > config file > defaults
+	profileName, err := stringFlag(cmd, "profile")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get profile flag: %w", err)
+	}
+	if profileName != "" {
+		if err := cfg.applyProfile(cmd, profileName); err != nil {
+			return nil, err
+		}
+	}
+
+	cfg.Recap, err = boolFlag(cmd, "recap")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recap flag: %w", err)
+	}
+
+	cfg.CheckboxASCII, err = boolFlag(cmd, "checkbox-ascii")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get checkbox-ascii flag: %w", err)
+	}
+
+	cfg.NoMouse, err = boolFlag(cmd, "no-mouse")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get no-mouse flag: %w", err)
+	}
+
+	cfg.Watch, err = boolFlag(cmd, "watch")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get watch flag: %w", err)
+	}
+
+	cfg.Sort, err = stringFlag(cmd, "sort")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sort flag: %w", err)
+	}
+	if cfg.Sort == "" {
+		cfg.Sort = SortName
+	}
+	if cfg.Sort != SortName && cfg.Sort != SortModTime && cfg.Sort != SortSize {
+		return nil, fmt.Errorf("invalid sort key %q: expected %s, %s or %s", cfg.Sort, SortName, SortModTime, SortSize)
+	}
+
+	cfg.ColorCursor, err = stringFlag(cmd, "color-cursor")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get color-cursor flag: %w", err)
+	}
+
+	cfg.ColorLinked, err = stringFlag(cmd, "color-linked")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get color-linked flag: %w", err)
+	}
+
+	cfg.ColorUnlinked, err = stringFlag(cmd, "color-unlinked")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get color-unlinked flag: %w", err)
+	}
+
+	cfg.NoColor, err = boolFlag(cmd, "no-color")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get no-color flag: %w", err)
+	}
+
+	cfg.FilterMode, err = stringFlag(cmd, "filter-mode")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get filter-mode flag: %w", err)
+	}
+	if cfg.FilterMode == "" {
+		cfg.FilterMode = FilterFuzzy
+	}
+	if cfg.FilterMode != FilterFuzzy && cfg.FilterMode != FilterRegex {
+		return nil, fmt.Errorf("invalid filter mode %q: expected %s or %s", cfg.FilterMode, FilterFuzzy, FilterRegex)
+	}
+
+	cfg.EnabledFirst, err = boolFlag(cmd, "enabled-first")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get enabled-first flag: %w", err)
+	}
+
+	cfg.CaseSensitive, err = boolFlag(cmd, "case-sensitive")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get case-sensitive flag: %w", err)
+	}
+
+	cfg.ContentSearch, err = boolFlag(cmd, "content-search")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get content-search flag: %w", err)
+	}
+
+	cfg.PageSize, err = intFlag(cmd, "page-size")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get page-size flag: %w", err)
+	}
+	if cfg.PageSize < 0 {
+		return nil, fmt.Errorf("invalid --page-size %d: expected a positive number or 0 to fit the window", cfg.PageSize)
+	}
+
+	cfg.AllowOpen, err = boolFlag(cmd, "allow-open")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get allow-open flag: %w", err)
+	}
+
+	cfg.DryRun, err = boolFlag(cmd, "dry-run")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dry-run flag: %w", err)
+	}
+
+	cfg.DetailedExitCode, err = boolFlag(cmd, "detailed-exitcode")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get detailed-exitcode flag: %w", err)
+	}
+
+	cfg.Bootstrap, err = boolFlag(cmd, "bootstrap")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bootstrap flag: %w", err)
+	}
+
+	cfg.Mkdir, err = boolFlag(cmd, "mkdir")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get mkdir flag: %w", err)
+	}
+
+	cfg.Recursive, err = boolFlag(cmd, "recursive")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recursive flag: %w", err)
+	}
+
+	cfg.Hidden, err = boolFlag(cmd, "all")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get all flag: %w", err)
+	}
+
+	cfg.Follow, err = boolFlag(cmd, "follow")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get follow flag: %w", err)
+	}
+
+	cfg.Timeout, err = durationFlag(cmd, "timeout")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get timeout flag: %w", err)
+	}
+	if cfg.Timeout < 0 {
+		return nil, fmt.Errorf("invalid --timeout %s: must not be negative", cfg.Timeout)
+	}
+
+	cfg.Dirs, err = boolFlag(cmd, "dirs")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dirs flag: %w", err)
+	}
+	if cfg.Dirs && cfg.Recursive {
+		return nil, fmt.Errorf("--dirs cannot be combined with --recursive")
+	}
+
+	cfg.PruneEmptyDirs, err = boolFlag(cmd, "prune-empty-dirs")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get prune-empty-dirs flag: %w", err)
+	}
+
+	cfg.LinkPrefix, err = stringFlag(cmd, "link-prefix")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get link-prefix flag: %w", err)
+	}
+
+	renamePattern, err := stringFlag(cmd, "rename-pattern")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rename-pattern flag: %w", err)
+	}
+	if renamePattern != "" {
+		cfg.Rename, err = filesystem.ParseRenamePattern(renamePattern)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	style, err := stringFlag(cmd, "link-style")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get link-style flag: %w", err)
+	}
+	cfg.LinkStyle, err = filesystem.ParseLinkStyle(style)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg.MaxUpLevels, err = intFlag(cmd, "max-up-levels")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get max-up-levels flag: %w", err)
+	}
+	if cmd.Flags().Changed("max-up-levels") && (cfg.MaxUpLevels == 0 || cfg.MaxUpLevels < -1) {
+		return nil, fmt.Errorf("invalid --max-up-levels %d: expected a positive number or -1 for no limit", cfg.MaxUpLevels)
+	}
+
+	mode, err := stringFlag(cmd, "mode")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get mode flag: %w", err)
+	}
+	cfg.LinkMode, err = filesystem.ParseLinkMode(mode)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Dirs && cfg.LinkMode != filesystem.LinkModeSymlink {
+		return nil, fmt.Errorf("--dirs only works with --mode %s", filesystem.LinkModeSymlink)
+	}
+
+	cfg.AllowlistFile, err = stringFlag(cmd, "removable-allowlist")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get removable-allowlist flag: %w", err)
+	}
+
+	cfg.Strict, err = boolFlag(cmd, "strict")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get strict flag: %w", err)
+	}
+
+	cfg.Verbose, err = boolFlag(cmd, "verbose")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get verbose flag: %w", err)
+	}
+
+	cfg.Add, err = boolFlag(cmd, "add")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get add flag: %w", err)
+	}
+
+	cfg.EmitSystemd, err = boolFlag(cmd, "emit-systemd")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get emit-systemd flag: %w", err)
+	}
+
+	cfg.OnlyChanged, err = boolFlag(cmd, "only-changed")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get only-changed flag: %w", err)
+	}
+
+	cfg.VerifyAfter, err = boolFlag(cmd, "verify-after")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get verify-after flag: %w", err)
+	}
+
+	cfg.IncludeShadows, err = boolFlag(cmd, "include-shadows-as-orphans")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get include-shadows-as-orphans flag: %w", err)
+	}
+
+	cfg.ContinueOnError, err = boolFlag(cmd, "continue-on-error")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get continue-on-error flag: %w", err)
+	}
+
+	cfg.Backup, err = boolFlag(cmd, "backup")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get backup flag: %w", err)
+	}
+
+	cfg.Normalize, err = boolFlag(cmd, "normalize")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get normalize flag: %w", err)
+	}
+
+	cfg.ConfirmApply, err = boolFlag(cmd, "confirm")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get confirm flag: %w", err)
+	}
+
+	cfg.AssumeYes, err = boolFlag(cmd, "yes")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get yes flag: %w", err)
+	}
+
+	cfg.AllowTeardown, err = boolFlag(cmd, "allow-teardown")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get allow-teardown flag: %w", err)
+	}
+
+	sourceMode, err := stringFlag(cmd, "enforce-source-mode")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get enforce-source-mode flag: %w", err)
+	}
+	if sourceMode != "" {
+		cfg.SourceMode, err = parseFileMode(sourceMode)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	cfg.SelectJSON, err = stringFlag(cmd, "select-json")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get select-json flag: %w", err)
+	}
+
+	cfg.Stdin, err = boolFlag(cmd, "stdin")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stdin flag: %w", err)
+	}
+
+	cfg.IgnoreMissing, err = boolFlag(cmd, "ignore-missing")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ignore-missing flag: %w", err)
+	}
+
+	cfg.Enable, err = stringSliceFlag(cmd, "enable")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get enable flag: %w", err)
+	}
+
+	cfg.Disable, err = stringSliceFlag(cmd, "disable")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get disable flag: %w", err)
+	}
+
+	cfg.EnableAll, err = boolFlag(cmd, "enable-all")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get enable-all flag: %w", err)
+	}
+
+	cfg.Preset, err = stringFlag(cmd, "preset")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get preset flag: %w", err)
+	}
+
+	cfg.ApplyPreset, err = boolFlag(cmd, "apply")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get apply flag: %w", err)
+	}
+	if cfg.ApplyPreset && cfg.Preset == "" {
+		return nil, fmt.Errorf("--apply requires --preset")
+	}
+
+	if cfg.Stdin && (len(cfg.Enable) > 0 || len(cfg.Disable) > 0 || cfg.EnableAll || cfg.ApplyPreset) {
+		return nil, fmt.Errorf("--stdin cannot be combined with --enable, --disable, --enable-all or --apply")
+	}
+	if cfg.NonInteractive() && cfg.SelectJSON != "" {
+		return nil, fmt.Errorf("--select-json cannot be combined with --stdin, --enable, --disable, --enable-all or --apply")
+	}
+	if cfg.IgnoreMissing && !cfg.Stdin {
+		return nil, fmt.Errorf("--ignore-missing requires --stdin")
+	}
+
+	cfg.PrintSelection, err = boolFlag(cmd, "print-selection")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get print-selection flag: %w", err)
+	}
+
+	cfg.Output, err = stringFlag(cmd, "format")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get format flag: %w", err)
+	}
+	if cfg.Output == "" {
+		cfg.Output = OutputText
+	}
+	if cfg.Output != OutputText && cfg.Output != OutputJSON {
+		return nil, fmt.Errorf("invalid output format %q: expected %s or %s", cfg.Output, OutputText, OutputJSON)
+	}
+
+	cfg.Quiet, err = boolFlag(cmd, "quiet")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get quiet flag: %w", err)
+	}
+	if cfg.Quiet && !cfg.NonInteractive() && cfg.SelectJSON == "" {
+		return nil, fmt.Errorf("--quiet requires a selection without the UI: --enable, --disable, --enable-all, --stdin, --apply or --select-json")
+	}
+	if cfg.Quiet && (cfg.PrintSelection || cfg.Output == OutputJSON) {
+		return nil, fmt.Errorf("--quiet cannot be combined with --print-selection or --format json")
+	}
+
+	cfg.Include, err = stringSliceFlag(cmd, "include")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get include flag: %w", err)
+	}
+	if err := filesystem.ValidatePatterns(cfg.Include); err != nil {
+		return nil, fmt.Errorf("invalid include filter: %w", err)
+	}
+
+	cfg.Exclude, err = stringSliceFlag(cmd, "exclude")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get exclude flag: %w", err)
+	}
+	if err := filesystem.ValidatePatterns(cfg.Exclude); err != nil {
+		return nil, fmt.Errorf("invalid exclude filter: %w", err)
+	}
+
+	cfg.AlsoCheck, err = stringSliceFlag(cmd, "also-check")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get also-check flag: %w", err)
+	}
+
+	cfg.TagsFile, err = stringFlag(cmd, "tags")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tags flag: %w", err)
+	}
+
+	// A single target may come from a profile
+	if len(cfg.TargetDirs) == 0 && cfg.TargetDir != "" {
+		cfg.TargetDirs = []string{cfg.TargetDir}
+	}
+
 	// Validate configuration
 	if err := cfg.Validate(); err != nil {
 		return nil, err
@@ -43,6 +537,103 @@ func Load(cmd *cobra.Command, args []string) (*Config, error) {
 	return cfg, nil
 }
 
+// NonInteractive reports whether the selection is given by the --stdin,
+// --enable, --disable, --enable-all or --apply flags instead of the UI
+func (c *Config) NonInteractive() bool {
+	return c.Stdin || len(c.Enable) > 0 || len(c.Disable) > 0 || c.EnableAll || c.ApplyPreset
+}
+
+// applyProfile sets source, target and title from the named profile of the
+// config file, unless they were given as arguments, flags or env vars
+func (c *Config) applyProfile(cmd *cobra.Command, name string) error {
+	path, err := stringFlag(cmd, "config")
+	if err != nil {
+		return fmt.Errorf("failed to get config flag: %w", err)
+	}
+	if path == "" {
+		path, err = DefaultConfigPath()
+		if err != nil {
+			return err
+		}
+	}
+
+	fc, err := LoadFile(path)
+	if err != nil {
+		return err
+	}
+
+	profile, err := fc.Profile(name)
+	if err != nil {
+		return fmt.Errorf("%w in %s", err, path)
+	}
+
+	if c.SourceDir == "" {
+		c.SourceDir = profile.Source
+	}
+	if c.TargetDir == "" {
+		c.TargetDir = profile.Target
+	}
+	if !cmd.Flags().Changed("title") && os.Getenv(TitleEnvVar) == "" {
+		c.Title = profile.Title
+	}
+
+	return nil
+}
+
+// boolFlag returns the value of an optional boolean flag
+// Flags that are not defined on the command are reported as false
+func boolFlag(cmd *cobra.Command, name string) (bool, error) {
+	if cmd.Flags().Lookup(name) == nil {
+		return false, nil
+	}
+	return cmd.Flags().GetBool(name)
+}
+
+// stringFlag returns the value of an optional string flag
+// Flags that are not defined on the command are reported as empty
+func stringFlag(cmd *cobra.Command, name string) (string, error) {
+	if cmd.Flags().Lookup(name) == nil {
+		return "", nil
+	}
+	return cmd.Flags().GetString(name)
+}
+
+// stringSliceFlag returns the value of an optional string slice flag
+// Flags that are not defined on the command are reported as nil
+func stringSliceFlag(cmd *cobra.Command, name string) ([]string, error) {
+	if cmd.Flags().Lookup(name) == nil {
+		return nil, nil
+	}
+	return cmd.Flags().GetStringSlice(name)
+}
+
+// intFlag returns the value of an optional integer flag
+// Flags that are not defined on the command are reported as zero
+func intFlag(cmd *cobra.Command, name string) (int, error) {
+	if cmd.Flags().Lookup(name) == nil {
+		return 0, nil
+	}
+	return cmd.Flags().GetInt(name)
+}
+
+// durationFlag returns the value of an optional duration flag
+// Flags that are not defined on the command are reported as zero
+func durationFlag(cmd *cobra.Command, name string) (time.Duration, error) {
+	if cmd.Flags().Lookup(name) == nil {
+		return 0, nil
+	}
+	return cmd.Flags().GetDuration(name)
+}
+
+// parseFileMode parses an octal permission string like "0644"
+func parseFileMode(s string) (os.FileMode, error) {
+	mode, err := strconv.ParseUint(s, 8, 32)
+	if err != nil || mode == 0 || mode > 0777 {
+		return 0, fmt.Errorf("invalid file mode %q: expected octal permissions like 0644", s)
+	}
+	return os.FileMode(mode), nil
+}
+
 // Validate checks if the configuration is valid
 func (c *Config) Validate() error {
 	// Check if both directories are provided
@@ -55,30 +646,114 @@ func (c *Config) Validate() error {
 	}
 
 	// Check if directories exist
-	if err := checkDirExists(c.SourceDir); err != nil {
+	if err := filesystem.CheckDirExists(c.SourceDir); err != nil {
 		return fmt.Errorf("source directory: %w", err)
 	}
 
-	if err := checkDirExists(c.TargetDir); err != nil {
-		return fmt.Errorf("target directory: %w", err)
+	source, err := resolveDir(c.SourceDir)
+	if err != nil {
+		return fmt.Errorf("source directory: %w", err)
+	}
+
+	targets := c.TargetDirs
+	if len(targets) == 0 {
+		targets = []string{c.TargetDir}
+	}
+	seen := make(map[string]string, len(targets))
+	for _, dir := range targets {
+		target, err := c.validateTarget(source, dir)
+		if err != nil {
+			return err
+		}
+		if other, ok := seen[target]; ok {
+			return fmt.Errorf("target directories %s and %s are the same directory", other, dir)
+		}
+		seen[target] = dir
+	}
+
+	for _, dir := range c.AlsoCheck {
+		if err := filesystem.CheckDirExists(dir); err != nil {
+			return fmt.Errorf("also-check directory: %w", err)
+		}
 	}
 
 	return nil
 }
 
-// checkDirExists verifies that a directory exists and is accessible
-func checkDirExists(path string) error {
-	info, err := os.Stat(path)
+// validateTarget checks the target directory dir against the resolved
+// source and returns the resolved target
+func (c *Config) validateTarget(source, dir string) (string, error) {
+	// With --mkdir a missing target is fine, the apply creates it
+	missing := false
+	if err := filesystem.CheckDirExists(dir); err != nil {
+		if _, statErr := os.Stat(dir); !c.Mkdir || !os.IsNotExist(statErr) {
+			return "", fmt.Errorf("target directory: %w", err)
+		}
+		missing = true
+	}
+
+	// Linking a directory into itself would replace files with links to
+	// themselves, nested directories make recursive scans loop
+	resolve := resolveDir
+	if missing {
+		resolve = resolveMissingDir
+	}
+	target, err := resolve(dir)
 	if err != nil {
-		if os.IsNotExist(err) {
-			return fmt.Errorf("%s does not exist", path)
+		return "", fmt.Errorf("target directory: %w", err)
+	}
+	if source == target {
+		return "", fmt.Errorf("source and target are the same directory: %s", source)
+	}
+	if c.Recursive {
+		if isWithin(source, target) {
+			return "", fmt.Errorf("target directory %s is inside the source directory, which is not supported with --recursive", target)
+		}
+		if isWithin(target, source) {
+			return "", fmt.Errorf("source directory %s is inside the target directory, which is not supported with --recursive", source)
 		}
-		return fmt.Errorf("cannot access %s: %w", path, err)
 	}
+	return target, nil
+}
 
-	if !info.IsDir() {
-		return fmt.Errorf("%s is not a directory", path)
+// resolveDir returns the absolute path of dir with all symlinks resolved
+func resolveDir(dir string) (string, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", dir, err)
 	}
+	resolved, err := filepath.EvalSymlinks(abs)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", dir, err)
+	}
+	return resolved, nil
+}
 
-	return nil
+// resolveMissingDir resolves dir like resolveDir for a directory that does
+// not exist yet: the symlinks of its nearest existing parent are resolved
+func resolveMissingDir(dir string) (string, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", dir, err)
+	}
+	var missing []string
+	for parent := abs; ; parent = filepath.Dir(parent) {
+		if resolved, err := filepath.EvalSymlinks(parent); err == nil {
+			return filepath.Join(append([]string{resolved}, missing...)...), nil
+		}
+		if filepath.Dir(parent) == parent {
+			return abs, nil
+		}
+		missing = append([]string{filepath.Base(parent)}, missing...)
+	}
+}
+
+// isWithin reports whether path lies below the directory parent
+// Both paths must be clean and absolute.
+func isWithin(parent, path string) bool {
+	rel, err := filepath.Rel(parent, path)
+	if err != nil {
+		return false
+	}
+	return rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
 }
@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
 
 	"github.com/spf13/cobra"
@@ -83,6 +84,16 @@ func TestValidate(t *testing.T) {
 			wantError: true,
 			errorMsg:  "is not a directory",
 		},
+		{
+			name: "non-existent also-check directory",
+			config: Config{
+				SourceDir: sourceDir,
+				TargetDir: targetDir,
+				AlsoCheck: []string{filepath.Join(tempDir, "nonexistent")},
+			},
+			wantError: true,
+			errorMsg:  "also-check directory",
+		},
 	}
 
 	// Create a file in source dir for file test
@@ -109,6 +120,95 @@ func TestValidate(t *testing.T) {
 	}
 }
 
+// TestValidate_SameOrNestedDirs tests rejecting overlapping source and target
+func TestValidate_SameOrNestedDirs(t *testing.T) {
+	tempDir := t.TempDir()
+	sourceDir := filepath.Join(tempDir, "source")
+	nestedDir := filepath.Join(sourceDir, "enabled")
+	if err := os.MkdirAll(nestedDir, 0755); err != nil {
+		t.Fatalf("Failed to create dirs: %v", err)
+	}
+	linkDir := filepath.Join(tempDir, "link")
+	if err := os.Symlink(sourceDir, linkDir); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		config    Config
+		wantError string
+	}{
+		{name: "identical paths", config: Config{SourceDir: sourceDir, TargetDir: sourceDir}, wantError: "same directory"},
+		{name: "unclean identical paths", config: Config{SourceDir: sourceDir, TargetDir: nestedDir + "/.."}, wantError: "same directory"},
+		{name: "symlink to the source", config: Config{SourceDir: sourceDir, TargetDir: linkDir}, wantError: "same directory"},
+		{name: "nested target", config: Config{SourceDir: sourceDir, TargetDir: nestedDir}},
+		{name: "nested target recursive", config: Config{SourceDir: sourceDir, TargetDir: nestedDir, Recursive: true}, wantError: "inside the source"},
+		{name: "nested source recursive", config: Config{SourceDir: nestedDir, TargetDir: linkDir, Recursive: true}, wantError: "inside the target"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if tt.wantError == "" {
+				if err != nil {
+					t.Errorf("Validate() unexpected error = %v", err)
+				}
+				return
+			}
+			if err == nil || !contains(err.Error(), tt.wantError) {
+				t.Errorf("Validate() error = %v, want error containing %q", err, tt.wantError)
+			}
+		})
+	}
+}
+
+// TestValidate_Mkdir tests accepting a missing target directory without
+// creating it, which is left to the apply
+func TestValidate_Mkdir(t *testing.T) {
+	tempDir := t.TempDir()
+	sourceDir := filepath.Join(tempDir, "source")
+	if err := os.Mkdir(sourceDir, 0755); err != nil {
+		t.Fatalf("Failed to create source dir: %v", err)
+	}
+
+	targetDir := filepath.Join(tempDir, "etc", "app", "enabled")
+	cfg := Config{SourceDir: sourceDir, TargetDir: targetDir, Mkdir: true}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() unexpected error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, "etc")); !os.IsNotExist(err) {
+		t.Errorf("Validate() created the target directory: %v", err)
+	}
+
+	// Without --mkdir the target must exist
+	cfg = Config{SourceDir: sourceDir, TargetDir: targetDir}
+	if err := cfg.Validate(); err == nil || !contains(err.Error(), "does not exist") {
+		t.Errorf("Validate() error = %v, want does not exist", err)
+	}
+
+	// A missing target is still checked against the source
+	cfg = Config{SourceDir: sourceDir, TargetDir: filepath.Join(sourceDir, "enabled"), Mkdir: true, Recursive: true}
+	if err := cfg.Validate(); err == nil || !contains(err.Error(), "inside the source directory") {
+		t.Errorf("Validate() error = %v, want inside the source directory", err)
+	}
+
+	// A file at the target path is never replaced
+	file := filepath.Join(tempDir, "file")
+	if err := os.WriteFile(file, []byte("test"), 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	cfg = Config{SourceDir: sourceDir, TargetDir: file, Mkdir: true}
+	if err := cfg.Validate(); err == nil || !contains(err.Error(), "is not a directory") {
+		t.Errorf("Validate() error = %v, want not a directory", err)
+	}
+
+	// The source is still required to exist
+	cfg = Config{SourceDir: filepath.Join(tempDir, "missing"), TargetDir: targetDir, Mkdir: true}
+	if err := cfg.Validate(); err == nil || !contains(err.Error(), "source directory") {
+		t.Errorf("Validate() error = %v, want source directory error", err)
+	}
+}
+
 // TestLoad tests the Load function with cobra command
 func TestLoad(t *testing.T) {
 	// Create temporary directories for testing
@@ -199,61 +299,51 @@ func TestLoad(t *testing.T) {
 	}
 }
 
-// TestCheckDirExists tests the checkDirExists function indirectly through Validate
-func TestCheckDirExists(t *testing.T) {
+// TestLoad_MultipleTargets tests that further arguments are more targets
+func TestLoad_MultipleTargets(t *testing.T) {
 	tempDir := t.TempDir()
+	var dirs []string
+	for _, name := range []string{"source", "staging", "prod"} {
+		dir := filepath.Join(tempDir, name)
+		if err := os.Mkdir(dir, 0755); err != nil {
+			t.Fatalf("Failed to create dir: %v", err)
+		}
+		dirs = append(dirs, dir)
+	}
 
-	tests := []struct {
-		name      string
-		setupFunc func() string
-		wantError bool
-		errorMsg  string
-	}{
-		{
-			name: "valid directory",
-			setupFunc: func() string {
-				dir := filepath.Join(tempDir, "valid")
-				_ = os.MkdirAll(dir, 0755)
-				return dir
-			},
-			wantError: false,
-		},
-		{
-			name: "non-existent directory",
-			setupFunc: func() string {
-				return filepath.Join(tempDir, "nonexistent")
-			},
-			wantError: true,
-			errorMsg:  "does not exist",
-		},
-		{
-			name: "file instead of directory",
-			setupFunc: func() string {
-				file := filepath.Join(tempDir, "file.txt")
-				_ = os.WriteFile(file, []byte("test"), 0644)
-				return file
-			},
-			wantError: true,
-			errorMsg:  "is not a directory",
-		},
+	newCmd := func() *cobra.Command {
+		cmd := &cobra.Command{Use: "test"}
+		cmd.Flags().StringP("title", "t", "", "Title")
+		return cmd
 	}
 
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			path := tt.setupFunc()
-			err := checkDirExists(path)
-			if tt.wantError {
-				if err == nil {
-					t.Errorf("checkDirExists() expected error but got none")
-				} else if tt.errorMsg != "" && !contains(err.Error(), tt.errorMsg) {
-					t.Errorf("checkDirExists() error = %v, want error containing %q", err, tt.errorMsg)
-				}
-			} else {
-				if err != nil {
-					t.Errorf("checkDirExists() unexpected error = %v", err)
-				}
-			}
-		})
+	cfg, err := Load(newCmd(), dirs)
+	if err != nil {
+		t.Fatalf("Load() unexpected error = %v", err)
+	}
+	if cfg.TargetDir != dirs[1] {
+		t.Errorf("Load() TargetDir = %v, want %v", cfg.TargetDir, dirs[1])
+	}
+	if want := dirs[1:]; !reflect.DeepEqual(cfg.TargetDirs, want) {
+		t.Errorf("Load() TargetDirs = %v, want %v", cfg.TargetDirs, want)
+	}
+
+	// A single target is listed as well
+	cfg, err = Load(newCmd(), dirs[:2])
+	if err != nil {
+		t.Fatalf("Load() unexpected error = %v", err)
+	}
+	if want := dirs[1:2]; !reflect.DeepEqual(cfg.TargetDirs, want) {
+		t.Errorf("Load() TargetDirs = %v, want %v", cfg.TargetDirs, want)
+	}
+
+	// Every target is validated
+	missing := filepath.Join(tempDir, "missing")
+	if _, err := Load(newCmd(), []string{dirs[0], dirs[1], missing}); err == nil || !contains(err.Error(), "target directory") {
+		t.Errorf("Load() error = %v, want target directory error", err)
+	}
+	if _, err := Load(newCmd(), []string{dirs[0], dirs[1], dirs[1] + "/"}); err == nil || !contains(err.Error(), "same directory") {
+		t.Errorf("Load() error = %v, want same directory error", err)
 	}
 }
 
@@ -272,3 +362,37 @@ func containsInner(s, substr string) bool {
 	}
 	return false
 }
+
+// TestParseFileMode tests parsing octal permission strings
+func TestParseFileMode(t *testing.T) {
+	tests := []struct {
+		input     string
+		want      os.FileMode
+		wantError bool
+	}{
+		{input: "0644", want: 0644},
+		{input: "755", want: 0755},
+		{input: "0000", wantError: true},
+		{input: "0999", wantError: true},
+		{input: "01777", wantError: true},
+		{input: "rw-r--r--", wantError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := parseFileMode(tt.input)
+			if tt.wantError {
+				if err == nil {
+					t.Errorf("parseFileMode(%q) expected error but got none", tt.input)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("parseFileMode(%q) unexpected error = %v", tt.input, err)
+			}
+			if got != tt.want {
+				t.Errorf("parseFileMode(%q) = %04o, want %04o", tt.input, got, tt.want)
+			}
+		})
+	}
+}
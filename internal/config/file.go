@@ -0,0 +1,96 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileConfig is the content of the optional YAML config file
+//
+// Example:
+//
+//	profiles:
+//	  home-dotfiles:
+//	    source: ~/dotfiles
+//	    target: ~/.config
+//	    title: Dotfiles
+type FileConfig struct {
+	Profiles map[string]Profile `yaml:"profiles"`
+}
+
+// Profile is a named set of defaults selected with --profile
+type Profile struct {
+	Source string `yaml:"source"`
+	Target string `yaml:"target"`
+	Title  string `yaml:"title"`
+}
+
+// DefaultConfigPath returns the default config file location
+// (e.g. ~/.config/lnka/config.yaml on Linux)
+func DefaultConfigPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate config directory: %w", err)
+	}
+	return filepath.Join(dir, "lnka", "config.yaml"), nil
+}
+
+// LoadFile reads the YAML config file at path
+// A missing file is not an error and yields an empty configuration
+func LoadFile(path string) (*FileConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return &FileConfig{}, nil
+		}
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	fc := &FileConfig{}
+	if err := yaml.Unmarshal(data, fc); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	return fc, nil
+}
+
+// Profile returns the named profile with "~" expanded in its directories
+func (fc *FileConfig) Profile(name string) (Profile, error) {
+	p, ok := fc.Profiles[name]
+	if !ok {
+		return Profile{}, fmt.Errorf("profile %q not found", name)
+	}
+
+	p.Source = expandHome(p.Source)
+	p.Target = expandHome(p.Target)
+	return p, nil
+}
+
+// ProfileNames returns the names of all profiles in alphabetical order
+func (fc *FileConfig) ProfileNames() []string {
+	names := make([]string, 0, len(fc.Profiles))
+	for name := range fc.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// expandHome replaces a leading "~" with the user's home directory
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, strings.TrimPrefix(path, "~"))
+}
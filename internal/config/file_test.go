@@ -0,0 +1,133 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+// TestLoadFile_Missing tests that a missing config file is not an error
+func TestLoadFile_Missing(t *testing.T) {
+	fc, err := LoadFile(filepath.Join(t.TempDir(), "missing.yaml"))
+	if err != nil {
+		t.Fatalf("LoadFile() unexpected error = %v", err)
+	}
+	if len(fc.Profiles) != 0 {
+		t.Errorf("LoadFile() profiles = %v, want none", fc.Profiles)
+	}
+}
+
+// TestLoadFile_Malformed tests that invalid YAML produces a clear error
+func TestLoadFile_Malformed(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("profiles:\n  home: [unclosed\n"), 0644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	_, err := LoadFile(path)
+	if err == nil || !contains(err.Error(), "failed to parse config file") {
+		t.Errorf("LoadFile() error = %v, want parse error", err)
+	}
+}
+
+// TestLoad_ProfilePrecedence tests that args, flags and env vars override profile values
+func TestLoad_ProfilePrecedence(t *testing.T) {
+	tempDir := t.TempDir()
+	dirs := map[string]string{}
+	for _, name := range []string{"profile-source", "profile-target", "cli-source"} {
+		dirs[name] = filepath.Join(tempDir, name)
+		if err := os.MkdirAll(dirs[name], 0755); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+	}
+
+	configPath := filepath.Join(tempDir, "config.yaml")
+	content := "profiles:\n  home:\n    source: " + dirs["profile-source"] +
+		"\n    target: " + dirs["profile-target"] + "\n    title: Profile Title\n"
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	newCmd := func(flags map[string]string) *cobra.Command {
+		cmd := &cobra.Command{Use: "test"}
+		cmd.Flags().StringP("title", "t", os.Getenv(TitleEnvVar), "Title")
+		cmd.Flags().String("config", "", "Config file")
+		cmd.Flags().String("profile", "", "Profile")
+		for name, value := range flags {
+			_ = cmd.Flags().Set(name, value)
+		}
+		return cmd
+	}
+
+	tests := []struct {
+		name       string
+		args       []string
+		flags      map[string]string
+		env        string
+		wantSource string
+		wantTarget string
+		wantTitle  string
+		wantError  string
+	}{
+		{
+			name:       "profile provides everything",
+			flags:      map[string]string{"profile": "home"},
+			wantSource: dirs["profile-source"],
+			wantTarget: dirs["profile-target"],
+			wantTitle:  "Profile Title",
+		},
+		{
+			name:       "positional source overrides profile",
+			args:       []string{dirs["cli-source"]},
+			flags:      map[string]string{"profile": "home"},
+			wantSource: dirs["cli-source"],
+			wantTarget: dirs["profile-target"],
+			wantTitle:  "Profile Title",
+		},
+		{
+			name:       "title flag overrides profile",
+			flags:      map[string]string{"profile": "home", "title": "Flag Title"},
+			wantSource: dirs["profile-source"],
+			wantTarget: dirs["profile-target"],
+			wantTitle:  "Flag Title",
+		},
+		{
+			name:       "env var overrides profile",
+			flags:      map[string]string{"profile": "home"},
+			env:        "Env Title",
+			wantSource: dirs["profile-source"],
+			wantTarget: dirs["profile-target"],
+			wantTitle:  "Env Title",
+		},
+		{
+			name:      "unknown profile",
+			flags:     map[string]string{"profile": "work"},
+			wantError: `profile "work" not found`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(TitleEnvVar, tt.env)
+			tt.flags["config"] = configPath
+
+			cfg, err := Load(newCmd(tt.flags), tt.args)
+			if tt.wantError != "" {
+				if err == nil || !contains(err.Error(), tt.wantError) {
+					t.Errorf("Load() error = %v, want error containing %q", err, tt.wantError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Load() unexpected error = %v", err)
+			}
+
+			if cfg.SourceDir != tt.wantSource || cfg.TargetDir != tt.wantTarget || cfg.Title != tt.wantTitle {
+				t.Errorf("Load() = {%s %s %q}, want {%s %s %q}",
+					cfg.SourceDir, cfg.TargetDir, cfg.Title, tt.wantSource, tt.wantTarget, tt.wantTitle)
+			}
+		})
+	}
+}
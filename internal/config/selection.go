@@ -0,0 +1,92 @@
+package config
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Output formats for --format
+const (
+	OutputText = "text"
+	OutputJSON = "json"
+)
+
+// WriteSelection writes the selected file names in the given output format
+// Text output is one name per line, JSON output is an array of names
+func WriteSelection(w io.Writer, selection []string, format string) error {
+	switch format {
+	case OutputJSON:
+		if selection == nil {
+			selection = []string{}
+		}
+		data, err := json.Marshal(selection)
+		if err != nil {
+			return fmt.Errorf("failed to encode selection: %w", err)
+		}
+		_, err = fmt.Fprintln(w, string(data))
+		return err
+	case OutputText:
+		for _, name := range selection {
+			if _, err := fmt.Fprintln(w, name); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("invalid output format %q: expected %s or %s", format, OutputText, OutputJSON)
+	}
+}
+
+// ReadSelection decodes a JSON array of file names
+func ReadSelection(r io.Reader) ([]string, error) {
+	var selection []string
+	if err := json.NewDecoder(r).Decode(&selection); err != nil {
+		return nil, fmt.Errorf("failed to parse selection: %w", err)
+	}
+
+	for _, name := range selection {
+		if strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("failed to parse selection: empty file name")
+		}
+	}
+
+	return selection, nil
+}
+
+// ReadSelectionLines reads file names one per line
+// Surrounding whitespace is trimmed, blank lines and lines starting with #
+// are ignored.
+func ReadSelectionLines(r io.Reader) ([]string, error) {
+	var selection []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		selection = append(selection, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read selection: %w", err)
+	}
+	return selection, nil
+}
+
+// LoadSelection reads a JSON selection from a file, or from stdin if path is "-"
+func LoadSelection(path string) ([]string, error) {
+	if path == "-" {
+		return ReadSelection(os.Stdin)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read selection file: %w", err)
+	}
+	defer f.Close()
+
+	return ReadSelection(f)
+}
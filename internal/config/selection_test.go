@@ -0,0 +1,89 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestWriteSelection tests the text and JSON selection output
+func TestWriteSelection(t *testing.T) {
+	selection := []string{"b.conf", "a.conf"}
+
+	var buf bytes.Buffer
+	if err := WriteSelection(&buf, selection, OutputJSON); err != nil {
+		t.Fatalf("WriteSelection() unexpected error = %v", err)
+	}
+	if got := buf.String(); got != "[\"b.conf\",\"a.conf\"]\n" {
+		t.Errorf("WriteSelection(json) = %q", got)
+	}
+
+	buf.Reset()
+	if err := WriteSelection(&buf, nil, OutputJSON); err != nil {
+		t.Fatalf("WriteSelection() unexpected error = %v", err)
+	}
+	if got := buf.String(); got != "[]\n" {
+		t.Errorf("WriteSelection(json, empty) = %q, want []", got)
+	}
+
+	buf.Reset()
+	if err := WriteSelection(&buf, selection, OutputText); err != nil {
+		t.Fatalf("WriteSelection() unexpected error = %v", err)
+	}
+	if got := buf.String(); got != "b.conf\na.conf\n" {
+		t.Errorf("WriteSelection(text) = %q", got)
+	}
+
+	if err := WriteSelection(&buf, selection, "yaml"); err == nil {
+		t.Error("WriteSelection() expected error for unknown format")
+	}
+}
+
+// TestSelectionRoundTrip tests that emitted JSON is read back unchanged
+func TestSelectionRoundTrip(t *testing.T) {
+	selection := []string{"site one.conf", "a.conf", "ünïcode.conf"}
+
+	var buf bytes.Buffer
+	if err := WriteSelection(&buf, selection, OutputJSON); err != nil {
+		t.Fatalf("WriteSelection() unexpected error = %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "selection.json")
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatalf("Failed to write selection: %v", err)
+	}
+
+	got, err := LoadSelection(path)
+	if err != nil {
+		t.Fatalf("LoadSelection() unexpected error = %v", err)
+	}
+	if !reflect.DeepEqual(got, selection) {
+		t.Errorf("LoadSelection() = %v, want %v", got, selection)
+	}
+}
+
+// TestReadSelection_Invalid tests that malformed input is rejected
+func TestReadSelection_Invalid(t *testing.T) {
+	inputs := []string{"", "{\"a\": 1}", "[\"a.conf\", 3]", "[\"\"]"}
+	for _, input := range inputs {
+		if _, err := ReadSelection(strings.NewReader(input)); err == nil {
+			t.Errorf("ReadSelection(%q) expected error", input)
+		}
+	}
+}
+
+// TestReadSelectionLines tests reading one name per line
+func TestReadSelectionLines(t *testing.T) {
+	input := "# enabled sites\na.conf\n\n  b.conf  \r\n#c.conf\nsite one.conf"
+	got, err := ReadSelectionLines(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ReadSelectionLines() unexpected error = %v", err)
+	}
+	want := []string{"a.conf", "b.conf", "site one.conf"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ReadSelectionLines() = %v, want %v", got, want)
+	}
+}
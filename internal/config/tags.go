@@ -0,0 +1,23 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// LoadTags reads a JSON tag file mapping file names to lists of tags
+// Example: {"app.conf": ["web", "critical"]}
+func LoadTags(path string) (map[string][]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tag file: %w", err)
+	}
+
+	tags := make(map[string][]string)
+	if err := json.Unmarshal(data, &tags); err != nil {
+		return nil, fmt.Errorf("failed to parse tag file %s: %w", path, err)
+	}
+
+	return tags, nil
+}
@@ -0,0 +1,69 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// TestLoadTags tests loading a valid tag file
+func TestLoadTags(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tags.json")
+	content := `{"app.conf": ["web", "critical"], "db.conf": ["db"]}`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create tag file: %v", err)
+	}
+
+	tags, err := LoadTags(path)
+	if err != nil {
+		t.Fatalf("LoadTags() unexpected error = %v", err)
+	}
+
+	expected := map[string][]string{
+		"app.conf": {"web", "critical"},
+		"db.conf":  {"db"},
+	}
+	if !reflect.DeepEqual(tags, expected) {
+		t.Errorf("LoadTags() = %v, want %v", tags, expected)
+	}
+}
+
+// TestLoadTags_Errors tests missing and malformed tag files
+func TestLoadTags_Errors(t *testing.T) {
+	tempDir := t.TempDir()
+
+	malformed := filepath.Join(tempDir, "malformed.json")
+	if err := os.WriteFile(malformed, []byte(`{"app.conf": "web"`), 0644); err != nil {
+		t.Fatalf("Failed to create tag file: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		path     string
+		errorMsg string
+	}{
+		{
+			name:     "missing file",
+			path:     filepath.Join(tempDir, "nonexistent.json"),
+			errorMsg: "failed to read tag file",
+		},
+		{
+			name:     "malformed file",
+			path:     malformed,
+			errorMsg: "failed to parse tag file",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadTags(tt.path)
+			if err == nil {
+				t.Fatal("LoadTags() expected error but got none")
+			}
+			if !contains(err.Error(), tt.errorMsg) {
+				t.Errorf("LoadTags() error = %v, want error containing %q", err, tt.errorMsg)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package config
+
+import (
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ThemeEnvVar is the environment variable providing the UI colors
+// (e.g. "cursor=33,linked=15,unlinked=#888888")
+const ThemeEnvVar = "LNKA_THEME"
+
+// NoColorEnvVar disables all UI styling when set to a non-empty value
+// (see https://no-color.org)
+const NoColorEnvVar = "NO_COLOR"
+
+// Default UI colors (ANSI color numbers)
+const (
+	DefaultColorCursor   = "10"  // Green
+	DefaultColorLinked   = ""    // Terminal default
+	DefaultColorUnlinked = "240" // Gray
+)
+
+// Theme holds the colors of the multi-select list
+// Colors are ANSI color numbers (0-255) or hex values like "#ff8800".
+type Theme struct {
+	Cursor   string // Item under the cursor
+	Linked   string // Linked items ("" = terminal default)
+	Unlinked string // Unlinked items
+	NoColor  bool   // Render without any styling
+}
+
+// DefaultTheme returns the built-in colors
+func DefaultTheme() Theme {
+	return Theme{Cursor: DefaultColorCursor, Linked: DefaultColorLinked, Unlinked: DefaultColorUnlinked}
+}
+
+// hexColor matches "#rgb" and "#rrggbb"
+var hexColor = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// ValidColor reports whether s is an ANSI color number or a hex color
+func ValidColor(s string) bool {
+	if hexColor.MatchString(s) {
+		return true
+	}
+	n, err := strconv.Atoi(s)
+	return err == nil && n >= 0 && n <= 255
+}
+
+// Theme returns the UI colors: the defaults, overridden by LNKA_THEME and
+// the --color-* flags. Invalid values are reported through warn and replaced
+// by the default. NO_COLOR or --no-color disable styling.
+func (c *Config) Theme(warn func(format string, args ...any)) Theme {
+	theme := DefaultTheme()
+	theme.NoColor = c.NoColor || os.Getenv(NoColorEnvVar) != ""
+
+	defaults := DefaultTheme()
+	colors := map[string]struct {
+		value *string
+		def   string
+	}{
+		"cursor":   {&theme.Cursor, defaults.Cursor},
+		"linked":   {&theme.Linked, defaults.Linked},
+		"unlinked": {&theme.Unlinked, defaults.Unlinked},
+	}
+
+	set := func(source, name, value string) {
+		color := colors[name]
+		if !ValidColor(value) {
+			warn("invalid %s color %q in %s, using the default", name, value, source)
+			*color.value = color.def
+			return
+		}
+		*color.value = value
+	}
+
+	if spec := os.Getenv(ThemeEnvVar); spec != "" {
+		for _, entry := range strings.Split(spec, ",") {
+			name, value, ok := strings.Cut(strings.TrimSpace(entry), "=")
+			if _, known := colors[name]; !ok || !known {
+				warn("ignoring invalid %s entry %q (expected cursor=, linked= or unlinked=)", ThemeEnvVar, entry)
+				continue
+			}
+			set(ThemeEnvVar, name, strings.TrimSpace(value))
+		}
+	}
+
+	flags := []struct{ name, value string }{
+		{"cursor", c.ColorCursor},
+		{"linked", c.ColorLinked},
+		{"unlinked", c.ColorUnlinked},
+	}
+	for _, flag := range flags {
+		if flag.value != "" {
+			set("--color-"+flag.name, flag.name, flag.value)
+		}
+	}
+
+	return theme
+}
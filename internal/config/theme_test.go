@@ -0,0 +1,81 @@
+package config
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestValidColor(t *testing.T) {
+	for _, c := range []string{"0", "10", "255", "#fff", "#FF8800"} {
+		if !ValidColor(c) {
+			t.Errorf("ValidColor(%q) = false, want true", c)
+		}
+	}
+	for _, c := range []string{"", "256", "-1", "green", "#ff88", "#gggggg"} {
+		if ValidColor(c) {
+			t.Errorf("ValidColor(%q) = true, want false", c)
+		}
+	}
+}
+
+func TestConfigTheme(t *testing.T) {
+	tests := []struct {
+		name         string
+		env          string
+		noColor      string
+		cfg          Config
+		want         Theme
+		wantWarnings int
+	}{
+		{
+			name: "defaults",
+			want: DefaultTheme(),
+		},
+		{
+			name: "env overrides defaults",
+			env:  "cursor=33, unlinked=#888888",
+			want: Theme{Cursor: "33", Unlinked: "#888888"},
+		},
+		{
+			name: "flags override env",
+			env:  "cursor=33",
+			cfg:  Config{ColorCursor: "12", ColorLinked: "15"},
+			want: Theme{Cursor: "12", Linked: "15", Unlinked: DefaultColorUnlinked},
+		},
+		{
+			name:         "invalid values fall back with a warning",
+			env:          "cursor=green,accent=1",
+			cfg:          Config{ColorUnlinked: "300"},
+			want:         DefaultTheme(),
+			wantWarnings: 3,
+		},
+		{
+			name:    "NO_COLOR disables styling",
+			noColor: "1",
+			want:    Theme{Cursor: DefaultColorCursor, Unlinked: DefaultColorUnlinked, NoColor: true},
+		},
+		{
+			name: "--no-color disables styling",
+			cfg:  Config{NoColor: true},
+			want: Theme{Cursor: DefaultColorCursor, Unlinked: DefaultColorUnlinked, NoColor: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(ThemeEnvVar, tt.env)
+			t.Setenv(NoColorEnvVar, tt.noColor)
+
+			var warnings []string
+			got := tt.cfg.Theme(func(format string, args ...any) {
+				warnings = append(warnings, fmt.Sprintf(format, args...))
+			})
+			if got != tt.want {
+				t.Errorf("Theme() = %+v, want %+v", got, tt.want)
+			}
+			if len(warnings) != tt.wantWarnings {
+				t.Errorf("warnings = %v, want %d", warnings, tt.wantWarnings)
+			}
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package filesystem
+
+import (
+	"fmt"
+	"os"
+)
+
+// BackupSuffix is appended to a regular file's name when it is moved aside
+// for a new link, with Options.Backup or by ReplaceShadowFiles
+const BackupSuffix = ".bak"
+
+// backupFile renames the file to the first free backup name (name.bak,
+// name.bak.1, name.bak.2, ...) and returns it
+func backupFile(path string) (string, error) {
+	backupPath := path + BackupSuffix
+	for i := 1; ; i++ {
+		if _, err := os.Lstat(backupPath); os.IsNotExist(err) {
+			break
+		} else if err != nil {
+			return "", err
+		}
+		backupPath = fmt.Sprintf("%s%s.%d", path, BackupSuffix, i)
+	}
+
+	if err := os.Rename(path, backupPath); err != nil {
+		return "", err
+	}
+	return backupPath, nil
+}
+
+// restoreBackup moves a backup made by backupFile back into place (no-op
+// without a backup)
+func restoreBackup(backupPath, path string) {
+	if backupPath != "" {
+		_ = os.Rename(backupPath, path)
+	}
+}
@@ -0,0 +1,75 @@
+package filesystem
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestCreateSymlinkWithBackup tests moving regular files aside before linking
+func TestCreateSymlinkWithBackup(t *testing.T) {
+	sourceDir, targetDir := setupSourceTarget(t, "app.conf")
+	linkPath := filepath.Join(targetDir, "app.conf")
+	opts := Options{Backup: true}
+
+	// A taken .bak name makes the backup use a counter
+	if err := os.WriteFile(linkPath+".bak", []byte("older"), 0644); err != nil {
+		t.Fatalf("Failed to create existing backup: %v", err)
+	}
+	if err := os.WriteFile(linkPath, []byte("local"), 0644); err != nil {
+		t.Fatalf("Failed to create regular file: %v", err)
+	}
+
+	backupPath, err := CreateSymlinkWithBackup(sourceDir, targetDir, "app.conf", opts)
+	if err != nil {
+		t.Fatalf("CreateSymlinkWithBackup failed: %v", err)
+	}
+	if want := linkPath + ".bak.1"; backupPath != want {
+		t.Errorf("backupPath = %s, want %s", backupPath, want)
+	}
+	if data, err := os.ReadFile(backupPath); err != nil || string(data) != "local" {
+		t.Errorf("backup content = %q, %v, want %q", data, err, "local")
+	}
+	if data, _ := os.ReadFile(linkPath + ".bak"); string(data) != "older" {
+		t.Errorf("existing backup was overwritten: %q", data)
+	}
+	if info, err := os.Lstat(linkPath); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Errorf("app.conf is not a symlink: %v", err)
+	}
+
+	// Symlink over symlink just replaces it
+	backupPath, err = CreateSymlinkWithBackup(sourceDir, targetDir, "app.conf", opts)
+	if err != nil {
+		t.Fatalf("CreateSymlinkWithBackup failed: %v", err)
+	}
+	if backupPath != "" {
+		t.Errorf("backupPath = %s, want no backup when replacing a symlink", backupPath)
+	}
+	if _, err := os.Lstat(linkPath + ".bak.2"); !os.IsNotExist(err) {
+		t.Errorf("unexpected backup created: %v", err)
+	}
+}
+
+// TestCreateSymlinkWithBackup_RefusesFiles tests that a regular file is only
+// replaced once the conflict is confirmed
+func TestCreateSymlinkWithBackup_RefusesFiles(t *testing.T) {
+	sourceDir, targetDir := setupSourceTarget(t, "app.conf")
+	linkPath := filepath.Join(targetDir, "app.conf")
+	if err := os.WriteFile(linkPath, []byte("local"), 0644); err != nil {
+		t.Fatalf("Failed to create regular file: %v", err)
+	}
+
+	if _, err := CreateSymlinkWithBackup(sourceDir, targetDir, "app.conf", Options{}); err == nil {
+		t.Fatal("CreateSymlinkWithBackup should refuse to replace a regular file")
+	}
+	if data, err := os.ReadFile(linkPath); err != nil || string(data) != "local" {
+		t.Errorf("regular file content = %q, %v, want it untouched", data, err)
+	}
+
+	if _, err := CreateSymlinkWithBackup(sourceDir, targetDir, "app.conf", Options{Overwrite: true}); err != nil {
+		t.Fatalf("CreateSymlinkWithBackup with Overwrite failed: %v", err)
+	}
+	if info, err := os.Lstat(linkPath); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Errorf("app.conf is not a symlink: %v", err)
+	}
+}
@@ -0,0 +1,97 @@
+package filesystem
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// TimeoutContext returns a context bounding a filesystem operation to
+// timeout, derived from parent (nil = background). A timeout of zero or less
+// only makes the context cancelable.
+func TimeoutContext(parent context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if parent == nil {
+		parent = context.Background()
+	}
+	if timeout <= 0 {
+		return context.WithCancel(parent)
+	}
+	return context.WithTimeout(parent, timeout)
+}
+
+// withContext runs fn until it returns or ctx is done, whichever comes first.
+// Stat and read calls cannot be interrupted (e.g. on a hanging network
+// mount), so a canceled fn is left running in the background and its result
+// is discarded.
+func withContext[T any](ctx context.Context, what string, fn func() (T, error)) (T, error) {
+	var zero T
+	if err := ctx.Err(); err != nil {
+		return zero, fmt.Errorf("gave up on %s: %w", what, err)
+	}
+
+	type result struct {
+		value T
+		err   error
+	}
+	done := make(chan result, 1)
+	go func() {
+		value, err := fn()
+		done <- result{value, err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.value, r.err
+	case <-ctx.Done():
+		return zero, fmt.Errorf("gave up on %s: %w", what, ctx.Err())
+	}
+}
+
+// CheckDirExistsContext checks the directory like CheckDirExists, returning
+// an error once ctx is done
+func CheckDirExistsContext(ctx context.Context, dir string) error {
+	_, err := withContext(ctx, "checking "+dir, func() (struct{}, error) {
+		return struct{}{}, CheckDirExists(dir)
+	})
+	return err
+}
+
+// ListAvailableFilesContext lists the source files like
+// ListAvailableFilesWithOptions, returning an error once ctx is done
+func ListAvailableFilesContext(ctx context.Context, dir string, opts Options) ([]string, error) {
+	return withContext(ctx, "reading "+dir, func() ([]string, error) {
+		return ListAvailableFilesWithOptions(dir, opts)
+	})
+}
+
+// GetEnabledFilesContext returns the enabled files like
+// GetEnabledFilesWithOptions, returning an error once ctx is done
+func GetEnabledFilesContext(ctx context.Context, sourceDir, targetDir string, opts Options) ([]string, error) {
+	return withContext(ctx, "reading "+targetDir, func() ([]string, error) {
+		return GetEnabledFilesWithOptions(sourceDir, targetDir, opts)
+	})
+}
+
+// ValidateSymlinksContext finds broken symlinks like
+// ValidateSymlinksWithOptions, returning an error once ctx is done
+func ValidateSymlinksContext(ctx context.Context, sourceDir, targetDir string, opts Options) ([]string, error) {
+	return withContext(ctx, "reading "+targetDir, func() ([]string, error) {
+		return ValidateSymlinksWithOptions(sourceDir, targetDir, opts)
+	})
+}
+
+// FindStaleSymlinksContext finds stale symlinks like
+// FindStaleSymlinksWithOptions, returning an error once ctx is done
+func FindStaleSymlinksContext(ctx context.Context, sourceDir, targetDir string, opts Options) ([]string, error) {
+	return withContext(ctx, "reading "+targetDir, func() ([]string, error) {
+		return FindStaleSymlinksWithOptions(sourceDir, targetDir, opts)
+	})
+}
+
+// ReadTargetStateContext reads the target like ReadTargetState, returning an
+// error once ctx is done
+func ReadTargetStateContext(ctx context.Context, sourceDir, targetDir string, opts Options) (*TargetState, error) {
+	return withContext(ctx, "reading "+targetDir, func() (*TargetState, error) {
+		return ReadTargetState(sourceDir, targetDir, opts)
+	})
+}
@@ -0,0 +1,49 @@
+package filesystem
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// TestWithContext_Timeout tests giving up on an operation that hangs
+func TestWithContext_Timeout(t *testing.T) {
+	ctx, cancel := TimeoutContext(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	hang := make(chan struct{})
+	defer close(hang)
+
+	start := time.Now()
+	_, err := withContext(ctx, "reading /mnt/nfs", func() ([]string, error) {
+		<-hang
+		return nil, nil
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("withContext() error = %v, want deadline exceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("withContext() returned after %v, want promptly after the timeout", elapsed)
+	}
+}
+
+// TestListAvailableFilesContext tests listing with a live and a canceled context
+func TestListAvailableFilesContext(t *testing.T) {
+	sourceDir, _ := setupSourceTarget(t, "a.conf", "b.conf")
+
+	files, err := ListAvailableFilesContext(context.Background(), sourceDir, Options{})
+	if err != nil {
+		t.Fatalf("ListAvailableFilesContext failed: %v", err)
+	}
+	if want := []string{"a.conf", "b.conf"}; !reflect.DeepEqual(files, want) {
+		t.Errorf("ListAvailableFilesContext() = %v, want %v", files, want)
+	}
+
+	ctx, cancel := TimeoutContext(context.Background(), 0)
+	cancel()
+	if _, err := ListAvailableFilesContext(ctx, sourceDir, Options{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("ListAvailableFilesContext() error = %v, want canceled", err)
+	}
+}
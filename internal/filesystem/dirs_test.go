@@ -0,0 +1,81 @@
+package filesystem
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestCheckDirExists tests directory existence checks
+func TestCheckDirExists(t *testing.T) {
+	tempDir := t.TempDir()
+
+	tests := []struct {
+		name      string
+		setupFunc func() string
+		wantError bool
+		errorMsg  string
+	}{
+		{
+			name: "valid directory",
+			setupFunc: func() string {
+				dir := filepath.Join(tempDir, "valid")
+				_ = os.MkdirAll(dir, 0755)
+				return dir
+			},
+			wantError: false,
+		},
+		{
+			name: "non-existent directory",
+			setupFunc: func() string {
+				return filepath.Join(tempDir, "nonexistent")
+			},
+			wantError: true,
+			errorMsg:  "does not exist",
+		},
+		{
+			name: "file instead of directory",
+			setupFunc: func() string {
+				file := filepath.Join(tempDir, "file.txt")
+				_ = os.WriteFile(file, []byte("test"), 0644)
+				return file
+			},
+			wantError: true,
+			errorMsg:  "is not a directory",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := tt.setupFunc()
+			err := CheckDirExists(path)
+			if tt.wantError {
+				if err == nil {
+					t.Errorf("CheckDirExists() expected error but got none")
+				} else if tt.errorMsg != "" && !strings.Contains(err.Error(), tt.errorMsg) {
+					t.Errorf("CheckDirExists() error = %v, want error containing %q", err, tt.errorMsg)
+				}
+			} else {
+				if err != nil {
+					t.Errorf("CheckDirExists() unexpected error = %v", err)
+				}
+			}
+		})
+	}
+}
+
+// TestApplyChangesWithOptions_SourceRemoved tests that a source directory
+// vanishing between load and apply yields a clear error before any change
+func TestApplyChangesWithOptions_SourceRemoved(t *testing.T) {
+	sourceDir, targetDir := setupSourceTarget(t, "a.conf")
+
+	if err := os.RemoveAll(sourceDir); err != nil {
+		t.Fatalf("Failed to remove source directory: %v", err)
+	}
+
+	_, err := ApplyChangesWithOptions(sourceDir, targetDir, []string{"a.conf"}, ApplyOptions{})
+	if err == nil || !strings.Contains(err.Error(), "source directory is no longer available") {
+		t.Errorf("Expected source directory error, got %v", err)
+	}
+}
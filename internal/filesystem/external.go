@@ -0,0 +1,53 @@
+package filesystem
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// FindExternalLinks returns the paths of the symlinks in the given
+// directories (not their subdirectories) that lead to sourceFile, e.g. to
+// spot a file already managed in another target directory. Broken links
+// are ignored.
+func FindExternalLinks(sourceFile string, dirs []string) ([]string, error) {
+	// Compare real paths so relative, absolute and chained links all match
+	realSource, err := evalRealPath(sourceFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve source file: %w", err)
+	}
+
+	links, err := MapExternalLinks(dirs)
+	if err != nil {
+		return nil, err
+	}
+	return links[realSource], nil
+}
+
+// MapExternalLinks reads each of the given directories (not their
+// subdirectories) once and maps the real path every symlink leads to onto
+// the paths of those symlinks, in directory order. Look up a source file by
+// its absolute real path (filepath.Abs, then filepath.EvalSymlinks) to find
+// the links to it. Broken
+// links are ignored.
+func MapExternalLinks(dirs []string) (map[string][]string, error) {
+	links := make(map[string][]string)
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read directory %s: %w", dir, err)
+		}
+		for _, entry := range entries {
+			if entry.Type()&os.ModeSymlink == 0 {
+				continue
+			}
+			link := filepath.Join(dir, entry.Name())
+			realTarget, err := evalRealPath(link)
+			if err != nil {
+				continue
+			}
+			links[realTarget] = append(links[realTarget], link)
+		}
+	}
+	return links, nil
+}
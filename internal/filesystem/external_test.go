@@ -0,0 +1,51 @@
+package filesystem
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// TestFindExternalLinks tests finding the links to a source file in other
+// directories
+func TestFindExternalLinks(t *testing.T) {
+	sourceDir, targetDir := setupSourceTarget(t, "app.conf", "other.conf")
+	otherDir := t.TempDir()
+	sourceFile := filepath.Join(sourceDir, "app.conf")
+
+	links := map[string]string{
+		filepath.Join(targetDir, "app.conf"):    sourceFile,                             // absolute
+		filepath.Join(otherDir, "renamed.conf"): filepath.Join(sourceDir, "app.conf"),   // different name
+		filepath.Join(otherDir, "other.conf"):   filepath.Join(sourceDir, "other.conf"), // another source file
+		filepath.Join(otherDir, "broken.conf"):  filepath.Join(sourceDir, "gone.conf"),  // broken
+	}
+	relative, err := filepath.Rel(otherDir, sourceFile)
+	if err != nil {
+		t.Fatalf("Failed to make relative path: %v", err)
+	}
+	links[filepath.Join(otherDir, "relative.conf")] = relative
+
+	for link, target := range links {
+		if err := os.Symlink(target, link); err != nil {
+			t.Fatalf("Failed to create symlink %s: %v", link, err)
+		}
+	}
+	// A copy is no link
+	if err := os.WriteFile(filepath.Join(otherDir, "copy.conf"), []byte("content"), 0644); err != nil {
+		t.Fatalf("Failed to create copy: %v", err)
+	}
+
+	got, err := FindExternalLinks(sourceFile, []string{targetDir, otherDir})
+	if err != nil {
+		t.Fatalf("FindExternalLinks failed: %v", err)
+	}
+	want := []string{filepath.Join(targetDir, "app.conf"), filepath.Join(otherDir, "relative.conf"), filepath.Join(otherDir, "renamed.conf")}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FindExternalLinks() = %v, want %v", got, want)
+	}
+
+	if _, err := FindExternalLinks(sourceFile, []string{filepath.Join(otherDir, "missing")}); err == nil {
+		t.Error("FindExternalLinks() with a missing directory should fail")
+	}
+}
@@ -0,0 +1,36 @@
+package filesystem
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// maxFollowDepth limits how many symlinks followChain follows, so cycles
+// are reported instead of followed forever
+const maxFollowDepth = 32
+
+// followChain follows the symlink at path hop by hop and returns the path
+// each hop points to. The last path is the final target, which is not a
+// symlink or does not exist. Cycles and links that cannot be read are errors.
+func followChain(path string) ([]string, error) {
+	var chain []string
+	current := path
+	for i := 0; i < maxFollowDepth; i++ {
+		info, err := os.Lstat(current)
+		if err != nil || info.Mode()&os.ModeSymlink == 0 {
+			// Not a symlink (or missing): the chain ends here
+			return chain, nil
+		}
+		target, err := os.Readlink(current)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read symlink %s: %w", current, err)
+		}
+		if !filepath.IsAbs(target) {
+			target = filepath.Join(realPath(filepath.Dir(current)), target)
+		}
+		chain = append(chain, target)
+		current = target
+	}
+	return nil, fmt.Errorf("more than %d symlinks to follow from %s, possibly a cycle", maxFollowDepth, path)
+}
@@ -0,0 +1,85 @@
+package filesystem
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// TestGetEnabledFilesWithOptions_Follow tests recognizing a link to a link
+// to a source file
+func TestGetEnabledFilesWithOptions_Follow(t *testing.T) {
+	sourceDir, targetDir := setupSourceTarget(t, "a.conf")
+	hopDir := filepath.Join(filepath.Dir(sourceDir), "hop")
+	if err := os.Mkdir(hopDir, 0755); err != nil {
+		t.Fatalf("Failed to create hop dir: %v", err)
+	}
+
+	// target/a.conf -> hop/a.conf -> source/a.conf
+	if err := os.Symlink(filepath.Join("..", "source", "a.conf"), filepath.Join(hopDir, "a.conf")); err != nil {
+		t.Fatalf("Failed to create intermediate symlink: %v", err)
+	}
+	if err := os.Symlink(filepath.Join("..", "hop", "a.conf"), filepath.Join(targetDir, "a.conf")); err != nil {
+		t.Fatalf("Failed to create symlink: %v", err)
+	}
+
+	enabled, err := GetEnabledFiles(sourceDir, targetDir)
+	if err != nil || len(enabled) != 0 {
+		t.Errorf("GetEnabledFiles() = %v, %v, want two-hop link ignored without Follow", enabled, err)
+	}
+
+	state, err := ReadTargetState(sourceDir, targetDir, Options{Follow: true})
+	if err != nil {
+		t.Fatalf("ReadTargetState failed: %v", err)
+	}
+	if !reflect.DeepEqual(state.Enabled, []string{"a.conf"}) {
+		t.Errorf("Enabled = %v, want [a.conf]", state.Enabled)
+	}
+	if want := filepath.Join(realPath(sourceDir), "a.conf"); state.Links["a.conf"] != want {
+		t.Errorf("Links[a.conf] = %q, want final target %q", state.Links["a.conf"], want)
+	}
+}
+
+// TestFollowChain_Cycle tests that a symlink cycle is reported as a warning
+// and skipped instead of failing the load
+func TestFollowChain_Cycle(t *testing.T) {
+	sourceDir, targetDir := setupSourceTarget(t, "a.conf", "c.conf")
+	if err := CreateSymlink(sourceDir, targetDir, "c.conf"); err != nil {
+		t.Fatalf("Failed to create symlink: %v", err)
+	}
+
+	// target/a.conf -> target/b.conf -> target/a.conf
+	if err := os.Symlink("b.conf", filepath.Join(targetDir, "a.conf")); err != nil {
+		t.Fatalf("Failed to create symlink: %v", err)
+	}
+	if err := os.Symlink("a.conf", filepath.Join(targetDir, "b.conf")); err != nil {
+		t.Fatalf("Failed to create symlink: %v", err)
+	}
+
+	if _, err := followChain(filepath.Join(targetDir, "a.conf")); err == nil || !strings.Contains(err.Error(), "cycle") {
+		t.Errorf("followChain() error = %v, want cycle error", err)
+	}
+
+	var warnings []string
+	opts := Options{
+		Follow: true,
+		Warnf: func(format string, args ...any) {
+			warnings = append(warnings, fmt.Sprintf(format, args...))
+		},
+	}
+	enabled, err := GetEnabledFilesWithOptions(sourceDir, targetDir, opts)
+	if err != nil {
+		t.Fatalf("GetEnabledFilesWithOptions failed: %v", err)
+	}
+	if !reflect.DeepEqual(enabled, []string{"c.conf"}) {
+		t.Errorf("enabled = %v, want [c.conf]", enabled)
+	}
+	sort.Strings(warnings)
+	if len(warnings) != 2 || !strings.HasPrefix(warnings[0], "skipping a.conf") || !strings.Contains(warnings[0], "cycle") {
+		t.Errorf("warnings = %v, want both links of the cycle skipped", warnings)
+	}
+}
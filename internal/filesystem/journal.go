@@ -0,0 +1,116 @@
+package filesystem
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ErrNoUndoRecord is returned by LoadUndoRecord when no apply was recorded
+// for a target directory
+var ErrNoUndoRecord = errors.New("no undo record")
+
+// UndoRecord describes the most recent apply to a target directory
+type UndoRecord struct {
+	SourceDir   string         `json:"source"`
+	Previous    []string       `json:"previous"` // Files enabled before the apply
+	Applied     []string       `json:"applied"`  // Files enabled after the apply
+	Mode        LinkMode       `json:"mode,omitempty"`
+	LinkPrefix  string         `json:"linkPrefix,omitempty"`
+	Style       LinkStyle      `json:"style,omitempty"`
+	MaxUpLevels int            `json:"maxUpLevels,omitempty"`
+	Recursive   bool           `json:"recursive,omitempty"`
+	Dirs        bool           `json:"dirs,omitempty"`
+	Hidden      bool           `json:"hidden,omitempty"`
+	Follow      bool           `json:"follow,omitempty"`
+	Rename      *RenamePattern `json:"rename,omitempty"`
+	Include     []string       `json:"include,omitempty"`
+	Exclude     []string       `json:"exclude,omitempty"`
+
+	LinkNames         map[string]string `json:"linkNames,omitempty"`         // Custom link names after the apply
+	PreviousLinkNames map[string]string `json:"previousLinkNames,omitempty"` // Custom link names before the apply
+}
+
+// Options returns the link options the recorded apply used
+func (r *UndoRecord) Options() Options {
+	return Options{Mode: r.Mode, LinkPrefix: r.LinkPrefix, Style: r.Style, MaxUpLevels: r.MaxUpLevels, Recursive: r.Recursive, Dirs: r.Dirs, Hidden: r.Hidden, Follow: r.Follow, Rename: r.Rename,
+		Include: r.Include, Exclude: r.Exclude, LinkNames: r.LinkNames, PreviousLinkNames: r.PreviousLinkNames}
+}
+
+// JournalPath returns the path of the undo journal
+// (e.g. ~/.config/lnka/undo.json on Linux)
+func JournalPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate config directory: %w", err)
+	}
+	return filepath.Join(dir, "lnka", "undo.json"), nil
+}
+
+// SaveUndoRecord stores the record for the target directory, replacing the
+// previous record of that target. Records of other targets are kept.
+func SaveUndoRecord(targetDir string, record UndoRecord) error {
+	journal, path, key, err := readJournal(targetDir)
+	if err != nil {
+		return err
+	}
+	journal[key] = record
+
+	data, err := json.MarshalIndent(journal, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode undo journal: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write undo journal: %w", err)
+	}
+	return nil
+}
+
+// LoadUndoRecord returns the record of the most recent apply to the target
+// directory, or ErrNoUndoRecord if there is none
+func LoadUndoRecord(targetDir string) (*UndoRecord, error) {
+	journal, _, key, err := readJournal(targetDir)
+	if err != nil {
+		return nil, err
+	}
+	record, ok := journal[key]
+	if !ok {
+		return nil, ErrNoUndoRecord
+	}
+	return &record, nil
+}
+
+// readJournal reads the undo journal and returns it with its path and the
+// key of the target directory. A missing journal is empty.
+func readJournal(targetDir string) (map[string]UndoRecord, string, string, error) {
+	path, err := JournalPath()
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	key, err := filepath.Abs(targetDir)
+	if err != nil {
+		return nil, "", "", fmt.Errorf("failed to resolve target directory: %w", err)
+	}
+
+	journal := make(map[string]UndoRecord)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return journal, path, key, nil
+		}
+		return nil, "", "", fmt.Errorf("failed to read undo journal: %w", err)
+	}
+	if err := json.Unmarshal(data, &journal); err != nil {
+		return nil, "", "", fmt.Errorf("failed to parse undo journal %s: %w", path, err)
+	}
+	if journal == nil {
+		journal = make(map[string]UndoRecord) // "null" journal
+	}
+	return journal, path, key, nil
+}
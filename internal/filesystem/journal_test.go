@@ -0,0 +1,61 @@
+package filesystem
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/marco-arnold/lnka/internal/testutil"
+)
+
+func TestSaveAndLoadUndoRecord(t *testing.T) {
+	testutil.UseTempConfigDir(t)
+	base := t.TempDir()
+	targetA := filepath.Join(base, "a")
+	targetB := filepath.Join(base, "b")
+
+	if _, err := LoadUndoRecord(targetA); !errors.Is(err, ErrNoUndoRecord) {
+		t.Fatalf("LoadUndoRecord() error = %v, want ErrNoUndoRecord", err)
+	}
+
+	recordA := UndoRecord{SourceDir: "/src", Previous: []string{"a.conf"}, Applied: []string{"b.conf"}, Mode: LinkModeCopy, Exclude: []string{"*.bak"}}
+	recordB := UndoRecord{SourceDir: "/src", Previous: []string{}, Applied: []string{"c.conf"}}
+	if err := SaveUndoRecord(targetA, recordA); err != nil {
+		t.Fatalf("SaveUndoRecord failed: %v", err)
+	}
+	if err := SaveUndoRecord(targetB, recordB); err != nil {
+		t.Fatalf("SaveUndoRecord failed: %v", err)
+	}
+
+	got, err := LoadUndoRecord(targetA)
+	if err != nil {
+		t.Fatalf("LoadUndoRecord failed: %v", err)
+	}
+	if !reflect.DeepEqual(*got, recordA) {
+		t.Errorf("LoadUndoRecord() = %+v, want %+v", *got, recordA)
+	}
+	if opts := got.Options(); opts.Mode != LinkModeCopy || !reflect.DeepEqual(opts.Exclude, []string{"*.bak"}) {
+		t.Errorf("Options() = %+v, want copy mode excluding *.bak", opts)
+	}
+
+	// Saving again replaces only the record of that target
+	recordA.Previous = []string{"b.conf"}
+	if err := SaveUndoRecord(targetA, recordA); err != nil {
+		t.Fatalf("SaveUndoRecord failed: %v", err)
+	}
+	got, err = LoadUndoRecord(targetA)
+	if err != nil {
+		t.Fatalf("LoadUndoRecord failed: %v", err)
+	}
+	if !reflect.DeepEqual(got.Previous, []string{"b.conf"}) {
+		t.Errorf("Previous = %v, want [b.conf]", got.Previous)
+	}
+	got, err = LoadUndoRecord(targetB)
+	if err != nil {
+		t.Fatalf("LoadUndoRecord failed: %v", err)
+	}
+	if !reflect.DeepEqual(*got, recordB) {
+		t.Errorf("LoadUndoRecord() = %+v, want %+v", *got, recordB)
+	}
+}
@@ -0,0 +1,209 @@
+package filesystem
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// LinkMode selects how selected files are made available in the target directory
+type LinkMode string
+
+// Available link modes
+const (
+	LinkModeSymlink  LinkMode = "symlink"  // Symlink to the source file (default)
+	LinkModeCopy     LinkMode = "copy"     // Regular copy, for filesystems without symlinks
+	LinkModeHardlink LinkMode = "hardlink" // Hard link, source and target must share a filesystem
+)
+
+// ParseLinkMode parses a --mode value (empty = symlink)
+func ParseLinkMode(s string) (LinkMode, error) {
+	switch LinkMode(s) {
+	case "", LinkModeSymlink:
+		return LinkModeSymlink, nil
+	case LinkModeCopy, LinkModeHardlink:
+		return LinkMode(s), nil
+	}
+	return "", fmt.Errorf("invalid link mode %q: expected %s, %s or %s", s, LinkModeSymlink, LinkModeCopy, LinkModeHardlink)
+}
+
+// LinkStyle selects the form of the target path of new symlinks
+type LinkStyle string
+
+// Available link styles
+const (
+	LinkStyleAuto     LinkStyle = "auto"     // Relative path, absolute when more than DefaultMaxUpLevels up (default)
+	LinkStyleRelative LinkStyle = "relative" // Path relative to the link's directory
+	LinkStyleAbsolute LinkStyle = "absolute" // Absolute path of the source file
+)
+
+// ParseLinkStyle parses a --link-style value (empty = auto)
+func ParseLinkStyle(s string) (LinkStyle, error) {
+	switch LinkStyle(s) {
+	case "", LinkStyleAuto:
+		return LinkStyleAuto, nil
+	case LinkStyleRelative:
+		return LinkStyleRelative, nil
+	case LinkStyleAbsolute:
+		return LinkStyleAbsolute, nil
+	}
+	return "", fmt.Errorf("invalid link style %q: expected %s, %s or %s", s, LinkStyleAuto, LinkStyleRelative, LinkStyleAbsolute)
+}
+
+// usesFiles reports whether the mode creates regular files instead of symlinks
+func (m LinkMode) usesFiles() bool {
+	return m == LinkModeCopy || m == LinkModeHardlink
+}
+
+// createFileLink copies or hard links the source file to linkPath
+func createFileLink(sourcePath, linkPath string, mode LinkMode) error {
+	if mode == LinkModeHardlink {
+		if err := os.Link(sourcePath, linkPath); err != nil {
+			if errors.Is(err, syscall.EXDEV) {
+				return fmt.Errorf("cannot hard link across filesystems (use --mode copy): %w", err)
+			}
+			return err
+		}
+		return nil
+	}
+	return copyFile(sourcePath, linkPath)
+}
+
+// copyFile copies the source file to dst, preserving its permissions and
+// modification time
+func copyFile(src, dst string) error {
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		_ = os.Remove(dst)
+		return err
+	}
+	if err := out.Close(); err != nil {
+		_ = os.Remove(dst)
+		return err
+	}
+
+	// OpenFile applies the umask, set the exact permissions afterwards
+	if err := os.Chmod(dst, info.Mode().Perm()); err != nil {
+		return err
+	}
+	return os.Chtimes(dst, info.ModTime(), info.ModTime())
+}
+
+// isFileLinked reports whether the regular file at linkPath is a copy or hard
+// link of the source file, depending on the mode. Copies keep the modification
+// time of their source, so one with the size and time of the source is taken
+// as equal without comparing the content.
+func isFileLinked(sourcePath, linkPath string, mode LinkMode) bool {
+	linkInfo, err := os.Lstat(linkPath)
+	if err != nil || !linkInfo.Mode().IsRegular() {
+		return false
+	}
+	sourceInfo, err := os.Stat(sourcePath)
+	if err != nil {
+		return false
+	}
+
+	if mode == LinkModeHardlink {
+		return os.SameFile(sourceInfo, linkInfo)
+	}
+
+	if sourceInfo.Size() != linkInfo.Size() {
+		return false
+	}
+	if sourceInfo.ModTime().Equal(linkInfo.ModTime()) {
+		return true
+	}
+	sourceHash, err1 := fileHash(sourcePath)
+	linkHash, err2 := fileHash(linkPath)
+	return err1 == nil && err2 == nil && bytes.Equal(sourceHash, linkHash)
+}
+
+// fileHash returns the SHA-256 hash of the file content
+func fileHash(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+// enabledFileLinks returns the available files that are copied or hard
+// linked into the target directory. In copy mode any regular file at the link
+// name is a copy; those no longer matching their source (e.g. the source was
+// edited since) are returned as outdated as well.
+func enabledFileLinks(sourceDir, targetDir string, opts Options) (enabled, outdated []string, err error) {
+	available, err := ListAvailableFilesWithOptions(sourceDir, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	if opts.Rename != nil || len(opts.LinkNames) > 0 {
+		if _, err := opts.linkNames(available); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	for _, name := range available {
+		sourcePath, linkPath := filepath.Join(sourceDir, name), filepath.Join(targetDir, opts.linkName(name))
+		switch {
+		case isFileLinked(sourcePath, linkPath, opts.Mode):
+			enabled = append(enabled, name)
+		case opts.Mode == LinkModeCopy && isRegularFile(linkPath):
+			enabled = append(enabled, name)
+			outdated = append(outdated, name)
+		}
+	}
+	return enabled, outdated, nil
+}
+
+// isRegularFile reports whether path is a regular file (not following symlinks)
+func isRegularFile(path string) bool {
+	info, err := os.Lstat(path)
+	return err == nil && info.Mode().IsRegular()
+}
+
+// removeLink removes a managed file from the target directory: a symlink, a
+// copy (also an outdated one) or a hard link of its source
+func removeLink(sourceDir, targetDir, name string, opts Options) error {
+	if !opts.Mode.usesFiles() {
+		return RemoveSymlink(targetDir, opts.linkName(name))
+	}
+
+	linkPath := filepath.Join(targetDir, opts.linkName(name))
+	if _, err := os.Lstat(linkPath); os.IsNotExist(err) {
+		return nil
+	}
+	copied := opts.Mode == LinkModeCopy && isRegularFile(linkPath)
+	if !copied && !isFileLinked(filepath.Join(sourceDir, name), linkPath, opts.Mode) {
+		return fmt.Errorf("%s does not match its source file, refusing to remove", name)
+	}
+	if err := os.Remove(linkPath); err != nil {
+		return fmt.Errorf("failed to remove %s: %w", name, err)
+	}
+	return nil
+}
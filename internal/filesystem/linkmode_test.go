@@ -0,0 +1,171 @@
+package filesystem
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+// TestParseLinkMode tests parsing --mode values
+func TestParseLinkMode(t *testing.T) {
+	for input, want := range map[string]LinkMode{
+		"":         LinkModeSymlink,
+		"symlink":  LinkModeSymlink,
+		"copy":     LinkModeCopy,
+		"hardlink": LinkModeHardlink,
+	} {
+		got, err := ParseLinkMode(input)
+		if err != nil || got != want {
+			t.Errorf("ParseLinkMode(%q) = %q, %v, want %q", input, got, err, want)
+		}
+	}
+
+	if _, err := ParseLinkMode("junction"); err == nil {
+		t.Error("ParseLinkMode(junction) expected error")
+	}
+}
+
+// TestParseLinkStyle tests parsing --link-style values
+func TestParseLinkStyle(t *testing.T) {
+	for input, want := range map[string]LinkStyle{
+		"":         LinkStyleAuto,
+		"auto":     LinkStyleAuto,
+		"relative": LinkStyleRelative,
+		"absolute": LinkStyleAbsolute,
+	} {
+		got, err := ParseLinkStyle(input)
+		if err != nil || got != want {
+			t.Errorf("ParseLinkStyle(%q) = %q, %v, want %q", input, got, err, want)
+		}
+	}
+
+	if _, err := ParseLinkStyle("shortest"); err == nil {
+		t.Error("ParseLinkStyle(shortest) expected error")
+	}
+}
+
+// TestApplyChanges_CopyMode tests copying, detecting and removing copies
+func TestApplyChanges_CopyMode(t *testing.T) {
+	sourceDir, targetDir := setupSourceTarget(t, "a.conf", "b.conf")
+	if err := os.Chmod(filepath.Join(sourceDir, "a.conf"), 0600); err != nil {
+		t.Fatalf("Chmod failed: %v", err)
+	}
+	opts := ApplyOptions{Options: Options{Mode: LinkModeCopy}}
+
+	if _, err := ApplyChangesWithOptions(sourceDir, targetDir, []string{"a.conf", "b.conf"}, opts); err != nil {
+		t.Fatalf("ApplyChangesWithOptions failed: %v", err)
+	}
+
+	info, err := os.Lstat(filepath.Join(targetDir, "a.conf"))
+	if err != nil {
+		t.Fatalf("copy not created: %v", err)
+	}
+	if !info.Mode().IsRegular() {
+		t.Errorf("a.conf mode = %v, want a regular file", info.Mode())
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("a.conf permissions = %o, want 600", info.Mode().Perm())
+	}
+
+	// A copy of an edited source is enabled but outdated
+	if err := os.WriteFile(filepath.Join(sourceDir, "b.conf"), []byte("edited"), 0644); err != nil {
+		t.Fatalf("Failed to edit source: %v", err)
+	}
+	state, err := ReadTargetState(sourceDir, targetDir, opts.Options)
+	if err != nil {
+		t.Fatalf("ReadTargetState failed: %v", err)
+	}
+	if !reflect.DeepEqual(state.Enabled, []string{"a.conf", "b.conf"}) || !reflect.DeepEqual(state.Outdated, []string{"b.conf"}) {
+		t.Errorf("enabled = %v, outdated = %v, want both enabled and b.conf outdated", state.Enabled, state.Outdated)
+	}
+
+	// Applying the selection again updates it
+	result, err := ApplyChangesWithOptions(sourceDir, targetDir, []string{"a.conf", "b.conf"}, opts)
+	if err != nil {
+		t.Fatalf("ApplyChangesWithOptions failed: %v", err)
+	}
+	if !reflect.DeepEqual(result.Relinked, []string{"b.conf"}) || !reflect.DeepEqual(result.Unchanged, []string{"a.conf"}) {
+		t.Errorf("relinked = %v, unchanged = %v, want b.conf updated", result.Relinked, result.Unchanged)
+	}
+	if data, _ := os.ReadFile(filepath.Join(targetDir, "b.conf")); string(data) != "edited" {
+		t.Errorf("b.conf = %q, want the edited content", data)
+	}
+
+	// Deselecting removes copies, also outdated ones
+	if err := os.WriteFile(filepath.Join(targetDir, "b.conf"), []byte("changed in the target"), 0644); err != nil {
+		t.Fatalf("Failed to edit copy: %v", err)
+	}
+	if _, err := ApplyChangesWithOptions(sourceDir, targetDir, nil, opts); err != nil {
+		t.Fatalf("ApplyChangesWithOptions failed: %v", err)
+	}
+	for _, name := range []string{"a.conf", "b.conf"} {
+		if _, err := os.Lstat(filepath.Join(targetDir, name)); !os.IsNotExist(err) {
+			t.Errorf("copy %s was not removed: %v", name, err)
+		}
+	}
+}
+
+// TestApplyChanges_HardlinkMode tests creating and detecting hard links
+func TestApplyChanges_HardlinkMode(t *testing.T) {
+	sourceDir, targetDir := setupSourceTarget(t, "a.conf", "b.conf")
+	opts := ApplyOptions{Options: Options{Mode: LinkModeHardlink}}
+
+	if _, err := ApplyChangesWithOptions(sourceDir, targetDir, []string{"a.conf"}, opts); err != nil {
+		t.Fatalf("ApplyChangesWithOptions failed: %v", err)
+	}
+
+	sourceInfo, _ := os.Stat(filepath.Join(sourceDir, "a.conf"))
+	linkInfo, err := os.Lstat(filepath.Join(targetDir, "a.conf"))
+	if err != nil {
+		t.Fatalf("hard link not created: %v", err)
+	}
+	if !os.SameFile(sourceInfo, linkInfo) {
+		t.Error("a.conf is not a hard link of the source file")
+	}
+
+	// An identical copy is not a hard link
+	if err := copyFile(filepath.Join(sourceDir, "b.conf"), filepath.Join(targetDir, "b.conf")); err != nil {
+		t.Fatalf("copyFile failed: %v", err)
+	}
+	enabled, err := GetEnabledFilesWithOptions(sourceDir, targetDir, opts.Options)
+	if err != nil {
+		t.Fatalf("GetEnabledFilesWithOptions failed: %v", err)
+	}
+	if !reflect.DeepEqual(enabled, []string{"a.conf"}) {
+		t.Errorf("enabled = %v, want [a.conf]", enabled)
+	}
+}
+
+// TestCreateSymlink_HardlinkAcrossDevices tests the error for hard links
+// between filesystems
+func TestCreateSymlink_HardlinkAcrossDevices(t *testing.T) {
+	sourceDir := t.TempDir()
+	targetDir, err := os.MkdirTemp("/dev/shm", "lnka-test")
+	if err != nil {
+		t.Skip("no second filesystem available at /dev/shm")
+	}
+	t.Cleanup(func() { _ = os.RemoveAll(targetDir) })
+
+	if err := os.WriteFile(filepath.Join(sourceDir, "a.conf"), []byte("test"), 0644); err != nil {
+		t.Fatalf("Failed to create source file: %v", err)
+	}
+
+	// Probe whether the directories are on different filesystems
+	probe := filepath.Join(targetDir, "probe")
+	if err := os.Link(filepath.Join(sourceDir, "a.conf"), probe); !errors.Is(err, syscall.EXDEV) {
+		_ = os.Remove(probe)
+		t.Skip("source and /dev/shm are on the same filesystem")
+	}
+
+	err = CreateSymlinkWithOptions(sourceDir, targetDir, "a.conf", Options{Mode: LinkModeHardlink})
+	if err == nil {
+		t.Fatal("CreateSymlinkWithOptions expected an error across filesystems")
+	}
+	if !strings.Contains(err.Error(), "across filesystems") {
+		t.Errorf("error = %v, want a cross-filesystem hint", err)
+	}
+}
@@ -0,0 +1,71 @@
+package filesystem
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// FindMismatchedSymlinks finds symlinks in the target directory whose target
+// exists but does not live inside the source directory (e.g. links into an
+// old source location). Returns a map of symlink name to its current target.
+func FindMismatchedSymlinks(sourceDir, targetDir string) (map[string]string, error) {
+	return FindMismatchedSymlinksWithOptions(sourceDir, targetDir, Options{})
+}
+
+// FindMismatchedSymlinksWithOptions finds mismatched symlinks like
+// FindMismatchedSymlinks, honoring the given options
+func FindMismatchedSymlinksWithOptions(sourceDir, targetDir string, opts Options) (map[string]string, error) {
+	symlinks, err := listSymlinks(sourceDir, targetDir, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// Compare real paths so symlinked parent directories don't cause mismatches
+	realSource, err := evalRealPath(sourceDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve source directory: %w", err)
+	}
+
+	mismatched := make(map[string]string)
+	for name, target := range symlinks {
+		linkDir := filepath.Join(targetDir, filepath.Dir(name))
+		resolved := opts.resolveLinkTarget(sourceDir, linkDir, target, nil)
+
+		realTarget, err := evalRealPath(resolved)
+		if err != nil {
+			// Broken links are handled by ValidateSymlinks
+			continue
+		}
+
+		if !isInside(realSource, realTarget) {
+			mismatched[name] = target
+		}
+	}
+
+	return mismatched, nil
+}
+
+// RepointSymlinks replaces the given symlinks with links to the files of the
+// same name in the source directory
+func RepointSymlinks(sourceDir, targetDir string, names []string, opts Options) error {
+	for _, name := range names {
+		if _, err := os.Stat(filepath.Join(sourceDir, name)); err != nil {
+			return fmt.Errorf("cannot re-point %s: not available in source directory", name)
+		}
+		if err := CreateSymlinkWithOptions(sourceDir, targetDir, name, opts); err != nil {
+			return fmt.Errorf("failed to re-point symlink %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// isInside reports whether path is dir or located below it
+func isInside(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,105 @@
+package filesystem
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// TestFindMismatchedSymlinks tests which links count as pointing outside the source
+func TestFindMismatchedSymlinks(t *testing.T) {
+	sourceDir, targetDir := setupSourceTarget(t, "inside.conf", "sub/nested.conf")
+
+	// Sibling directory of the source, e.g. an old source location
+	oldSource := filepath.Join(filepath.Dir(sourceDir), "old-source")
+	if err := os.MkdirAll(oldSource, 0755); err != nil {
+		t.Fatalf("Failed to create old source: %v", err)
+	}
+	oldFile := filepath.Join(oldSource, "moved.conf")
+	if err := os.WriteFile(oldFile, []byte("old"), 0644); err != nil {
+		t.Fatalf("Failed to create old file: %v", err)
+	}
+
+	links := map[string]string{
+		"moved.conf":  oldFile,                                        // sibling directory
+		"nested.conf": filepath.Join(sourceDir, "sub", "nested.conf"), // subdirectory of source
+		"inside.conf": filepath.Join(sourceDir, "inside.conf"),        // absolute into source
+		"broken.conf": filepath.Join(oldSource, "gone.conf"),          // handled by ValidateSymlinks
+	}
+	for name, target := range links {
+		if err := os.Symlink(target, filepath.Join(targetDir, name)); err != nil {
+			t.Fatalf("Failed to create symlink %s: %v", name, err)
+		}
+	}
+
+	got, err := FindMismatchedSymlinks(sourceDir, targetDir)
+	if err != nil {
+		t.Fatalf("FindMismatchedSymlinks failed: %v", err)
+	}
+
+	want := map[string]string{"moved.conf": oldFile}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FindMismatchedSymlinks() = %v, want %v", got, want)
+	}
+}
+
+// TestFindMismatchedSymlinks_RelativePaths tests that a link created with
+// relative source and target paths counts as pointing into the source
+func TestFindMismatchedSymlinks_RelativePaths(t *testing.T) {
+	t.Chdir(t.TempDir())
+	for _, dir := range []string{"src", "tgt"} {
+		if err := os.Mkdir(dir, 0755); err != nil {
+			t.Fatalf("Failed to create %s: %v", dir, err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join("src", "a.conf"), []byte("test"), 0644); err != nil {
+		t.Fatalf("Failed to create source file: %v", err)
+	}
+	if err := CreateSymlink("src", "tgt", "a.conf"); err != nil {
+		t.Fatalf("CreateSymlink failed: %v", err)
+	}
+
+	mismatched, err := FindMismatchedSymlinks("src", "tgt")
+	if err != nil || len(mismatched) != 0 {
+		t.Errorf("FindMismatchedSymlinks() = %v, %v, want none", mismatched, err)
+	}
+
+	statuses, err := CollectStatus("src", "tgt", Options{})
+	if err != nil {
+		t.Fatalf("CollectStatus failed: %v", err)
+	}
+	if len(statuses) != 1 || !statuses[0].Enabled || statuses[0].Mismatched {
+		t.Errorf("CollectStatus() = %+v, want a.conf enabled and not mismatched", statuses)
+	}
+
+	unnormalized, err := FindUnnormalizedSymlinks("src", "tgt", Options{})
+	if err != nil || len(unnormalized) != 0 {
+		t.Errorf("FindUnnormalizedSymlinks() = %v, %v, want none", unnormalized, err)
+	}
+}
+
+// TestRepointSymlinks tests re-pointing a mismatched link at the source
+func TestRepointSymlinks(t *testing.T) {
+	sourceDir, targetDir := setupSourceTarget(t, "moved.conf")
+
+	oldFile := filepath.Join(t.TempDir(), "moved.conf")
+	if err := os.WriteFile(oldFile, []byte("old"), 0644); err != nil {
+		t.Fatalf("Failed to create old file: %v", err)
+	}
+	if err := os.Symlink(oldFile, filepath.Join(targetDir, "moved.conf")); err != nil {
+		t.Fatalf("Failed to create symlink: %v", err)
+	}
+
+	if err := RepointSymlinks(sourceDir, targetDir, []string{"moved.conf"}, Options{}); err != nil {
+		t.Fatalf("RepointSymlinks failed: %v", err)
+	}
+
+	enabled, err := GetEnabledFiles(sourceDir, targetDir)
+	if err != nil {
+		t.Fatalf("GetEnabledFiles failed: %v", err)
+	}
+	if !reflect.DeepEqual(enabled, []string{"moved.conf"}) {
+		t.Errorf("enabled = %v, want [moved.conf]", enabled)
+	}
+}
@@ -0,0 +1,96 @@
+package filesystem
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// NormalizeSymlinks rewrites symlinks into the source directory to the
+// canonical form produced by CreateSymlink (e.g. old absolute links become
+// relative). Returns the names of the rewritten symlinks.
+func NormalizeSymlinks(sourceDir, targetDir string) ([]string, error) {
+	return NormalizeSymlinksWithOptions(sourceDir, targetDir, Options{})
+}
+
+// NormalizeSymlinksWithOptions normalizes symlinks like NormalizeSymlinks,
+// honoring the given options
+func NormalizeSymlinksWithOptions(sourceDir, targetDir string, opts Options) ([]string, error) {
+	names, err := FindUnnormalizedSymlinks(sourceDir, targetDir, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range names {
+		if err := CreateSymlinkWithOptions(sourceDir, targetDir, name, opts); err != nil {
+			return nil, err
+		}
+	}
+
+	return names, nil
+}
+
+// FindUnnormalizedSymlinks returns the sorted names of the source files whose
+// link (at the name the options give it) points at them but differs from
+// the canonical form. Symlinks pointing outside the source, at another
+// source file or nowhere (broken links) are left alone.
+func FindUnnormalizedSymlinks(sourceDir, targetDir string, opts Options) ([]string, error) {
+	symlinks, err := listSymlinks(sourceDir, targetDir, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	realSource, err := evalRealPath(sourceDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve source directory: %w", err)
+	}
+
+	// Links are named after their source file, unless renamed
+	var sources map[string]string
+	if opts.Rename != nil || len(opts.LinkNames) > 0 {
+		if sources, err = opts.sourcesByLink(sourceDir); err != nil {
+			return nil, err
+		}
+	}
+
+	var names []string
+	for link, target := range symlinks {
+		name := link
+		if sources != nil {
+			name = sources[link]
+		}
+		if name == "" || !opts.managed(name) {
+			continue
+		}
+
+		// Never touch links pointing outside the source, even if they end up
+		// at the same file through another symlink
+		resolved := opts.resolveLinkTarget(sourceDir, filepath.Join(targetDir, filepath.Dir(link)), target, nil)
+		realDir, err := evalRealPath(filepath.Dir(resolved))
+		if err != nil || !isInside(realSource, realDir) {
+			continue
+		}
+
+		// The link and the source file must refer to the same file
+		linkInfo, err := os.Stat(filepath.Join(targetDir, link))
+		if err != nil {
+			continue
+		}
+		sourceInfo, err := os.Stat(filepath.Join(sourceDir, name))
+		if err != nil || !os.SameFile(linkInfo, sourceInfo) {
+			continue
+		}
+
+		canonical, _, err := canonicalTarget(sourceDir, targetDir, name, opts)
+		if err != nil {
+			return nil, err
+		}
+		if target != canonical {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+	return names, nil
+}
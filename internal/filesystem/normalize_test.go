@@ -0,0 +1,90 @@
+package filesystem
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// TestNormalizeSymlinks tests rewriting absolute links into the canonical relative form
+func TestNormalizeSymlinks(t *testing.T) {
+	sourceDir, targetDir := setupSourceTarget(t, "absolute.conf", "relative.conf")
+
+	outsideDir := t.TempDir()
+	outsideFile := filepath.Join(outsideDir, "outside.conf")
+	if err := os.WriteFile(outsideFile, []byte("outside"), 0644); err != nil {
+		t.Fatalf("Failed to create outside file: %v", err)
+	}
+
+	if err := os.Symlink(filepath.Join(sourceDir, "absolute.conf"), filepath.Join(targetDir, "absolute.conf")); err != nil {
+		t.Fatalf("Failed to create symlink: %v", err)
+	}
+	if err := CreateSymlink(sourceDir, targetDir, "relative.conf"); err != nil {
+		t.Fatalf("CreateSymlink failed: %v", err)
+	}
+	if err := os.Symlink(outsideFile, filepath.Join(targetDir, "outside.conf")); err != nil {
+		t.Fatalf("Failed to create symlink: %v", err)
+	}
+	if err := os.Symlink(filepath.Join(sourceDir, "missing.conf"), filepath.Join(targetDir, "missing.conf")); err != nil {
+		t.Fatalf("Failed to create symlink: %v", err)
+	}
+
+	rewritten, err := NormalizeSymlinks(sourceDir, targetDir)
+	if err != nil {
+		t.Fatalf("NormalizeSymlinks failed: %v", err)
+	}
+	if !reflect.DeepEqual(rewritten, []string{"absolute.conf"}) {
+		t.Errorf("rewritten = %v, want [absolute.conf]", rewritten)
+	}
+
+	target, err := os.Readlink(filepath.Join(targetDir, "absolute.conf"))
+	if err != nil {
+		t.Fatalf("Readlink failed: %v", err)
+	}
+	if filepath.IsAbs(target) {
+		t.Errorf("absolute.conf still points to absolute path %s", target)
+	}
+
+	// Links outside the source and broken links stay untouched
+	if target, _ := os.Readlink(filepath.Join(targetDir, "outside.conf")); target != outsideFile {
+		t.Errorf("outside.conf -> %s, want %s", target, outsideFile)
+	}
+	if _, err := os.Lstat(filepath.Join(targetDir, "missing.conf")); err != nil {
+		t.Errorf("broken link was removed: %v", err)
+	}
+
+	// Running again changes nothing
+	rewritten, err = NormalizeSymlinks(sourceDir, targetDir)
+	if err != nil {
+		t.Fatalf("second NormalizeSymlinks failed: %v", err)
+	}
+	if len(rewritten) != 0 {
+		t.Errorf("second run rewrote %v, want nothing", rewritten)
+	}
+}
+
+// TestFindUnnormalizedSymlinks_Renamed tests that links are matched to their
+// source file by the link name the options give it
+func TestFindUnnormalizedSymlinks_Renamed(t *testing.T) {
+	sourceDir, targetDir := setupSourceTarget(t, "a.conf", "b.conf")
+	rename, err := ParseRenamePattern("s/^/10-/")
+	if err != nil {
+		t.Fatalf("ParseRenamePattern failed: %v", err)
+	}
+
+	// a.conf is linked under the raw name, which is not its link name
+	for link, name := range map[string]string{"a.conf": "a.conf", "10-b.conf": "b.conf"} {
+		if err := os.Symlink(filepath.Join(sourceDir, name), filepath.Join(targetDir, link)); err != nil {
+			t.Fatalf("Failed to create symlink: %v", err)
+		}
+	}
+
+	names, err := FindUnnormalizedSymlinks(sourceDir, targetDir, Options{Rename: rename})
+	if err != nil {
+		t.Fatalf("FindUnnormalizedSymlinks failed: %v", err)
+	}
+	if want := []string{"b.conf"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("FindUnnormalizedSymlinks() = %v, want %v", names, want)
+	}
+}
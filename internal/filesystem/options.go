@@ -0,0 +1,248 @@
+package filesystem
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Options controls how symlinks are created and recognized.
+// The zero value gives the default behavior.
+type Options struct {
+	// LinkPrefix, when set, makes new symlinks point to LinkPrefix/name instead
+	// of a computed relative or absolute path. Symlinks using the prefix are
+	// recognized as pointing into the source directory. This is an escape hatch
+	// for layouts where the source is mounted at a different path for the
+	// consumer of the links (e.g. inside a container).
+	LinkPrefix string
+
+	// Style selects auto (zero value), relative or absolute symlink targets.
+	// LinkPrefix takes precedence.
+	Style LinkStyle
+
+	// MaxUpLevels limits the ".." components of relative targets; deeper
+	// links get an absolute target instead. Zero means DefaultMaxUpLevels
+	// with the auto style and no limit with the relative one, a negative
+	// value allows any depth.
+	MaxUpLevels int
+
+	// Mode selects symlinks (zero value), copies or hard links. Copies and hard
+	// links are recognized as enabled while they match their source file;
+	// LinkPrefix only applies to symlinks.
+	Mode LinkMode
+
+	// Backup moves a regular file found in place of a new link to name.bak
+	// (name.bak.1, name.bak.2, ... if taken) instead of removing it
+	Backup bool
+
+	// Overwrite lets a new link replace a file or directory in its place that
+	// is no symlink, e.g. once the user confirmed the conflict. Without it or
+	// Backup, creating such a link fails.
+	Overwrite bool
+
+	// Recursive lists the files of source subdirectories as relative paths
+	// (e.g. "apps/foo.conf"), links them into matching target subdirectories
+	// and scans target subdirectories for managed and orphaned symlinks
+	Recursive bool
+
+	// Include lists filepath.Match patterns; when set, only source files whose
+	// base name matches at least one of them are listed and managed.
+	// Brace alternatives like "*.{yml,yaml}" are not supported by filepath.Match.
+	Include []string
+
+	// Exclude lists filepath.Match patterns; source files whose base name
+	// matches any of them are neither listed nor managed (applied after Include)
+	Exclude []string
+
+	// Dirs lists the directories of the source next to its files, so they are
+	// linked as a whole. Only supported for symlinks and without Recursive.
+	Dirs bool
+
+	// Hidden lists and manages source files whose name (or, in recursive
+	// mode, that of a parent directory) starts with a dot. Links to hidden
+	// files are left alone otherwise.
+	Hidden bool
+
+	// Follow recognizes links reaching a source file through other symlinks
+	// (e.g. a link to a link to the source) as enabled and reports the final
+	// target of each chain in TargetState.Links
+	Follow bool
+
+	// Rename, when set, gives links a name derived from their source file
+	// (e.g. "foo.conf" for "foo.conf.disabled"). Enabled files are recognized
+	// by mapping the link names of the source files back.
+	Rename *RenamePattern
+
+	// LinkNames gives single links a custom name (source file -> link name,
+	// in the directory of the source file), taking precedence over Rename.
+	LinkNames map[string]string
+
+	// PreviousLinkNames are the custom link names of an earlier apply (e.g.
+	// from the undo record). Symlinks under them, or under the name a file
+	// gets without a custom name, are recognized by their target when
+	// LinkNames gives another name (see TargetState.Renamed). Other links
+	// into the source under another name are the user's own.
+	PreviousLinkNames map[string]string
+
+	// CreateTarget lets the target directory be missing: it reads as empty
+	// and ApplyChangesWithOptions creates it with its missing parents before
+	// the first change (never with DryRun)
+	CreateTarget bool
+
+	// SourceMode, when set, are the permission bits the selected source
+	// files get before they are linked (see ChangeSet.Chmod)
+	SourceMode os.FileMode
+
+	// Warnf is called for non-fatal problems, e.g. links skipped while
+	// reading the target (optional)
+	Warnf func(format string, args ...any)
+}
+
+// DefaultMaxUpLevels is the number of ".." components a relative symlink
+// target may have with the auto style when Options.MaxUpLevels is zero
+const DefaultMaxUpLevels = 5
+
+// maxUpLevels returns the effective limit of ".." components (negative = none)
+func (o Options) maxUpLevels() int {
+	switch {
+	case o.MaxUpLevels != 0:
+		return o.MaxUpLevels
+	case o.Style == LinkStyleRelative:
+		return -1
+	default:
+		return DefaultMaxUpLevels
+	}
+}
+
+// warn reports a non-fatal problem via Warnf
+func (o Options) warn(format string, args ...any) {
+	if o.Warnf != nil {
+		o.Warnf(format, args...)
+	}
+}
+
+// ValidatePatterns checks that all patterns are valid filepath.Match patterns
+func ValidatePatterns(patterns []string) error {
+	for _, pattern := range patterns {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// matchesAny reports whether the base name of name matches any pattern
+// Patterns must have been checked with ValidatePatterns
+func matchesAny(patterns []string, name string) bool {
+	base := filepath.Base(name)
+	for _, pattern := range patterns {
+		if ok, _ := filepath.Match(pattern, base); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// isHidden reports whether any component of the relative path name starts
+// with a dot
+func isHidden(name string) bool {
+	for _, part := range splitPath(filepath.Clean(name)) {
+		if strings.HasPrefix(part, ".") {
+			return true
+		}
+	}
+	return false
+}
+
+// managed reports whether a source file name is selected by the filters
+func (o Options) managed(name string) bool {
+	if !o.Hidden && isHidden(name) {
+		return false
+	}
+	if len(o.Include) > 0 && !matchesAny(o.Include, name) {
+		return false
+	}
+	return !matchesAny(o.Exclude, name)
+}
+
+// resolveLinkTarget returns the path a symlink's target refers to, as seen
+// from this process. Relative targets are resolved against linkDir (the
+// directory containing the symlink) and targets below LinkPrefix are mapped
+// back into sourceDir.
+func (o Options) resolveLinkTarget(sourceDir, linkDir, target string, dirs dirCache) string {
+	if o.LinkPrefix != "" {
+		prefix := filepath.Clean(o.LinkPrefix) + string(filepath.Separator)
+		if rest, ok := strings.CutPrefix(filepath.Clean(target), prefix); ok {
+			return filepath.Join(sourceDir, rest)
+		}
+	}
+
+	if filepath.IsAbs(target) {
+		return target
+	}
+	// The system resolves relative targets from the real directory of the
+	// link, which differs from linkDir when that is reached through a symlink
+	return filepath.Join(dirs.realPath(linkDir), target)
+}
+
+// realPath returns the absolute path of path with all symlinks resolved, or
+// the cleaned path itself if it cannot be resolved
+func realPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	if real, err := filepath.EvalSymlinks(path); err == nil {
+		return real
+	}
+	return filepath.Clean(path)
+}
+
+// evalRealPath returns the absolute path of path with all symlinks
+// resolved, like realPath, but fails if path cannot be resolved. Results
+// compare with resolveLinkTarget's, which are absolute as well.
+func evalRealPath(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	return filepath.EvalSymlinks(abs)
+}
+
+// dirCache memoizes realPath for the directories of a target scan
+// A nil dirCache resolves every path again.
+type dirCache map[string]string
+
+// realPath returns realPath(path), resolving each path only once
+func (c dirCache) realPath(path string) string {
+	if c == nil {
+		return realPath(path)
+	}
+	real, ok := c[path]
+	if !ok {
+		real = realPath(path)
+		c[path] = real
+	}
+	return real
+}
+
+// sameLocation reports whether the paths a and b name the same directory
+// entry once symlinks in their parent directories are resolved
+func (c dirCache) sameLocation(a, b string) bool {
+	if filepath.Base(a) != filepath.Base(b) {
+		return false
+	}
+	return c.realPath(filepath.Dir(a)) == c.realPath(filepath.Dir(b))
+}
+
+// pointsTo reports whether the resolved link target path denotes expected,
+// an absolute source path
+func (c dirCache) pointsTo(path, expected string) bool {
+	abs, err := filepath.Abs(path)
+	return err == nil && (abs == expected || c.sameLocation(abs, expected))
+}
+
+// sameLocation is dirCache.sameLocation without caching
+func sameLocation(a, b string) bool {
+	return dirCache(nil).sameLocation(a, b)
+}
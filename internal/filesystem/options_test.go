@@ -0,0 +1,220 @@
+package filesystem
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestCreateSymlinkWithOptions_LinkPrefix tests that a configured prefix is
+// used verbatim as the symlink target
+func TestCreateSymlinkWithOptions_LinkPrefix(t *testing.T) {
+	sourceDir, targetDir := setupSourceTarget(t, "app.conf")
+	opts := Options{LinkPrefix: "/mnt/container/available"}
+
+	if err := CreateSymlinkWithOptions(sourceDir, targetDir, "app.conf", opts); err != nil {
+		t.Fatalf("CreateSymlinkWithOptions failed: %v", err)
+	}
+
+	target, err := os.Readlink(filepath.Join(targetDir, "app.conf"))
+	if err != nil {
+		t.Fatalf("Failed to read symlink: %v", err)
+	}
+
+	expected := filepath.Join("/mnt/container/available", "app.conf")
+	if target != expected {
+		t.Errorf("Symlink target = %q, want %q", target, expected)
+	}
+}
+
+// TestGetEnabledFilesWithOptions_LinkPrefix tests that prefixed symlinks are
+// recognized as enabled and not reported as orphaned
+func TestGetEnabledFilesWithOptions_LinkPrefix(t *testing.T) {
+	sourceDir, targetDir := setupSourceTarget(t, "app.conf", "db.conf")
+	opts := Options{LinkPrefix: "/mnt/container/available"}
+
+	// Prefixed link, a regular relative link and a prefixed link to a missing file
+	if err := CreateSymlinkWithOptions(sourceDir, targetDir, "app.conf", opts); err != nil {
+		t.Fatalf("CreateSymlinkWithOptions failed: %v", err)
+	}
+	if err := CreateSymlink(sourceDir, targetDir, "db.conf"); err != nil {
+		t.Fatalf("CreateSymlink failed: %v", err)
+	}
+	if err := os.Symlink("/mnt/container/available/gone.conf", filepath.Join(targetDir, "gone.conf")); err != nil {
+		t.Fatalf("Failed to create broken prefixed symlink: %v", err)
+	}
+
+	enabled, err := GetEnabledFilesWithOptions(sourceDir, targetDir, opts)
+	if err != nil {
+		t.Fatalf("GetEnabledFilesWithOptions failed: %v", err)
+	}
+	enabledMap := make(map[string]bool)
+	for _, name := range enabled {
+		enabledMap[name] = true
+	}
+	if !enabledMap["app.conf"] || !enabledMap["db.conf"] {
+		t.Errorf("Expected app.conf and db.conf to be enabled, got %v", enabled)
+	}
+
+	// Without the option the prefixed link is not recognized
+	enabled, err = GetEnabledFiles(sourceDir, targetDir)
+	if err != nil {
+		t.Fatalf("GetEnabledFiles failed: %v", err)
+	}
+	if !reflect.DeepEqual(enabled, []string{"db.conf"}) {
+		t.Errorf("Expected only db.conf without prefix option, got %v", enabled)
+	}
+
+	// Prefixed links are checked against the source directory
+	orphaned, err := ValidateSymlinksWithOptions(sourceDir, targetDir, opts)
+	if err != nil {
+		t.Fatalf("ValidateSymlinksWithOptions failed: %v", err)
+	}
+	if !reflect.DeepEqual(orphaned, []string{"gone.conf"}) {
+		t.Errorf("Expected only gone.conf to be orphaned, got %v", orphaned)
+	}
+}
+
+// TestListAvailableFilesWithOptions_Exclude tests excluding source files by glob
+func TestListAvailableFilesWithOptions_Exclude(t *testing.T) {
+	sourceDir, _ := setupSourceTarget(t, "app.conf", "app.conf.bak", ".gitkeep", "README.md", "web.conf")
+
+	tests := []struct {
+		name    string
+		exclude []string
+		want    []string
+	}{
+		{"no patterns", nil, []string{".gitkeep", "README.md", "app.conf", "app.conf.bak", "web.conf"}},
+		{"backup and meta files", []string{"*.bak", ".git*", "README.md"}, []string{"app.conf", "web.conf"}},
+		{"match everything", []string{"*"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ListAvailableFilesWithOptions(sourceDir, Options{Exclude: tt.exclude, Hidden: true})
+			if err != nil {
+				t.Fatalf("ListAvailableFilesWithOptions failed: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ListAvailableFilesWithOptions() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestListAvailableFilesWithOptions_Hidden tests skipping dotfiles unless
+// Hidden is set, at the top level and in recursive mode
+func TestListAvailableFilesWithOptions_Hidden(t *testing.T) {
+	sourceDir, targetDir := setupSourceTarget(t, ".keep", "app.conf", filepath.Join(".git", "config"), filepath.Join("sub", ".env"), filepath.Join("sub", "web.conf"))
+
+	tests := []struct {
+		name string
+		opts Options
+		want []string
+	}{
+		{"default", Options{}, []string{"app.conf"}},
+		{"hidden", Options{Hidden: true}, []string{".keep", "app.conf"}},
+		{"recursive", Options{Recursive: true}, []string{"app.conf", filepath.Join("sub", "web.conf")}},
+		{"recursive hidden", Options{Recursive: true, Hidden: true}, []string{filepath.Join(".git", "config"), ".keep", "app.conf", filepath.Join("sub", ".env"), filepath.Join("sub", "web.conf")}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ListAvailableFilesWithOptions(sourceDir, tt.opts)
+			if err != nil {
+				t.Fatalf("ListAvailableFilesWithOptions failed: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ListAvailableFilesWithOptions() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// An existing link to a hidden file is neither removed nor orphaned
+	if err := CreateSymlink(sourceDir, targetDir, ".keep"); err != nil {
+		t.Fatalf("CreateSymlink failed: %v", err)
+	}
+	if _, err := ApplyChangesWithOptions(sourceDir, targetDir, []string{"app.conf"}, ApplyOptions{}); err != nil {
+		t.Fatalf("ApplyChangesWithOptions failed: %v", err)
+	}
+	if _, err := os.Lstat(filepath.Join(targetDir, ".keep")); err != nil {
+		t.Errorf("link to hidden file was removed: %v", err)
+	}
+	if orphaned, err := ValidateSymlinks(sourceDir, targetDir); err != nil || len(orphaned) != 0 {
+		t.Errorf("ValidateSymlinks() = %v, %v, want no orphans", orphaned, err)
+	}
+	enabled, err := GetEnabledFilesWithOptions(sourceDir, targetDir, Options{Hidden: true})
+	if err != nil || !reflect.DeepEqual(enabled, []string{".keep", "app.conf"}) {
+		t.Errorf("enabled with Hidden = %v, %v, want [.keep app.conf]", enabled, err)
+	}
+}
+
+// TestListAvailableFilesWithOptions_InvalidPattern tests that a malformed
+// pattern is reported clearly
+func TestListAvailableFilesWithOptions_InvalidPattern(t *testing.T) {
+	sourceDir, _ := setupSourceTarget(t, "app.conf")
+
+	_, err := ListAvailableFilesWithOptions(sourceDir, Options{Exclude: []string{"[a-"}})
+	if err == nil || !strings.Contains(err.Error(), `invalid pattern "[a-"`) {
+		t.Errorf("Expected invalid pattern error, got %v", err)
+	}
+}
+
+// TestGetEnabledFilesWithOptions_Exclude tests that links to excluded files
+// are not managed, so applying a selection never removes them
+func TestGetEnabledFilesWithOptions_Exclude(t *testing.T) {
+	sourceDir, targetDir := setupSourceTarget(t, "app.conf", "app.conf.bak")
+
+	for _, f := range []string{"app.conf", "app.conf.bak"} {
+		if err := CreateSymlink(sourceDir, targetDir, f); err != nil {
+			t.Fatalf("Failed to create symlink: %v", err)
+		}
+	}
+
+	opts := ApplyOptions{Options: Options{Exclude: []string{"*.bak"}}}
+	if _, err := ApplyChangesWithOptions(sourceDir, targetDir, []string{}, opts); err != nil {
+		t.Fatalf("ApplyChangesWithOptions failed: %v", err)
+	}
+
+	if _, err := os.Lstat(filepath.Join(targetDir, "app.conf.bak")); err != nil {
+		t.Error("link to excluded file should have been left alone")
+	}
+	if _, err := os.Lstat(filepath.Join(targetDir, "app.conf")); !os.IsNotExist(err) {
+		t.Error("app.conf link should have been removed")
+	}
+}
+
+// TestListAvailableFilesWithOptions_Include tests restricting source files by
+// include patterns, combined with exclude patterns
+func TestListAvailableFilesWithOptions_Include(t *testing.T) {
+	sourceDir, _ := setupSourceTarget(t, "app.conf", "old.conf", "run.sh", "a.yml", "b.yaml", "data1.csv")
+
+	tests := []struct {
+		name    string
+		include []string
+		exclude []string
+		want    []string
+	}{
+		{"no includes", nil, nil, []string{"a.yml", "app.conf", "b.yaml", "data1.csv", "old.conf", "run.sh"}},
+		{"single include", []string{"*.conf"}, nil, []string{"app.conf", "old.conf"}},
+		{"include then exclude", []string{"*.conf", "*.sh"}, []string{"old*"}, []string{"app.conf", "run.sh"}},
+		{"character class", []string{"data[0-9].csv", "*.y[a]ml"}, nil, []string{"b.yaml", "data1.csv"}},
+		// filepath.Match has no brace alternatives, the pattern matches nothing
+		{"braces unsupported", []string{"*.{yml,yaml}"}, nil, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := Options{Include: tt.include, Exclude: tt.exclude}
+			got, err := ListAvailableFilesWithOptions(sourceDir, opts)
+			if err != nil {
+				t.Fatalf("ListAvailableFilesWithOptions failed: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ListAvailableFilesWithOptions() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
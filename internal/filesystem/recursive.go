@@ -0,0 +1,119 @@
+package filesystem
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// listAvailableFilesRecursive lists all files below the source directory as
+// paths relative to it (e.g. "apps/foo.conf"). Symlinked directories are not followed.
+func listAvailableFilesRecursive(dir string, opts Options) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == dir {
+				return fmt.Errorf("failed to read source directory: %w", err)
+			}
+			// Skip unreadable subdirectories
+			return nil
+		}
+
+		if d.IsDir() {
+			// Nothing below a hidden directory is managed
+			if path != dir && !opts.Hidden && isHidden(d.Name()) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		name, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		if opts.managed(name) {
+			files = append(files, name)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return files, nil
+}
+
+// listSymlinksRecursive returns all symlinks below the target directory,
+// mapping their path relative to targetDir to their link target.
+// Symlinked directories below targetDir are not followed.
+// Hidden directories (e.g. .git) are skipped unless opts.Hidden is set.
+func listSymlinksRecursive(targetDir string, opts Options) (map[string]string, error) {
+	// WalkDir does not descend into a root that is itself a symlink
+	root := targetDir
+	if real, err := filepath.EvalSymlinks(targetDir); err == nil {
+		root = real
+	}
+
+	symlinks := make(map[string]string)
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == root {
+				return fmt.Errorf("failed to read target directory: %w", err)
+			}
+			// Skip unreadable subdirectories
+			return nil
+		}
+
+		if d.IsDir() && path != root && !opts.Hidden && isHidden(d.Name()) {
+			return filepath.SkipDir
+		}
+		if d.Type()&os.ModeSymlink == 0 {
+			return nil
+		}
+
+		target, err := os.Readlink(path)
+		if err != nil {
+			return nil
+		}
+
+		name, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		symlinks[name] = target
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return symlinks, nil
+}
+
+// ensureTargetDir creates the directory dir (relative to targetDir) and its
+// parents. A non-directory in the way is reported as a name collision.
+func ensureTargetDir(targetDir, dir string) error {
+	// Walk down from the top so the colliding path component can be named
+	current := targetDir
+	for _, part := range splitPath(dir) {
+		current = filepath.Join(current, part)
+		info, err := os.Stat(current)
+		if err == nil && !info.IsDir() {
+			rel, _ := filepath.Rel(targetDir, current)
+			return fmt.Errorf("cannot create directory %s: a non-directory with that name exists in the target", rel)
+		}
+	}
+
+	return os.MkdirAll(filepath.Join(targetDir, dir), 0755)
+}
+
+// splitPath splits a relative path into its components
+func splitPath(path string) []string {
+	var parts []string
+	for path != "." && path != string(filepath.Separator) && path != "" {
+		parts = append([]string{filepath.Base(path)}, parts...)
+		path = filepath.Dir(path)
+	}
+	return parts
+}
@@ -0,0 +1,113 @@
+package filesystem
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// TestListAvailableFilesWithOptions_Recursive tests listing nested source files
+func TestListAvailableFilesWithOptions_Recursive(t *testing.T) {
+	sourceDir, targetDir := setupSourceTarget(t, "top.conf", "apps/foo.conf", "apps/web/bar.conf", "timers/backup.timer", ".git/config")
+
+	got, err := ListAvailableFilesWithOptions(sourceDir, Options{Recursive: true, Exclude: []string{"*.timer"}})
+	if err != nil {
+		t.Fatalf("ListAvailableFilesWithOptions failed: %v", err)
+	}
+
+	want := []string{
+		filepath.Join("apps", "foo.conf"),
+		filepath.Join("apps", "web", "bar.conf"),
+		"top.conf",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ListAvailableFilesWithOptions() = %v, want %v", got, want)
+	}
+
+	// Links below hidden target directories are not walked into either
+	gitLink := filepath.Join(".git", "hooks", "top.conf")
+	if err := os.MkdirAll(filepath.Join(targetDir, ".git", "hooks"), 0755); err != nil {
+		t.Fatalf("Failed to create hidden target dir: %v", err)
+	}
+	if err := os.Symlink(filepath.Join(sourceDir, "top.conf"), filepath.Join(targetDir, gitLink)); err != nil {
+		t.Fatalf("Failed to create symlink: %v", err)
+	}
+	for _, hidden := range []bool{false, true} {
+		links, err := listSymlinksRecursive(targetDir, Options{Hidden: hidden})
+		if err != nil {
+			t.Fatalf("listSymlinksRecursive failed: %v", err)
+		}
+		if _, ok := links[gitLink]; ok != hidden {
+			t.Errorf("listSymlinksRecursive(hidden = %v) = %v, want %s listed only with hidden", hidden, links, gitLink)
+		}
+	}
+
+	got, err = ListAvailableFilesWithOptions(sourceDir, Options{Recursive: true, Hidden: true})
+	if err != nil {
+		t.Fatalf("ListAvailableFilesWithOptions failed: %v", err)
+	}
+	if !slices.Contains(got, filepath.Join(".git", "config")) {
+		t.Errorf("ListAvailableFilesWithOptions(hidden) = %v, want .git/config listed", got)
+	}
+}
+
+// TestApplyChangesWithOptions_RecursiveSource tests linking and unlinking
+// nested source files, creating and pruning target subdirectories
+func TestApplyChangesWithOptions_RecursiveSource(t *testing.T) {
+	sourceDir, targetDir := setupSourceTarget(t, "top.conf", "apps/foo.conf", "apps/web/bar.conf")
+	nested := filepath.Join("apps", "web", "bar.conf")
+
+	opts := ApplyOptions{Options: Options{Recursive: true}, PruneEmptyDirs: true}
+	if _, err := ApplyChangesWithOptions(sourceDir, targetDir, []string{"top.conf", nested}, opts); err != nil {
+		t.Fatalf("ApplyChangesWithOptions failed: %v", err)
+	}
+
+	// The nested link is relative to its own directory and resolves to the source
+	content, err := os.ReadFile(filepath.Join(targetDir, nested))
+	if err != nil {
+		t.Fatalf("nested symlink does not resolve: %v", err)
+	}
+	if string(content) != "test" {
+		t.Errorf("nested symlink content = %q", content)
+	}
+	link, _ := os.Readlink(filepath.Join(targetDir, nested))
+	if filepath.IsAbs(link) {
+		t.Errorf("nested symlink target = %s, want relative", link)
+	}
+
+	enabled, err := GetEnabledFilesWithOptions(sourceDir, targetDir, opts.Options)
+	if err != nil {
+		t.Fatalf("GetEnabledFilesWithOptions failed: %v", err)
+	}
+	sort.Strings(enabled)
+	if !reflect.DeepEqual(enabled, []string{nested, "top.conf"}) {
+		t.Errorf("enabled = %v, want nested and top-level links", enabled)
+	}
+
+	// Deselecting the nested file removes the link and the empty directories
+	if _, err := ApplyChangesWithOptions(sourceDir, targetDir, []string{"top.conf"}, opts); err != nil {
+		t.Fatalf("ApplyChangesWithOptions failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(targetDir, "apps")); !os.IsNotExist(err) {
+		t.Error("empty apps directory should have been pruned")
+	}
+}
+
+// TestCreateSymlink_DirectoryNameCollision tests that a target file named
+// like a source subdirectory produces a clear error
+func TestCreateSymlink_DirectoryNameCollision(t *testing.T) {
+	sourceDir, targetDir := setupSourceTarget(t, "apps/foo.conf")
+
+	if err := os.WriteFile(filepath.Join(targetDir, "apps"), []byte("file"), 0644); err != nil {
+		t.Fatalf("Failed to create colliding file: %v", err)
+	}
+
+	err := CreateSymlink(sourceDir, targetDir, filepath.Join("apps", "foo.conf"))
+	if err == nil || !strings.Contains(err.Error(), "cannot create directory apps") {
+		t.Errorf("Expected directory collision error, got %v", err)
+	}
+}
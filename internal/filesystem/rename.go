@@ -0,0 +1,226 @@
+package filesystem
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// RenamePattern maps source file names to different link names with a sed
+// style substitution like s/\.disabled$// (see ParseRenamePattern)
+type RenamePattern struct {
+	spec        string
+	re          *regexp.Regexp
+	replacement string // Replacement in regexp.Expand syntax
+	global      bool   // Replace all matches instead of the first
+}
+
+// ParseRenamePattern parses a substitution s<d>regex<d>replacement<d>[g]
+// where <d> is any delimiter character (usually "/"). The regex uses Go
+// syntax; \1 to \9 in the replacement insert capture groups and & the whole
+// match, as in sed.
+func ParseRenamePattern(spec string) (*RenamePattern, error) {
+	invalid := func(reason string) error {
+		return fmt.Errorf("invalid rename pattern %q: %s", spec, reason)
+	}
+
+	if len(spec) < 2 || spec[0] != 's' {
+		return nil, invalid("expected s/regex/replacement/")
+	}
+	delim := spec[1:2]
+	if delim == `\` || delim == "\n" {
+		return nil, invalid("backslash and newline cannot be delimiters")
+	}
+
+	parts := splitUnescaped(spec[2:], delim[0])
+	if len(parts) != 3 {
+		return nil, invalid("expected s/regex/replacement/")
+	}
+	if parts[2] != "" && parts[2] != "g" {
+		return nil, invalid(fmt.Sprintf("unknown flags %q (only g is supported)", parts[2]))
+	}
+
+	re, err := regexp.Compile(parts[0])
+	if err != nil {
+		return nil, invalid(err.Error())
+	}
+	return &RenamePattern{
+		spec:        spec,
+		re:          re,
+		replacement: sedReplacement(parts[1]),
+		global:      parts[2] == "g",
+	}, nil
+}
+
+// splitUnescaped splits s at each delim not preceded by a backslash.
+// Escaped delimiters lose their backslash, other escapes are kept.
+func splitUnescaped(s string, delim byte) []string {
+	var parts []string
+	var part strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\\' && i+1 < len(s) && s[i+1] == delim:
+			part.WriteByte(delim)
+			i++
+		case s[i] == '\\' && i+1 < len(s):
+			part.WriteString(s[i : i+2])
+			i++
+		case s[i] == delim:
+			parts = append(parts, part.String())
+			part.Reset()
+		default:
+			part.WriteByte(s[i])
+		}
+	}
+	return append(parts, part.String())
+}
+
+// sedReplacement converts a sed replacement to regexp.Expand syntax
+func sedReplacement(s string) string {
+	var out strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '\\' && i+1 < len(s) && s[i+1] >= '0' && s[i+1] <= '9':
+			out.WriteString("${" + s[i+1:i+2] + "}")
+			i++
+		case c == '\\' && i+1 < len(s):
+			// Escaped characters are literal (e.g. \& or \\)
+			if s[i+1] == '$' {
+				out.WriteString("$$")
+			} else {
+				out.WriteByte(s[i+1])
+			}
+			i++
+		case c == '&':
+			out.WriteString("${0}")
+		case c == '$':
+			out.WriteString("$$")
+		default:
+			out.WriteByte(c)
+		}
+	}
+	return out.String()
+}
+
+// String returns the pattern as given to ParseRenamePattern
+func (p *RenamePattern) String() string {
+	return p.spec
+}
+
+// MarshalText stores the pattern as given (e.g. in the undo journal)
+func (p *RenamePattern) MarshalText() ([]byte, error) {
+	return []byte(p.spec), nil
+}
+
+// UnmarshalText parses a pattern stored with MarshalText
+func (p *RenamePattern) UnmarshalText(text []byte) error {
+	parsed, err := ParseRenamePattern(string(text))
+	if err != nil {
+		return err
+	}
+	*p = *parsed
+	return nil
+}
+
+// Apply returns the link name of a source file. Only the base name is
+// renamed, so links stay in the directory matching their source.
+func (p *RenamePattern) Apply(name string) string {
+	dir, base := filepath.Split(name)
+	if p.global {
+		return dir + p.re.ReplaceAllString(base, p.replacement)
+	}
+
+	match := p.re.FindStringSubmatchIndex(base)
+	if match == nil {
+		return name
+	}
+	replaced := p.re.ExpandString(nil, p.replacement, base, match)
+	return dir + base[:match[0]] + string(replaced) + base[match[1]:]
+}
+
+// linkName returns the name of the link to the source file name in the
+// target directory
+func (o Options) linkName(name string) string {
+	if link, ok := o.LinkNames[name]; ok {
+		return link
+	}
+	if o.Rename == nil {
+		return name
+	}
+	return o.Rename.Apply(name)
+}
+
+// LinkName returns the name of the link to the source file name in the
+// target directory, relative to it
+func (o Options) LinkName(name string) string {
+	return o.linkName(name)
+}
+
+// sourcesByLink maps the link name of each available source file back to
+// the file, the identity without renames
+func (o Options) sourcesByLink(sourceDir string) (map[string]string, error) {
+	available, err := ListAvailableFilesWithOptions(sourceDir, o)
+	if err != nil {
+		return nil, err
+	}
+	return o.linkNames(available)
+}
+
+// ownsLink reports whether lnka may have linked the source file under the
+// link name: the name it gets without a custom name or the one of an
+// earlier apply
+func (o Options) ownsLink(name, link string) bool {
+	if previous, ok := o.PreviousLinkNames[name]; ok && previous == link {
+		return true
+	}
+	if o.Rename != nil {
+		return o.Rename.Apply(name) == link
+	}
+	return name == link
+}
+
+// renamedSource returns the available source file a link resolving to
+// resolved leads to, "" if it leads elsewhere. Used to recognize links
+// created under another name than linkName gives (see ownsLink).
+func (o Options) renamedSource(absSource, resolved string) string {
+	abs, err := filepath.Abs(resolved)
+	if err != nil || abs == absSource || !isInside(absSource, abs) {
+		return ""
+	}
+	name, err := filepath.Rel(absSource, abs)
+	if err != nil || (!o.Recursive && strings.ContainsRune(name, filepath.Separator)) || !o.managed(name) {
+		return ""
+	}
+	info, err := os.Stat(abs)
+	if err != nil || (info.IsDir() && !o.Dirs) {
+		return ""
+	}
+	return name
+}
+
+// linkNames maps the link name of each available source file back to the
+// source file. Returns an error if a file is renamed to an empty name or two
+// files would get the same link name.
+func (o Options) linkNames(available []string) (map[string]string, error) {
+	sources := make(map[string]string, len(available))
+	for _, name := range available {
+		link := o.linkName(name)
+		_, custom := o.LinkNames[name]
+		if _, base := filepath.Split(link); base == "" {
+			if custom {
+				return nil, fmt.Errorf("link name of %s is empty", name)
+			}
+			return nil, fmt.Errorf("rename pattern %s maps %s to an empty name", o.Rename, name)
+		}
+		if other, ok := sources[link]; ok {
+			if _, otherCustom := o.LinkNames[other]; custom || otherCustom || o.Rename == nil {
+				return nil, fmt.Errorf("both %s and %s would be linked as %s", other, name, link)
+			}
+			return nil, fmt.Errorf("rename pattern %s maps both %s and %s to %s", o.Rename, other, name, link)
+		}
+		sources[link] = name
+	}
+	return sources, nil
+}
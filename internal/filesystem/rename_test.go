@@ -0,0 +1,213 @@
+package filesystem
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseRenamePattern(t *testing.T) {
+	tests := []struct {
+		spec    string
+		name    string
+		want    string
+		wantErr bool
+	}{
+		{spec: `s/\.disabled$//`, name: "foo.conf.disabled", want: "foo.conf"},
+		{spec: `s/\.disabled$//`, name: "bar.conf", want: "bar.conf"},
+		{spec: `s/^[0-9]+-(.*)\.disabled$/\1/`, name: "10-foo.conf.disabled", want: "foo.conf"},
+		{spec: `s/o/0/`, name: "foo", want: "f0o"},
+		{spec: `s/o/0/g`, name: "foo", want: "f00"},
+		{spec: `s|^|pre-&|`, name: "apps/foo", want: "apps/pre-foo"}, // Only the base name is renamed
+		{spec: `s/a/\//`, name: "a", want: "/"},
+		{spec: `s/x/$1/`, name: "x", want: "$1"},
+		{spec: `\.disabled$`, wantErr: true},
+		{spec: `s/(/x/`, wantErr: true},
+		{spec: `s/a/b`, wantErr: true},
+		{spec: `s/a/b/i`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			pattern, err := ParseRenamePattern(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseRenamePattern(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got := pattern.Apply(tt.name); got != tt.want {
+				t.Errorf("Apply(%q) = %q, want %q", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestApplyChanges_Rename tests creating, recognizing and removing links
+// under a name without the source's suffix
+func TestApplyChanges_Rename(t *testing.T) {
+	sourceDir, targetDir := setupSourceTarget(t, "foo.conf.disabled", "bar.conf.disabled")
+	pattern, err := ParseRenamePattern(`s/\.disabled$//`)
+	if err != nil {
+		t.Fatalf("ParseRenamePattern failed: %v", err)
+	}
+	opts := ApplyOptions{Options: Options{Rename: pattern}}
+
+	if _, err := ApplyChangesWithOptions(sourceDir, targetDir, []string{"foo.conf.disabled"}, opts); err != nil {
+		t.Fatalf("ApplyChangesWithOptions failed: %v", err)
+	}
+	target, err := os.Readlink(filepath.Join(targetDir, "foo.conf"))
+	if err != nil {
+		t.Fatalf("link foo.conf not created: %v", err)
+	}
+	if filepath.Base(target) != "foo.conf.disabled" {
+		t.Errorf("link target = %q, want the original source file", target)
+	}
+
+	enabled, err := GetEnabledFilesWithOptions(sourceDir, targetDir, opts.Options)
+	if err != nil {
+		t.Fatalf("GetEnabledFilesWithOptions failed: %v", err)
+	}
+	if want := []string{"foo.conf.disabled"}; !reflect.DeepEqual(enabled, want) {
+		t.Errorf("GetEnabledFilesWithOptions() = %v, want %v", enabled, want)
+	}
+
+	// Switching the selection removes the renamed link
+	if _, err := ApplyChangesWithOptions(sourceDir, targetDir, []string{"bar.conf.disabled"}, opts); err != nil {
+		t.Fatalf("ApplyChangesWithOptions failed: %v", err)
+	}
+	if _, err := os.Lstat(filepath.Join(targetDir, "foo.conf")); !os.IsNotExist(err) {
+		t.Errorf("foo.conf should be removed, got %v", err)
+	}
+	if _, err := os.Lstat(filepath.Join(targetDir, "bar.conf")); err != nil {
+		t.Errorf("bar.conf should be linked: %v", err)
+	}
+}
+
+// TestApplyChanges_RenameCollision tests that two sources renamed to the same
+// link name are refused
+func TestApplyChanges_RenameCollision(t *testing.T) {
+	sourceDir, targetDir := setupSourceTarget(t, "foo.conf", "foo.conf.disabled")
+	pattern, err := ParseRenamePattern(`s/\.disabled$//`)
+	if err != nil {
+		t.Fatalf("ParseRenamePattern failed: %v", err)
+	}
+
+	_, err = ApplyChangesWithOptions(sourceDir, targetDir, []string{"foo.conf"}, ApplyOptions{Options: Options{Rename: pattern}})
+	if err == nil || !strings.Contains(err.Error(), "maps both foo.conf and foo.conf.disabled to foo.conf") {
+		t.Errorf("ApplyChangesWithOptions() error = %v, want collision error", err)
+	}
+	if entries, _ := os.ReadDir(targetDir); len(entries) != 0 {
+		t.Errorf("target should stay empty, got %d entries", len(entries))
+	}
+}
+
+// TestApplyChanges_LinkNames tests custom link names and recognizing the
+// links of earlier applies under them
+func TestApplyChanges_LinkNames(t *testing.T) {
+	sourceDir, targetDir := setupSourceTarget(t, "app.conf", "other.conf")
+	apply := func(selected []string, linkNames, previous map[string]string) *ChangeResult {
+		t.Helper()
+		opts := Options{LinkNames: linkNames, PreviousLinkNames: previous}
+		result, err := ApplyChangesWithOptions(sourceDir, targetDir, selected, ApplyOptions{Options: opts})
+		if err != nil {
+			t.Fatalf("ApplyChangesWithOptions failed: %v", err)
+		}
+		return result
+	}
+	exists := func(name string) bool {
+		_, err := os.Lstat(filepath.Join(targetDir, name))
+		return err == nil
+	}
+
+	recorded := map[string]string{"app.conf": "custom.conf"}
+	result := apply([]string{"app.conf"}, recorded, nil)
+	if !reflect.DeepEqual(result.Created, []string{"app.conf"}) || !exists("custom.conf") || exists("app.conf") {
+		t.Fatalf("created %v, want app.conf linked as custom.conf", result.Created)
+	}
+
+	// Without the recorded name the link is an alias of the user, neither
+	// managed nor stale
+	state, err := ReadTargetState(sourceDir, targetDir, Options{})
+	if err != nil {
+		t.Fatalf("ReadTargetState failed: %v", err)
+	}
+	if len(state.Enabled) != 0 {
+		t.Errorf("Enabled = %v, want none", state.Enabled)
+	}
+	if stale, err := FindStaleSymlinks(sourceDir, targetDir); err != nil || len(stale) != 0 {
+		t.Errorf("FindStaleSymlinks() = %v, %v, want none", stale, err)
+	}
+
+	// With it, the link is recognized by its target
+	state, err = ReadTargetState(sourceDir, targetDir, Options{PreviousLinkNames: recorded})
+	if err != nil {
+		t.Fatalf("ReadTargetState failed: %v", err)
+	}
+	if want := []string{"app.conf"}; !reflect.DeepEqual(state.Enabled, want) {
+		t.Errorf("Enabled = %v, want %v", state.Enabled, want)
+	}
+	if !reflect.DeepEqual(state.Renamed, recorded) {
+		t.Errorf("Renamed = %v, want %v", state.Renamed, recorded)
+	}
+
+	// Keeping the custom name keeps the link
+	result = apply([]string{"app.conf", "other.conf"}, recorded, recorded)
+	if !reflect.DeepEqual(result.Unchanged, []string{"app.conf"}) || !exists("custom.conf") {
+		t.Errorf("unchanged %v, want app.conf kept as custom.conf", result.Unchanged)
+	}
+
+	// The removable allowlist keeps the current link
+	renamed := map[string]string{"app.conf": "new.conf"}
+	result, err = ApplyChangesWithOptions(sourceDir, targetDir, []string{"app.conf", "other.conf"}, ApplyOptions{
+		Options:            Options{LinkNames: renamed, PreviousLinkNames: recorded},
+		RemovableAllowlist: map[string]bool{},
+	})
+	if err != nil {
+		t.Fatalf("ApplyChangesWithOptions failed: %v", err)
+	}
+	if len(result.Renamed) != 0 || !reflect.DeepEqual(result.Refused, []string{"app.conf"}) || !exists("custom.conf") || exists("new.conf") {
+		t.Errorf("renamed %v, refused %v, want the link custom.conf kept", result.Renamed, result.Refused)
+	}
+
+	// A new name relinks the file
+	result = apply([]string{"app.conf", "other.conf"}, renamed, recorded)
+	if !reflect.DeepEqual(result.Renamed, []string{"app.conf"}) || !exists("new.conf") || exists("custom.conf") {
+		t.Errorf("renamed %v, want app.conf relinked as new.conf", result.Renamed)
+	}
+
+	// The renamed link is removed when deselected
+	result = apply([]string{"other.conf"}, renamed, renamed)
+	if !reflect.DeepEqual(result.Removed, []string{"app.conf"}) || exists("new.conf") {
+		t.Errorf("removed %v, want the link new.conf removed", result.Removed)
+	}
+
+	// A custom name may not take the link name of another file
+	_, err = ApplyChangesWithOptions(sourceDir, targetDir, []string{"app.conf"}, ApplyOptions{Options: Options{LinkNames: map[string]string{"app.conf": "other.conf"}}})
+	if err == nil || !strings.Contains(err.Error(), "would be linked as other.conf") {
+		t.Errorf("ApplyChangesWithOptions() error = %v, want collision error", err)
+	}
+}
+
+// TestRenamePattern_JSON tests storing a pattern in the undo journal
+func TestRenamePattern_JSON(t *testing.T) {
+	pattern, err := ParseRenamePattern(`s/\.disabled$//`)
+	if err != nil {
+		t.Fatalf("ParseRenamePattern failed: %v", err)
+	}
+
+	data, err := json.Marshal(UndoRecord{Rename: pattern})
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	var record UndoRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if record.Rename == nil || record.Rename.String() != pattern.String() {
+		t.Errorf("Rename = %v, want %s", record.Rename, pattern)
+	}
+}
@@ -0,0 +1,121 @@
+package filesystem
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// rollback collects how to undo the operations of ApplyChangesWithOptions, so
+// a failed apply can restore the target to its state before the apply
+type rollback struct {
+	undo []func() error
+}
+
+// record captures the current state of the target entry for name before it
+// is changed. Must be called right before the operation.
+func (r *rollback) record(sourceDir, targetDir, name string, opts Options) {
+	r.undo = append(r.undo, restorePoint(filepath.Join(sourceDir, name), filepath.Join(targetDir, opts.linkName(name)), opts.Mode))
+}
+
+// recordBackup records moving the new link at linkPath out of the way and the
+// backup made for it back into place
+func (r *rollback) recordBackup(backupPath, linkPath string) {
+	r.undo = append(r.undo, func() error {
+		if err := os.Remove(linkPath); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return os.Rename(backupPath, linkPath)
+	})
+}
+
+// recordMode captures the permission bits of the file at path before they
+// are changed
+func (r *rollback) recordMode(path string) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return
+	}
+	mode := info.Mode().Perm()
+	r.undo = append(r.undo, func() error {
+		return os.Chmod(path, mode)
+	})
+}
+
+// run undoes the recorded operations in reverse order
+// All of them are attempted; their errors are returned joined.
+func (r *rollback) run() error {
+	var errs []error
+	for i := len(r.undo) - 1; i >= 0; i-- {
+		if err := r.undo[i](); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	r.undo = nil
+	return errors.Join(errs...)
+}
+
+// restorePoint returns a function restoring linkPath to its current state:
+// a missing entry is removed again, a symlink is recreated with its old
+// target, a copy or hard link matching sourcePath is made again and an
+// outdated copy is written back. Other entries (e.g. unrelated regular files)
+// cannot be restored and are left as they are then.
+func restorePoint(sourcePath, linkPath string, mode LinkMode) func() error {
+	info, err := os.Lstat(linkPath)
+	switch {
+	case os.IsNotExist(err):
+		return func() error {
+			if err := os.Remove(linkPath); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("failed to remove %s: %w", linkPath, err)
+			}
+			return nil
+		}
+	case err != nil:
+		return func() error { return nil }
+	case info.Mode()&os.ModeSymlink != 0:
+		target, err := os.Readlink(linkPath)
+		if err != nil {
+			return func() error { return fmt.Errorf("failed to restore symlink %s: %w", linkPath, err) }
+		}
+		return func() error {
+			// Removals may have pruned the parent directory
+			if err := os.MkdirAll(filepath.Dir(linkPath), 0755); err != nil {
+				return fmt.Errorf("failed to restore symlink %s: %w", linkPath, err)
+			}
+			if err := replaceSymlink(target, linkPath); err != nil {
+				return fmt.Errorf("failed to restore symlink %s: %w", linkPath, err)
+			}
+			return nil
+		}
+	case mode.usesFiles() && isFileLinked(sourcePath, linkPath, mode):
+		return func() error {
+			if err := os.MkdirAll(filepath.Dir(linkPath), 0755); err != nil {
+				return fmt.Errorf("failed to restore %s: %w", linkPath, err)
+			}
+			if err := os.Remove(linkPath); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("failed to restore %s: %w", linkPath, err)
+			}
+			if err := createFileLink(sourcePath, linkPath, mode); err != nil {
+				return fmt.Errorf("failed to restore %s: %w", linkPath, err)
+			}
+			return nil
+		}
+	case mode == LinkModeCopy && info.Mode().IsRegular():
+		data, err := os.ReadFile(linkPath)
+		if err != nil {
+			return func() error { return fmt.Errorf("failed to restore %s: %w", linkPath, err) }
+		}
+		return func() error {
+			if err := os.MkdirAll(filepath.Dir(linkPath), 0755); err != nil {
+				return fmt.Errorf("failed to restore %s: %w", linkPath, err)
+			}
+			if err := os.WriteFile(linkPath, data, info.Mode().Perm()); err != nil {
+				return fmt.Errorf("failed to restore %s: %w", linkPath, err)
+			}
+			return os.Chtimes(linkPath, info.ModTime(), info.ModTime())
+		}
+	default:
+		return func() error { return nil }
+	}
+}
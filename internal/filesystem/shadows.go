@@ -0,0 +1,90 @@
+package filesystem
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// FindShadowFiles finds regular files in the target directory that have the
+// same name as a file in the source directory. Such files shadow the source
+// file and can be replaced with a proper symlink.
+func FindShadowFiles(sourceDir, targetDir string) ([]string, error) {
+	return FindShadowFilesWithOptions(sourceDir, targetDir, Options{})
+}
+
+// FindShadowFilesWithOptions finds shadow files like FindShadowFiles at the
+// link names the given options give the source files. Returns the source
+// file names. In copy and hardlink mode regular files are the links, so
+// there are none.
+func FindShadowFilesWithOptions(sourceDir, targetDir string, opts Options) ([]string, error) {
+	if opts.Mode.usesFiles() {
+		return nil, nil
+	}
+
+	available, err := ListAvailableFilesWithOptions(sourceDir, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var shadows []string
+	for _, name := range available {
+		info, err := os.Lstat(filepath.Join(targetDir, opts.linkName(name)))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to check %s: %w", name, err)
+		}
+
+		if info.Mode().IsRegular() {
+			shadows = append(shadows, name)
+		}
+	}
+
+	return shadows, nil
+}
+
+// ReplaceShadowFiles moves each shadow file (given by its source file name)
+// aside to a backup like Options.Backup does and creates a symlink to the
+// source file in its place
+func ReplaceShadowFiles(sourceDir, targetDir string, shadows []string, opts Options) error {
+	for _, name := range shadows {
+		shadowPath := filepath.Join(targetDir, opts.linkName(name))
+		backupPath, err := backupFile(shadowPath)
+		if err != nil {
+			return fmt.Errorf("failed to back up %s: %w", name, err)
+		}
+
+		if err := CreateSymlinkWithOptions(sourceDir, targetDir, name, opts); err != nil {
+			// Put the original file back so nothing is lost
+			restoreBackup(backupPath, shadowPath)
+			return fmt.Errorf("failed to replace shadow file %s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// DetectConflicts returns the link names of the selected files for which the
+// target directory already holds something other than a symlink, which
+// creating the link would replace
+func DetectConflicts(sourceDir, targetDir string, selected []string, opts Options) ([]string, error) {
+	var conflicts []string
+	for _, name := range selected {
+		link := opts.linkName(name)
+		info, err := os.Lstat(filepath.Join(targetDir, link))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to check %s: %w", name, err)
+		}
+
+		if info.Mode()&os.ModeSymlink == 0 {
+			conflicts = append(conflicts, link)
+		}
+	}
+
+	return conflicts, nil
+}
@@ -0,0 +1,112 @@
+package filesystem
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// TestFindShadowFiles tests detection of regular files shadowing source files
+func TestFindShadowFiles(t *testing.T) {
+	sourceDir, targetDir := setupSourceTarget(t, "linked.conf", "shadow.conf", "absent.conf")
+
+	if err := CreateSymlink(sourceDir, targetDir, "linked.conf"); err != nil {
+		t.Fatalf("Failed to create symlink: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(targetDir, "shadow.conf"), []byte("local"), 0644); err != nil {
+		t.Fatalf("Failed to create shadow file: %v", err)
+	}
+	// Regular files without a source counterpart are not shadows
+	if err := os.WriteFile(filepath.Join(targetDir, "unrelated.conf"), []byte("local"), 0644); err != nil {
+		t.Fatalf("Failed to create unrelated file: %v", err)
+	}
+
+	shadows, err := FindShadowFiles(sourceDir, targetDir)
+	if err != nil {
+		t.Fatalf("FindShadowFiles failed: %v", err)
+	}
+	if !reflect.DeepEqual(shadows, []string{"shadow.conf"}) {
+		t.Errorf("FindShadowFiles() = %v, want [shadow.conf]", shadows)
+	}
+}
+
+// TestReplaceShadowFiles tests that shadow files are backed up and replaced by symlinks
+func TestReplaceShadowFiles(t *testing.T) {
+	sourceDir, targetDir := setupSourceTarget(t, "shadow.conf")
+
+	shadowPath := filepath.Join(targetDir, "shadow.conf")
+	if err := os.WriteFile(shadowPath, []byte("local"), 0644); err != nil {
+		t.Fatalf("Failed to create shadow file: %v", err)
+	}
+
+	if err := ReplaceShadowFiles(sourceDir, targetDir, []string{"shadow.conf"}, Options{}); err != nil {
+		t.Fatalf("ReplaceShadowFiles failed: %v", err)
+	}
+
+	info, err := os.Lstat(shadowPath)
+	if err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Error("shadow.conf should have been replaced by a symlink")
+	}
+
+	backup, err := os.ReadFile(shadowPath + BackupSuffix)
+	if err != nil || string(backup) != "local" {
+		t.Errorf("backup should keep the original content, got %q (%v)", backup, err)
+	}
+
+	// A second shadow gets the next backup name like Options.Backup
+	if err := os.Remove(shadowPath); err != nil {
+		t.Fatalf("Failed to remove symlink: %v", err)
+	}
+	if err := os.WriteFile(shadowPath, []byte("again"), 0644); err != nil {
+		t.Fatalf("Failed to create shadow file: %v", err)
+	}
+	if err := ReplaceShadowFiles(sourceDir, targetDir, []string{"shadow.conf"}, Options{}); err != nil {
+		t.Fatalf("ReplaceShadowFiles failed: %v", err)
+	}
+	if content, _ := os.ReadFile(shadowPath + BackupSuffix); string(content) != "local" {
+		t.Error("the existing backup should be left untouched")
+	}
+	if content, _ := os.ReadFile(shadowPath + BackupSuffix + ".1"); string(content) != "again" {
+		t.Error("the second shadow file should be kept as shadow.conf.bak.1")
+	}
+}
+
+// TestDetectConflicts tests detection of non-symlinks in place of selected links
+func TestDetectConflicts(t *testing.T) {
+	sourceDir, targetDir := setupSourceTarget(t, "linked.conf", "file.conf", "dir.conf", "absent.conf")
+
+	if err := CreateSymlink(sourceDir, targetDir, "linked.conf"); err != nil {
+		t.Fatalf("Failed to create symlink: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(targetDir, "file.conf"), []byte("local"), 0644); err != nil {
+		t.Fatalf("Failed to create regular file: %v", err)
+	}
+	if err := os.Mkdir(filepath.Join(targetDir, "dir.conf"), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+
+	got, err := DetectConflicts(sourceDir, targetDir, []string{"linked.conf", "file.conf", "dir.conf", "absent.conf"}, Options{})
+	if err != nil {
+		t.Fatalf("DetectConflicts failed: %v", err)
+	}
+	if want := []string{"file.conf", "dir.conf"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("DetectConflicts() = %v, want %v", got, want)
+	}
+
+	// Renamed links are checked at their link name
+	if err := os.WriteFile(filepath.Join(targetDir, "10-absent.conf"), []byte("local"), 0644); err != nil {
+		t.Fatalf("Failed to create regular file: %v", err)
+	}
+	rename, err := ParseRenamePattern("s/^/10-/")
+	if err != nil {
+		t.Fatalf("ParseRenamePattern failed: %v", err)
+	}
+	got, err = DetectConflicts(sourceDir, targetDir, []string{"absent.conf"}, Options{Rename: rename})
+	if err != nil {
+		t.Fatalf("DetectConflicts failed: %v", err)
+	}
+	if want := []string{"10-absent.conf"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("DetectConflicts(renamed) = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,157 @@
+package filesystem
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// TargetState describes the links of a target directory
+type TargetState struct {
+	Enabled  []string          // Source files linked into the target (sorted; source names with Rename)
+	Broken   []string          // Source names of links pointing to their source file that no longer exists (sorted, not in Enabled)
+	Orphaned []string          // Symlinks whose target does not exist (sorted)
+	Links    map[string]string // All symlinks of the target mapped to their link targets (final targets with Follow)
+	Renamed  map[string]string // Enabled files whose symlink has another name than Options gives them (see Options.PreviousLinkNames), mapped to that name
+	Outdated []string          // Enabled files whose copy no longer matches the source file (sorted, only in copy mode)
+}
+
+// ReadTargetState reads the target directory once and detects both the
+// enabled files and the orphaned symlinks, instead of scanning it for each
+// like GetEnabledFilesWithOptions and ValidateSymlinksWithOptions
+func ReadTargetState(sourceDir, targetDir string, opts Options) (*TargetState, error) {
+	return scanTarget(sourceDir, targetDir, opts, scanEnabled|scanOrphaned)
+}
+
+// scanParts selects what scanTarget detects
+type scanParts int
+
+const (
+	scanEnabled scanParts = 1 << iota
+	scanOrphaned
+)
+
+// scanTarget lists the symlinks of the target and resolves each link once
+// for the requested parts of the state
+func scanTarget(sourceDir, targetDir string, opts Options, parts scanParts) (*TargetState, error) {
+	symlinks, err := listSymlinks(sourceDir, targetDir, opts)
+	if err != nil {
+		return nil, err
+	}
+	state := &TargetState{Links: symlinks}
+
+	// Copies and hard links are regular files matching their source
+	fileLinks := opts.Mode.usesFiles()
+	if parts&scanEnabled != 0 && fileLinks {
+		state.Enabled, state.Outdated, err = enabledFileLinks(sourceDir, targetDir, opts)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	absSource, err := filepath.Abs(sourceDir)
+	if err != nil {
+		absSource = sourceDir
+	}
+
+	// Links are matched to their source file by link name
+	var sources map[string]string
+	if (opts.Rename != nil || len(opts.LinkNames) > 0) && parts&scanEnabled != 0 && !fileLinks {
+		available, err := ListAvailableFilesWithOptions(sourceDir, opts)
+		if err != nil {
+			return nil, err
+		}
+		if sources, err = opts.linkNames(available); err != nil {
+			return nil, err
+		}
+	}
+
+	// Links in the same directory share the resolution of its real path
+	dirs := dirCache{}
+	enabled := make(map[string]bool)
+	renamed := make(map[string]string) // Link name -> source file
+	for name, target := range symlinks {
+		// Resolve the target path (could be relative, absolute or prefixed)
+		// against the directory containing the link
+		linkDir := filepath.Join(targetDir, filepath.Dir(name))
+		resolved := opts.resolveLinkTarget(sourceDir, linkDir, target, dirs)
+
+		sourceName := name
+		if sources != nil {
+			sourceName = sources[name]
+		}
+
+		// A link under another name is matched to its source by its target
+		if parts&scanEnabled != 0 && !fileLinks && (sourceName == "" || !dirs.pointsTo(resolved, filepath.Join(absSource, sourceName))) {
+			if other := opts.renamedSource(absSource, resolved); other != "" && opts.ownsLink(other, name) {
+				renamed[name] = other
+				continue
+			}
+		}
+
+		// Links to filtered-out files are left alone
+		if parts&scanEnabled != 0 && !fileLinks && sourceName != "" && opts.managed(sourceName) {
+			expected := filepath.Join(absSource, sourceName)
+			linked := dirs.pointsTo(resolved, expected)
+
+			// Follow the chain if the link leads to the source through others
+			if opts.Follow {
+				chain, err := followChain(filepath.Join(targetDir, name))
+				if err != nil {
+					// A cycle or an unreadable link only costs this link
+					opts.warn("skipping %s: %v", name, err)
+					continue
+				}
+				for _, hop := range chain {
+					linked = linked || dirs.pointsTo(hop, expected)
+				}
+				if len(chain) > 0 {
+					state.Links[name] = chain[len(chain)-1]
+				}
+			}
+
+			// The link path matches, but only an existing file is enabled
+			// (resolved maps prefixed links to the source file)
+			if linked {
+				if _, err := os.Stat(resolved); os.IsNotExist(err) {
+					state.Broken = append(state.Broken, sourceName)
+				} else {
+					state.Enabled = append(state.Enabled, sourceName)
+					enabled[sourceName] = true
+				}
+			}
+		}
+
+		if parts&scanOrphaned != 0 {
+			if _, err := os.Stat(resolved); os.IsNotExist(err) {
+				state.Orphaned = append(state.Orphaned, name)
+			}
+		}
+	}
+
+	// Files linked under their own name as well keep that link, several
+	// renamed links to one file are settled by link name
+	linkNames := make([]string, 0, len(renamed))
+	for name := range renamed {
+		linkNames = append(linkNames, name)
+	}
+	sort.Strings(linkNames)
+	for _, name := range linkNames {
+		sourceName := renamed[name]
+		if enabled[sourceName] {
+			continue
+		}
+		if state.Renamed == nil {
+			state.Renamed = make(map[string]string)
+		}
+		state.Enabled = append(state.Enabled, sourceName)
+		state.Renamed[sourceName] = name
+		enabled[sourceName] = true
+	}
+
+	// Map iteration order is random, report in path order
+	sort.Strings(state.Enabled)
+	sort.Strings(state.Broken)
+	sort.Strings(state.Orphaned)
+	return state, nil
+}
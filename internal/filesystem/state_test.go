@@ -0,0 +1,150 @@
+package filesystem
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// TestReadTargetState tests detecting enabled files and orphans in one scan
+func TestReadTargetState(t *testing.T) {
+	sourceDir, targetDir := setupSourceTarget(t, "a.conf", "b.conf", "c.conf")
+
+	for _, f := range []string{"b.conf", "a.conf"} {
+		if err := CreateSymlink(sourceDir, targetDir, f); err != nil {
+			t.Fatalf("Failed to create symlink: %v", err)
+		}
+	}
+	if err := os.Symlink(filepath.Join("..", "removed", "gone.conf"), filepath.Join(targetDir, "gone.conf")); err != nil {
+		t.Fatalf("Failed to create broken symlink: %v", err)
+	}
+
+	state, err := ReadTargetState(sourceDir, targetDir, Options{})
+	if err != nil {
+		t.Fatalf("ReadTargetState failed: %v", err)
+	}
+
+	if want := []string{"a.conf", "b.conf"}; !reflect.DeepEqual(state.Enabled, want) {
+		t.Errorf("Enabled = %v, want %v", state.Enabled, want)
+	}
+	if want := []string{"gone.conf"}; !reflect.DeepEqual(state.Orphaned, want) {
+		t.Errorf("Orphaned = %v, want %v", state.Orphaned, want)
+	}
+	if len(state.Links) != 3 || state.Links["a.conf"] != filepath.Join("..", "source", "a.conf") {
+		t.Errorf("Links = %v, want all three symlinks", state.Links)
+	}
+
+	// Same results as the separate scans
+	enabled, err := GetEnabledFiles(sourceDir, targetDir)
+	if err != nil || !reflect.DeepEqual(enabled, state.Enabled) {
+		t.Errorf("GetEnabledFiles() = %v, %v, want %v", enabled, err, state.Enabled)
+	}
+	orphaned, err := ValidateSymlinks(sourceDir, targetDir)
+	if err != nil || !reflect.DeepEqual(orphaned, state.Orphaned) {
+		t.Errorf("ValidateSymlinks() = %v, %v, want %v", orphaned, err, state.Orphaned)
+	}
+}
+
+// TestReadTargetState_BrokenMatchingLink tests that a broken link whose text
+// matches the source path is reported as broken instead of enabled, and that
+// applying a selection leaves it to the orphan cleanup
+func TestReadTargetState_BrokenMatchingLink(t *testing.T) {
+	sourceDir, targetDir := setupSourceTarget(t, "a.conf")
+	for _, name := range []string{"a.conf", "gone.conf"} {
+		if err := os.Symlink(filepath.Join("..", "source", name), filepath.Join(targetDir, name)); err != nil {
+			t.Fatalf("Failed to create symlink: %v", err)
+		}
+	}
+
+	state, err := ReadTargetState(sourceDir, targetDir, Options{})
+	if err != nil {
+		t.Fatalf("ReadTargetState failed: %v", err)
+	}
+	if want := []string{"a.conf"}; !reflect.DeepEqual(state.Enabled, want) {
+		t.Errorf("Enabled = %v, want %v", state.Enabled, want)
+	}
+	if want := []string{"gone.conf"}; !reflect.DeepEqual(state.Broken, want) {
+		t.Errorf("Broken = %v, want %v", state.Broken, want)
+	}
+	enabled, err := GetEnabledFiles(sourceDir, targetDir)
+	if err != nil || !reflect.DeepEqual(enabled, []string{"a.conf"}) {
+		t.Errorf("GetEnabledFiles() = %v, %v, want [a.conf]", enabled, err)
+	}
+
+	// Selected, there is no file to link
+	changes, err := PlanChanges(sourceDir, targetDir, []string{"a.conf", "gone.conf"}, Options{})
+	if err != nil {
+		t.Fatalf("PlanChanges failed: %v", err)
+	}
+	if changes.HasChanges() {
+		t.Errorf("PlanChanges(selected) = %+v, want no changes", changes)
+	}
+
+	// Not selected, the broken link is kept for the orphan cleanup
+	result, err := ApplyChanges(sourceDir, targetDir, []string{"a.conf"})
+	if err != nil {
+		t.Fatalf("ApplyChanges failed: %v", err)
+	}
+	if len(result.Removed) != 0 || len(result.Created) != 0 {
+		t.Errorf("ApplyChanges() = %+v, want no changes", result)
+	}
+	if _, err := os.Lstat(filepath.Join(targetDir, "gone.conf")); err != nil {
+		t.Errorf("broken link should be kept, Lstat err = %v", err)
+	}
+}
+
+// setupLinkedTarget creates a source with n files, all linked into the target
+func setupLinkedTarget(b *testing.B, n int) (string, string) {
+	b.Helper()
+
+	tempDir := b.TempDir()
+	sourceDir := filepath.Join(tempDir, "source")
+	targetDir := filepath.Join(tempDir, "target")
+	for _, dir := range []string{sourceDir, targetDir} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			b.Fatalf("Failed to create dir: %v", err)
+		}
+	}
+
+	for i := 0; i < n; i++ {
+		name := fmt.Sprintf("file%04d.conf", i)
+		if err := os.WriteFile(filepath.Join(sourceDir, name), nil, 0644); err != nil {
+			b.Fatalf("Failed to create source file: %v", err)
+		}
+		if err := os.Symlink(filepath.Join("..", "source", name), filepath.Join(targetDir, name)); err != nil {
+			b.Fatalf("Failed to create symlink: %v", err)
+		}
+	}
+	return sourceDir, targetDir
+}
+
+// BenchmarkSeparateScans measures reading the target state with one scan
+// for the enabled files and another one for the orphans
+func BenchmarkSeparateScans(b *testing.B) {
+	sourceDir, targetDir := setupLinkedTarget(b, 2000)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := GetEnabledFiles(sourceDir, targetDir); err != nil {
+			b.Fatal(err)
+		}
+		if _, err := ValidateSymlinks(sourceDir, targetDir); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkReadTargetState measures reading the target state with a single
+// scan (one ReadDir and one resolution of the target directory)
+func BenchmarkReadTargetState(b *testing.B) {
+	sourceDir, targetDir := setupLinkedTarget(b, 2000)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := ReadTargetState(sourceDir, targetDir, Options{}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
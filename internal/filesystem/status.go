@@ -0,0 +1,118 @@
+package filesystem
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// FileStatus is the state of a source file, or of a broken symlink of the
+// target without a source file, as reported by the status command
+type FileStatus struct {
+	Name       string    `json:"name"`
+	Enabled    bool      `json:"enabled"`        // Linked into the target (under its own or another name)
+	Broken     bool      `json:"broken"`         // The symlink of the same name points to nothing
+	Mismatched bool      `json:"mismatched"`     // The symlink of the same name points to an existing file outside the source
+	Target     string    `json:"target"`         // Current symlink target (empty = no symlink)
+	Size       int64     `json:"size"`           // Size of the source file (0 without one)
+	ModTime    time.Time `json:"mtime,omitzero"` // Modification time of the source file (omitted without one)
+}
+
+// CollectStatus lists every available file of the source with its link state
+// and stat information, followed by the broken symlinks of the target
+// without a source file (sorted). Source and target are read once each,
+// instead of combining ListAvailableFiles, GetEnabledFiles,
+// ValidateSymlinks and FindMismatchedSymlinks. Links are recognized with
+// the given options (e.g. those of the last apply).
+func CollectStatus(sourceDir, targetDir string, opts Options) ([]FileStatus, error) {
+	entries, err := os.ReadDir(sourceDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read source directory: %w", err)
+	}
+	links, err := ListEnabledSymlinks(sourceDir, targetDir)
+	if err != nil {
+		return nil, err
+	}
+
+	// Compare real paths so symlinked parent directories don't cause mismatches
+	realSource, err := evalRealPath(sourceDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve source directory: %w", err)
+	}
+	absSource, err := filepath.Abs(sourceDir)
+	if err != nil {
+		absSource = sourceDir
+	}
+
+	statuses := make([]FileStatus, 0, len(entries))
+	index := make(map[string]int, len(entries)) // File name -> position in statuses
+	for _, entry := range entries {
+		if (entry.IsDir() && !opts.Dirs) || !opts.managed(entry.Name()) {
+			continue
+		}
+		status := FileStatus{Name: entry.Name()}
+		if info, err := entry.Info(); err == nil {
+			status.Size = info.Size()
+			status.ModTime = info.ModTime()
+		}
+		index[status.Name] = len(statuses)
+		statuses = append(statuses, status)
+	}
+
+	names := make([]string, 0, len(links))
+	for name := range links {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	dirs := dirCache{}
+	var orphaned []FileStatus
+	renamed := make(map[string]string) // Source file -> first link under another name
+	for _, name := range names {
+		target := links[name]
+		resolved := opts.resolveLinkTarget(sourceDir, targetDir, target, dirs)
+		_, err := os.Stat(resolved)
+		broken := os.IsNotExist(err)
+
+		if i, ok := index[name]; ok {
+			status := &statuses[i]
+			status.Target = target
+			status.Broken = broken
+			if !broken {
+				real, err := evalRealPath(resolved)
+				status.Mismatched = err == nil && !isInside(realSource, real)
+				if dirs.pointsTo(resolved, filepath.Join(absSource, name)) {
+					status.Enabled = true
+					continue
+				}
+			}
+		} else if broken {
+			orphaned = append(orphaned, FileStatus{Name: name, Broken: true, Target: target})
+			continue
+		}
+
+		// A link under another name enables the source file it points to
+		if other := opts.renamedSource(absSource, resolved); other != "" && opts.ownsLink(other, name) {
+			if _, ok := renamed[other]; !ok {
+				renamed[other] = name
+			}
+		}
+	}
+
+	// Files linked under their own name as well keep that link
+	for other, name := range renamed {
+		i, ok := index[other]
+		if !ok || statuses[i].Enabled {
+			continue
+		}
+		status := &statuses[i]
+		status.Enabled = true
+		if _, ok := links[other]; !ok {
+			status.Target = links[name]
+		}
+	}
+
+	return append(statuses, orphaned...), nil
+}
@@ -0,0 +1,90 @@
+package filesystem
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// TestCollectStatus tests the state of linked, unlinked, mismatched and
+// broken files in one pass
+func TestCollectStatus(t *testing.T) {
+	sourceDir, targetDir := setupSourceTarget(t, "linked.conf", "unlinked.conf", "renamed.conf", "moved.conf", "missing.conf")
+	oldDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(oldDir, "moved.conf"), []byte("old"), 0644); err != nil {
+		t.Fatalf("Failed to create old file: %v", err)
+	}
+
+	links := map[string]string{
+		"linked.conf":  filepath.Join(sourceDir, "linked.conf"),
+		"custom.conf":  filepath.Join(sourceDir, "renamed.conf"), // an alias of the user
+		"moved.conf":   filepath.Join(oldDir, "moved.conf"),      // outside the source
+		"missing.conf": filepath.Join(oldDir, "missing.conf"),    // broken, with a source file
+		"gone.conf":    filepath.Join(sourceDir, "gone.conf"),    // broken, without a source file
+	}
+	for name, target := range links {
+		if err := os.Symlink(target, filepath.Join(targetDir, name)); err != nil {
+			t.Fatalf("Failed to create symlink %s: %v", name, err)
+		}
+	}
+
+	got, err := CollectStatus(sourceDir, targetDir, Options{})
+	if err != nil {
+		t.Fatalf("CollectStatus failed: %v", err)
+	}
+
+	// Only source files have stat information
+	for i := range got {
+		if hasSource := got[i].Name != "gone.conf"; hasSource != (got[i].Size == int64(len("test")) && !got[i].ModTime.IsZero()) {
+			t.Errorf("%s: size = %d, mtime = %v, want the stat of its source file", got[i].Name, got[i].Size, got[i].ModTime)
+		}
+		got[i].Size, got[i].ModTime = 0, time.Time{}
+	}
+	want := []FileStatus{
+		{Name: "linked.conf", Enabled: true, Target: links["linked.conf"]},
+		{Name: "missing.conf", Broken: true, Target: links["missing.conf"]},
+		{Name: "moved.conf", Mismatched: true, Target: links["moved.conf"]},
+		{Name: "renamed.conf"},
+		{Name: "unlinked.conf"},
+		{Name: "gone.conf", Broken: true, Target: links["gone.conf"]},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CollectStatus() = %+v, want %+v", got, want)
+	}
+
+	if _, err := CollectStatus(filepath.Join(sourceDir, "missing"), targetDir, Options{}); err == nil {
+		t.Error("CollectStatus() with a missing source directory should fail")
+	}
+}
+
+// TestCollectStatus_Options tests recognizing links created with a rename
+// pattern as enabled only when the pattern is given
+func TestCollectStatus_Options(t *testing.T) {
+	sourceDir, targetDir := setupSourceTarget(t, "a.conf")
+	rename, err := ParseRenamePattern("s/^/10-/")
+	if err != nil {
+		t.Fatalf("ParseRenamePattern failed: %v", err)
+	}
+	opts := Options{Rename: rename}
+	if err := CreateSymlinkWithOptions(sourceDir, targetDir, "a.conf", opts); err != nil {
+		t.Fatalf("CreateSymlinkWithOptions failed: %v", err)
+	}
+
+	for _, tt := range []struct {
+		opts    Options
+		enabled bool
+	}{
+		{Options{}, false},
+		{opts, true},
+	} {
+		got, err := CollectStatus(sourceDir, targetDir, tt.opts)
+		if err != nil {
+			t.Fatalf("CollectStatus failed: %v", err)
+		}
+		if len(got) != 1 || got[0].Name != "a.conf" || got[0].Enabled != tt.enabled {
+			t.Errorf("CollectStatus(rename = %v) = %+v, want a.conf with enabled = %v", tt.opts.Rename != nil, got, tt.enabled)
+		}
+	}
+}
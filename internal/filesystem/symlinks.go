@@ -1,14 +1,36 @@
 package filesystem
 
 import (
+	"errors"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
 )
 
 // ListAvailableFiles lists all files (not directories) in the source directory
 func ListAvailableFiles(dir string) ([]string, error) {
+	return ListAvailableFilesWithOptions(dir, Options{})
+}
+
+// ListAvailableFilesWithOptions lists the files in the source directory like
+// ListAvailableFiles, keeping only files selected by the include and exclude
+// filters. With opts.Dirs, directories are listed as well.
+func ListAvailableFilesWithOptions(dir string, opts Options) ([]string, error) {
+	if err := ValidatePatterns(opts.Include); err != nil {
+		return nil, fmt.Errorf("invalid include filter: %w", err)
+	}
+	if err := ValidatePatterns(opts.Exclude); err != nil {
+		return nil, fmt.Errorf("invalid exclude filter: %w", err)
+	}
+
+	if opts.Recursive {
+		return listAvailableFilesRecursive(dir, opts)
+	}
+
 	entries, err := os.ReadDir(dir)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read source directory: %w", err)
@@ -16,8 +38,8 @@ func ListAvailableFiles(dir string) ([]string, error) {
 
 	var files []string
 	for _, entry := range entries {
-		// Only include regular files, skip directories
-		if !entry.IsDir() {
+		// Only include regular files, skip directories unless linked as a whole
+		if (!entry.IsDir() || opts.Dirs) && opts.managed(entry.Name()) {
 			files = append(files, entry.Name())
 		}
 	}
@@ -57,95 +79,200 @@ func ListEnabledSymlinks(sourceDir string, targetDir string) (map[string]string,
 }
 
 // GetEnabledFiles returns a list of file names that are currently enabled
-// (have symlinks pointing to them in the target directory)
+// (have symlinks pointing to them in the target directory). Broken links are
+// not enabled, even if their target names the source file; ReadTargetState
+// reports them as Broken.
 func GetEnabledFiles(sourceDir string, targetDir string) ([]string, error) {
-	symlinks, err := ListEnabledSymlinks(sourceDir, targetDir)
+	return GetEnabledFilesWithOptions(sourceDir, targetDir, Options{})
+}
+
+// GetEnabledFilesWithOptions returns the currently enabled files like
+// GetEnabledFiles, recognizing symlinks created with the given options
+func GetEnabledFilesWithOptions(sourceDir string, targetDir string, opts Options) ([]string, error) {
+	state, err := scanTarget(sourceDir, targetDir, opts, scanEnabled)
 	if err != nil {
 		return nil, err
 	}
+	return state.Enabled, nil
+}
 
-	enabled := make([]string, 0, len(symlinks))
-	for name, target := range symlinks {
-		// Resolve the target path (could be relative or absolute)
-		var resolvedTarget string
-		if filepath.IsAbs(target) {
-			resolvedTarget = target
-		} else {
-			resolvedTarget = filepath.Join(targetDir, target)
-		}
-
-		// Check if the resolved target points to a file in sourceDir
-		expectedPath := filepath.Join(sourceDir, name)
-
-		// Compare resolved paths
-		resolvedTargetAbs, err1 := filepath.Abs(resolvedTarget)
-		expectedPathAbs, err2 := filepath.Abs(expectedPath)
-
-		if err1 == nil && err2 == nil && resolvedTargetAbs == expectedPathAbs {
-			enabled = append(enabled, name)
+// listSymlinks returns the symlinks of the target directory, including those
+// in subdirectories in recursive mode (keyed by their relative path)
+func listSymlinks(sourceDir, targetDir string, opts Options) (map[string]string, error) {
+	if opts.CreateTarget {
+		if _, err := os.Lstat(targetDir); os.IsNotExist(err) {
+			return map[string]string{}, nil
 		}
 	}
-
-	return enabled, nil
+	if opts.Recursive {
+		return listSymlinksRecursive(targetDir, opts)
+	}
+	return ListEnabledSymlinks(sourceDir, targetDir)
 }
 
 // CreateSymlink creates a symlink in the target directory pointing to a file in the source directory
 // Uses relative paths when source and target are close together
 func CreateSymlink(sourceDir, targetDir, filename string) error {
+	return CreateSymlinkWithOptions(sourceDir, targetDir, filename, Options{})
+}
+
+// CreateSymlinkWithOptions creates a symlink like CreateSymlink, honoring the given options
+func CreateSymlinkWithOptions(sourceDir, targetDir, filename string, opts Options) error {
+	_, err := CreateSymlinkWithBackup(sourceDir, targetDir, filename, opts)
+	return err
+}
+
+// CreateSymlinkWithBackup creates a symlink like CreateSymlinkWithOptions.
+// With opts.Backup, a regular file in place of the link is moved to a backup
+// instead of being removed; its path is returned (empty = no backup made).
+// Anything else but a symlink (or an own copy) is only replaced with
+// opts.Overwrite.
+func CreateSymlinkWithBackup(sourceDir, targetDir, filename string, opts Options) (string, error) {
 	sourcePath := filepath.Join(sourceDir, filename)
-	linkPath := filepath.Join(targetDir, filename)
+	linkPath := filepath.Join(targetDir, opts.linkName(filename))
 
 	// Check if source file exists
 	if _, err := os.Stat(sourcePath); err != nil {
-		return fmt.Errorf("source file %s does not exist: %w", filename, err)
+		return "", fmt.Errorf("source file %s does not exist: %w", filename, err)
+	}
+
+	// Nested files need their parent directories in the target
+	if dir := filepath.Dir(filename); dir != "." {
+		if err := ensureTargetDir(targetDir, dir); err != nil {
+			return "", fmt.Errorf("failed to create symlink %s: %w", filename, err)
+		}
+	}
+
+	symlinkTarget, _, err := canonicalTarget(sourceDir, targetDir, filename, opts)
+	if err != nil {
+		return "", err
 	}
 
 	// Check if symlink already exists
-	if _, err := os.Lstat(linkPath); err == nil {
-		// Symlink exists, remove it first
-		if err := os.Remove(linkPath); err != nil {
-			return fmt.Errorf("failed to remove existing symlink %s: %w", filename, err)
+	backupPath := ""
+	if info, err := os.Lstat(linkPath); err == nil {
+		ownCopy := opts.Mode.usesFiles() && isFileLinked(sourcePath, linkPath, opts.Mode)
+		if info.Mode()&os.ModeSymlink == 0 && !ownCopy && !opts.Overwrite && !(opts.Backup && info.Mode().IsRegular()) {
+			return "", fmt.Errorf("refusing to replace %s: not a symlink (confirm the conflict or use a backup)", opts.linkName(filename))
+		}
+		if opts.Backup && info.Mode().IsRegular() && !ownCopy {
+			// Keep a regular file the user may still need
+			backupPath, err = backupFile(linkPath)
+			if err != nil {
+				return "", fmt.Errorf("failed to back up %s: %w", filename, err)
+			}
+		} else if opts.Mode.usesFiles() || info.IsDir() {
+			// Copies, hard links and directories cannot be renamed over, remove
+			// them first (symlinks are replaced atomically below)
+			if err := os.Remove(linkPath); err != nil {
+				return "", fmt.Errorf("failed to remove existing symlink %s: %w", filename, err)
+			}
 		}
 	}
 
+	// Copy or hard link instead of symlinking
+	if opts.Mode.usesFiles() {
+		if err := createFileLink(sourcePath, linkPath, opts.Mode); err != nil {
+			restoreBackup(backupPath, linkPath)
+			return "", fmt.Errorf("failed to create %s %s: %w", opts.Mode, filename, err)
+		}
+		return backupPath, nil
+	}
+
+	// Create the symlink
+	if err := replaceSymlink(symlinkTarget, linkPath); err != nil {
+		restoreBackup(backupPath, linkPath)
+		return "", fmt.Errorf("failed to create symlink %s: %w", filename, err)
+	}
+
+	return backupPath, nil
+}
+
+// replaceSymlink creates a symlink to target at linkPath, replacing whatever
+// is there in a single rename. The link is created under a temporary name
+// (.name.tmp-<pid>) in the same directory first, so linkPath never goes
+// missing, even if the process dies midway.
+func replaceSymlink(target, linkPath string) error {
+	tmpPath := filepath.Join(filepath.Dir(linkPath), fmt.Sprintf(".%s.tmp-%d", filepath.Base(linkPath), os.Getpid()))
+
+	// A leftover of an earlier run with the same pid would make Symlink fail
+	if info, err := os.Lstat(tmpPath); err == nil && info.Mode()&os.ModeSymlink != 0 {
+		_ = os.Remove(tmpPath)
+	}
+
+	if err := os.Symlink(target, tmpPath); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, linkPath); err != nil {
+		_ = os.Remove(tmpPath)
+		return err
+	}
+	return nil
+}
+
+// canonicalTarget returns the target CreateSymlink uses for a link to filename:
+// the link prefix path if configured, otherwise a path relative to the link's
+// directory or, with LinkStyleAbsolute, the absolute source path. If the
+// target directory is a symlink, the relative path starts at its real location.
+// A relative path falls back to the absolute one when it does not exist
+// or needs more levels up than allowed; fallback then tells why.
+func canonicalTarget(sourceDir, targetDir, filename string, opts Options) (target, fallback string, err error) {
+	// Use the configured prefix verbatim instead of computing a path
+	if opts.LinkPrefix != "" {
+		return filepath.Join(opts.LinkPrefix, filename), "", nil
+	}
+
 	// Convert both paths to absolute for reliable Rel calculation
-	absSourcePath, err := filepath.Abs(sourcePath)
+	absSourcePath, err := filepath.Abs(filepath.Join(sourceDir, filename))
 	if err != nil {
-		return fmt.Errorf("failed to get absolute source path: %w", err)
+		return "", "", fmt.Errorf("failed to get absolute source path: %w", err)
+	}
+	if opts.Style == LinkStyleAbsolute {
+		return absSourcePath, "", nil
 	}
 
-	absTargetDir, err := filepath.Abs(targetDir)
+	absLinkDir, err := filepath.Abs(filepath.Dir(filepath.Join(targetDir, opts.linkName(filename))))
 	if err != nil {
-		return fmt.Errorf("failed to get absolute target directory: %w", err)
+		return "", "", fmt.Errorf("failed to get absolute target directory: %w", err)
 	}
 
-	// Try to create a relative symlink if possible
-	symlinkTarget := absSourcePath
-	relPath, err := filepath.Rel(absTargetDir, absSourcePath)
-	if err == nil && !filepath.IsAbs(relPath) {
-		// Count how many levels up we need to go (count ".." components)
-		upLevels := 0
-		normalized := filepath.ToSlash(relPath)
-		parts := strings.Split(normalized, "/")
-		for _, part := range parts {
-			if part == ".." {
-				upLevels++
-			}
-		}
+	// Relative to the directory containing the link; absolute when no
+	// relative path exists (e.g. another volume on Windows)
+	relPath, err := filepath.Rel(absLinkDir, absSourcePath)
+	if err != nil || filepath.IsAbs(relPath) {
+		return absSourcePath, "no relative path from the target directory", nil
+	}
 
-		// Use relative path only if it's reasonably short (max 5 levels up)
-		// This avoids overly complex paths like ../../../../../../../../var/...
-		if upLevels <= 5 {
-			symlinkTarget = relPath
+	// Relative targets are followed from the real directory of the link; when
+	// the link directory is reached through a symlink, the path computed from
+	// its name may lead elsewhere
+	realLinkDir := realPath(absLinkDir)
+	if !sameLocation(filepath.Join(realLinkDir, relPath), absSourcePath) {
+		relPath, err = filepath.Rel(realLinkDir, absSourcePath)
+		if err != nil || filepath.IsAbs(relPath) {
+			return absSourcePath, "no relative path from the target directory", nil
 		}
 	}
 
-	// Create the symlink
-	if err := os.Symlink(symlinkTarget, linkPath); err != nil {
-		return fmt.Errorf("failed to create symlink %s: %w", filename, err)
+	// Deep relative paths like ../../../../source/x break as soon as part of
+	// the tree moves
+	if maxUp := opts.maxUpLevels(); maxUp >= 0 {
+		if upLevels := countUpLevels(relPath); upLevels > maxUp {
+			return absSourcePath, fmt.Sprintf("the relative path %s needs %d levels up (max %d)", relPath, upLevels, maxUp), nil
+		}
 	}
+	return relPath, "", nil
+}
 
-	return nil
+// countUpLevels counts the ".." components of a relative path
+func countUpLevels(relPath string) int {
+	upLevels := 0
+	for _, part := range strings.Split(filepath.ToSlash(relPath), "/") {
+		if part == ".." {
+			upLevels++
+		}
+	}
+	return upLevels
 }
 
 // RemoveSymlink removes a symlink from the target directory
@@ -178,31 +305,144 @@ func RemoveSymlink(targetDir, filename string) error {
 // ValidateSymlinks finds orphaned or broken symlinks in the target directory
 // Returns a list of symlink names that are broken (point to non-existent files)
 func ValidateSymlinks(sourceDir, targetDir string) ([]string, error) {
-	symlinks, err := ListEnabledSymlinks(sourceDir, targetDir)
+	return ValidateSymlinksWithOptions(sourceDir, targetDir, Options{})
+}
+
+// ValidateSymlinksRecursive finds broken symlinks in the target directory and
+// all of its subdirectories. Returned names are paths relative to targetDir
+// (e.g. "sub/dir/broken.conf"). Symlinked directories are not followed.
+func ValidateSymlinksRecursive(sourceDir, targetDir string) ([]string, error) {
+	return ValidateSymlinksWithOptions(sourceDir, targetDir, Options{Recursive: true})
+}
+
+// ValidateSymlinksWithOptions finds broken symlinks like ValidateSymlinks,
+// scanning subdirectories when opts.Recursive is set
+func ValidateSymlinksWithOptions(sourceDir, targetDir string, opts Options) ([]string, error) {
+	state, err := scanTarget(sourceDir, targetDir, opts, scanOrphaned)
+	if err != nil {
+		return nil, err
+	}
+	return state.Orphaned, nil
+}
+
+// FindStaleSymlinks finds symlinks in the target directory whose name has no
+// entry in the source directory anymore and that point into the source at a
+// file that is gone (e.g. after renaming a source file). Links elsewhere and
+// aliases of existing source files are the user's own and left alone.
+func FindStaleSymlinks(sourceDir, targetDir string) ([]string, error) {
+	return FindStaleSymlinksWithOptions(sourceDir, targetDir, Options{})
+}
+
+// FindStaleSymlinksWithOptions finds stale symlinks like FindStaleSymlinks,
+// comparing against the source listing for the given options. Names excluded
+// by the filters are left alone, and copies and hard links have no symlinks
+// to check.
+func FindStaleSymlinksWithOptions(sourceDir, targetDir string, opts Options) ([]string, error) {
+	if opts.Mode.usesFiles() {
+		return nil, nil
+	}
+
+	available, err := ListAvailableFilesWithOptions(sourceDir, opts)
+	if err != nil {
+		return nil, err
+	}
+	inSource := make(map[string]bool, len(available))
+	for _, name := range available {
+		inSource[opts.linkName(name)] = true
+	}
+
+	// Links to source files under another name are no stale links
+	state, err := scanTarget(sourceDir, targetDir, opts, scanEnabled)
+	if err != nil {
+		return nil, err
+	}
+	for _, link := range state.Renamed {
+		inSource[link] = true
+	}
+
+	symlinks, err := listSymlinks(sourceDir, targetDir, opts)
 	if err != nil {
 		return nil, err
 	}
 
-	var orphaned []string
+	absSource, err := filepath.Abs(sourceDir)
+	if err != nil {
+		absSource = sourceDir
+	}
+	dirs := dirCache{}
+	realSource := dirs.realPath(absSource)
+
+	var stale []string
 	for name, target := range symlinks {
-		// Resolve target path relative to target directory if it's a relative path
-		targetPath := target
-		if !filepath.IsAbs(target) {
-			targetPath = filepath.Join(targetDir, target)
+		if inSource[name] || !opts.managed(name) {
+			continue
 		}
+		linkDir := filepath.Join(targetDir, filepath.Dir(name))
+		resolved := opts.resolveLinkTarget(sourceDir, linkDir, target, dirs)
+		if !opts.linksIntoSource(absSource, realSource, target, resolved, dirs) {
+			continue
+		}
+		if _, err := os.Lstat(resolved); err == nil {
+			continue
+		}
+		stale = append(stale, name)
+	}
+	sort.Strings(stale)
+	return stale, nil
+}
 
-		// Check if target exists
-		if _, err := os.Stat(targetPath); err != nil {
-			if os.IsNotExist(err) {
-				orphaned = append(orphaned, name)
+// linksIntoSource reports whether a symlink target (resolved as resolveLinkTarget
+// does) lies below the source directory or LinkPrefix, so lnka may have created it
+func (o Options) linksIntoSource(absSource, realSource, target, resolved string, dirs dirCache) bool {
+	if o.LinkPrefix != "" && isInside(filepath.Clean(o.LinkPrefix), filepath.Clean(target)) {
+		return true
+	}
+	abs, err := filepath.Abs(resolved)
+	if err != nil {
+		return false
+	}
+	if isInside(absSource, abs) {
+		return true
+	}
+	// The parent directories may be reached through symlinks
+	return isInside(realSource, filepath.Join(dirs.realPath(filepath.Dir(abs)), filepath.Base(abs)))
+}
+
+// PruneEmptyDirs removes the now-empty parent directories of the given names
+// (paths relative to targetDir), walking bottom-up. The target directory
+// itself is never removed and non-empty directories are left untouched.
+func PruneEmptyDirs(targetDir string, names []string) error {
+	for _, name := range names {
+		dir := filepath.Dir(filepath.Clean(name))
+		for dir != "." && dir != string(filepath.Separator) {
+			dirPath := filepath.Join(targetDir, dir)
+
+			entries, err := os.ReadDir(dirPath)
+			if err != nil {
+				if os.IsNotExist(err) {
+					// Already pruned via another name
+					dir = filepath.Dir(dir)
+					continue
+				}
+				return fmt.Errorf("failed to read directory %s: %w", dir, err)
 			}
+
+			if len(entries) > 0 {
+				break
+			}
+
+			if err := os.Remove(dirPath); err != nil {
+				return fmt.Errorf("failed to remove empty directory %s: %w", dir, err)
+			}
+			dir = filepath.Dir(dir)
 		}
 	}
 
-	return orphaned, nil
+	return nil
 }
 
 // CleanOrphanedSymlinks removes broken symlinks from the target directory
+// Names may be paths relative to targetDir for symlinks in subdirectories
 func CleanOrphanedSymlinks(targetDir string, orphaned []string) error {
 	for _, name := range orphaned {
 		if err := RemoveSymlink(targetDir, name); err != nil {
@@ -213,14 +453,190 @@ func CleanOrphanedSymlinks(targetDir string, orphaned []string) error {
 	return nil
 }
 
-// ApplyChanges applies the user's selection by creating and removing symlinks
-func ApplyChanges(sourceDir, targetDir string, selectedFiles []string) error {
-	// Get currently enabled files
-	currentlyEnabled, err := GetEnabledFiles(sourceDir, targetDir)
+// FindCaseCollisions groups names that differ only by letter case
+// Groups and the names within them keep the order of the input
+func FindCaseCollisions(names []string) [][]string {
+	groups := make(map[string][]string)
+	var keys []string
+	for _, name := range names {
+		folded := strings.ToLower(name)
+		if _, ok := groups[folded]; !ok {
+			keys = append(keys, folded)
+		}
+		groups[folded] = append(groups[folded], name)
+	}
+
+	var collisions [][]string
+	for _, k := range keys {
+		if len(groups[k]) > 1 {
+			collisions = append(collisions, groups[k])
+		}
+	}
+	return collisions
+}
+
+// StaleLinks returns the names whose source file was modified after the
+// symlink in the target directory was created. Names without a symlink are skipped.
+func StaleLinks(sourceDir, targetDir string, names []string) ([]string, error) {
+	return staleLinks(sourceDir, targetDir, names, Options{})
+}
+
+// staleLinks finds stale links like StaleLinks, looking up the links under
+// their renamed names
+func staleLinks(sourceDir, targetDir string, names []string, opts Options) ([]string, error) {
+	var stale []string
+	for _, name := range names {
+		linkInfo, err := os.Lstat(filepath.Join(targetDir, opts.linkName(name)))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to check symlink %s: %w", name, err)
+		}
+
+		sourceInfo, err := os.Stat(filepath.Join(sourceDir, name))
+		if err != nil {
+			return nil, fmt.Errorf("failed to check source file %s: %w", name, err)
+		}
+
+		if sourceInfo.ModTime().After(linkInfo.ModTime()) {
+			stale = append(stale, name)
+		}
+	}
+
+	return stale, nil
+}
+
+// CheckBootstrapTarget verifies that the target directory contains no symlinks
+// managed by lnka (symlinks pointing to files in the source directory)
+func CheckBootstrapTarget(sourceDir, targetDir string, opts Options) error {
+	enabled, err := GetEnabledFilesWithOptions(sourceDir, targetDir, opts)
 	if err != nil {
 		return fmt.Errorf("failed to get currently enabled files: %w", err)
 	}
 
+	if len(enabled) > 0 {
+		return fmt.Errorf("bootstrap refused: target already contains %d managed symlink(s)", len(enabled))
+	}
+
+	return nil
+}
+
+// ChangeSet describes the symlink operations needed to reach a selection
+type ChangeSet struct {
+	Create []string // Files to link (selected but not yet enabled)
+	Remove []string // Files to unlink (enabled but no longer selected)
+	Rename []string // Files to relink under the name given by Options.LinkNames (selected and enabled under another name)
+	Update []string // Files whose outdated copy is replaced with a new one (selected, see TargetState.Outdated)
+	Chmod  []string // Source files whose permissions differ from Options.SourceMode (selected)
+}
+
+// HasChanges reports whether the change set contains any operation
+func (c *ChangeSet) HasChanges() bool {
+	return len(c.Create) > 0 || len(c.Remove) > 0 || len(c.Rename) > 0 || len(c.Update) > 0 || len(c.Chmod) > 0
+}
+
+// PlanChanges computes the changes ApplyChanges would make for the given
+// selection without touching the filesystem
+func PlanChanges(sourceDir, targetDir string, selectedFiles []string, opts Options) (*ChangeSet, error) {
+	changes, _, err := planChanges(sourceDir, targetDir, selectedFiles, opts)
+	return changes, err
+}
+
+// planChanges computes the changes like PlanChanges and also returns the
+// state of the target they are based on
+func planChanges(sourceDir, targetDir string, selectedFiles []string, opts Options) (*ChangeSet, *TargetState, error) {
+	state, err := scanTarget(sourceDir, targetDir, opts, scanEnabled)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get currently enabled files: %w", err)
+	}
+
+	// Broken links to source files have no file to link and are left to the
+	// orphan cleanup, which asks first
+	changes := diffSelection(state.Enabled, selectedFiles)
+	if len(state.Broken) > 0 {
+		broken := make(map[string]bool, len(state.Broken))
+		for _, name := range state.Broken {
+			broken[name] = true
+		}
+		changes.Create = slices.DeleteFunc(changes.Create, func(name string) bool { return broken[name] })
+	}
+
+	// Enabled files keep the name of their link unless another one is given
+	current := currentLinks(opts, state)
+	enabled := make(map[string]bool, len(state.Enabled))
+	for _, name := range state.Enabled {
+		enabled[name] = true
+	}
+	for _, name := range selectedFiles {
+		link, ok := opts.LinkNames[name]
+		if ok && enabled[name] && current.linkName(name) != link {
+			changes.Rename = append(changes.Rename, name)
+			enabled[name] = false // Listed once
+		}
+	}
+
+	// Selected copies no longer matching their source are copied anew
+	if len(state.Outdated) > 0 {
+		selected := make(map[string]bool, len(selectedFiles))
+		for _, name := range selectedFiles {
+			selected[name] = true
+		}
+		for _, name := range state.Outdated {
+			if selected[name] {
+				changes.Update = append(changes.Update, name)
+			}
+		}
+	}
+
+	if opts.SourceMode != 0 {
+		changes.Chmod, err = sourceModeChanges(sourceDir, selectedFiles, opts.SourceMode)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	return changes, state, nil
+}
+
+// sourceModeChanges returns the files whose permission bits differ from mode
+func sourceModeChanges(sourceDir string, files []string, mode os.FileMode) ([]string, error) {
+	var changed []string
+	seen := make(map[string]bool, len(files))
+	for _, name := range files {
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		info, err := os.Stat(filepath.Join(sourceDir, name))
+		if err != nil {
+			return nil, fmt.Errorf("failed to check source file %s: %w", name, err)
+		}
+		if info.Mode().Perm() != mode.Perm() {
+			changed = append(changed, name)
+		}
+	}
+	return changed, nil
+}
+
+// currentLinks returns the options naming the links of the enabled files as
+// found in the target, to reach the existing links (e.g. for removal)
+func currentLinks(opts Options, state *TargetState) Options {
+	if len(state.Renamed) == 0 {
+		return opts
+	}
+	linkNames := maps.Clone(opts.LinkNames)
+	if linkNames == nil {
+		linkNames = make(map[string]string, len(state.Renamed))
+	}
+	maps.Copy(linkNames, state.Renamed)
+	opts.LinkNames = linkNames
+	return opts
+}
+
+// diffSelection compares the currently enabled files with the selection
+// Removals follow the order of currentlyEnabled, creations the order of selectedFiles
+func diffSelection(currentlyEnabled, selectedFiles []string) *ChangeSet {
 	// Convert to maps for easier lookup
 	selectedMap := make(map[string]bool)
 	for _, name := range selectedFiles {
@@ -232,23 +648,426 @@ func ApplyChanges(sourceDir, targetDir string, selectedFiles []string) error {
 		currentMap[name] = true
 	}
 
-	// Remove symlinks for files that are no longer selected
+	changes := &ChangeSet{}
 	for _, name := range currentlyEnabled {
 		if !selectedMap[name] {
-			if err := RemoveSymlink(targetDir, name); err != nil {
-				return err
+			changes.Remove = append(changes.Remove, name)
+		}
+	}
+	for _, name := range selectedFiles {
+		if !currentMap[name] {
+			changes.Create = append(changes.Create, name)
+		}
+	}
+
+	return changes
+}
+
+// ApplyOptions controls how ApplyChangesWithOptions reconciles the target directory
+type ApplyOptions struct {
+	// Options controls how symlinks are created and recognized
+	Options
+
+	// Bootstrap only creates symlinks and refuses to run if the target
+	// already contains managed symlinks
+	Bootstrap bool
+
+	// PruneEmptyDirs removes target subdirectories left empty by removals
+	PruneEmptyDirs bool
+
+	// RemovableAllowlist restricts removals to the listed symlink names
+	// (nil = no restriction). Other removals are skipped with a warning; with
+	// Strict they fail the apply before anything is changed.
+	RemovableAllowlist map[string]bool
+
+	// Strict turns warnings into errors
+	Strict bool
+
+	// DryRun computes and returns the operations without touching the
+	// filesystem; the result lists what would be created and removed
+	DryRun bool
+
+	// Additive treats the selection as an addition to the existing links:
+	// the desired set is currently enabled ∪ selected, so nothing is removed
+	Additive bool
+
+	// OnlyChanged recreates the links of already enabled, selected files
+	// whose source file is newer than the link itself
+	OnlyChanged bool
+
+	// VerifyAfter checks the target for dangling symlinks once all changes
+	// are applied and reports each one through warnf
+	VerifyAfter bool
+
+	// ContinueOnError keeps processing the remaining files after a failed
+	// operation and returns all errors joined at the end
+	ContinueOnError bool
+
+	// Logf is called for each file once it was linked, unlinked, relinked or
+	// skipped (optional, not called with DryRun)
+	Logf func(format string, args ...any)
+}
+
+// warnf reports a non-fatal problem via Options.Warnf, or returns it as an
+// error in strict mode
+func (o ApplyOptions) warnf(format string, args ...any) error {
+	if o.Strict {
+		return fmt.Errorf(format, args...)
+	}
+	o.warn(format, args...)
+	return nil
+}
+
+// logf reports a performed operation via Logf
+func (o ApplyOptions) logf(format string, args ...any) {
+	if o.Logf != nil && !o.DryRun {
+		o.Logf(format, args...)
+	}
+}
+
+// absoluteNote explains for the Logf line of a new symlink why it got an
+// absolute target although the style asks for a relative one (empty if not)
+func (o ApplyOptions) absoluteNote(sourceDir, targetDir, name string) string {
+	if o.Logf == nil || o.DryRun || o.Mode.usesFiles() {
+		return ""
+	}
+	if _, fallback, err := canonicalTarget(sourceDir, targetDir, name, o.Options); err == nil && fallback != "" {
+		return " with an absolute path: " + fallback
+	}
+	return ""
+}
+
+// ChangeResult reports the operations performed by ApplyChangesWithOptions
+type ChangeResult struct {
+	Created     []string `json:"created"`     // Files that were linked
+	Removed     []string `json:"removed"`     // Files that were unlinked
+	Unchanged   []string `json:"unchanged"`   // Selected files already linked
+	Refused     []string `json:"refused"`     // Files whose current link the removable allowlist kept
+	Relinked    []string `json:"relinked"`    // Selections relinked because the source changed (outdated copies, or newer sources with OnlyChanged)
+	Renamed     []string `json:"renamed"`     // Enabled files relinked under the name given by Options.LinkNames
+	ModeChanged []string `json:"modeChanged"` // Source files whose permissions were set to Options.SourceMode
+	Failed      []string `json:"failed"`      // Files whose operation failed (only with ContinueOnError)
+	BackedUp    []string `json:"backedUp"`    // Backup paths of regular files moved aside for new links (only with Backup)
+}
+
+// ApplyChanges applies the user's selection by creating and removing symlinks
+// Returns what was created, removed and left unchanged
+func ApplyChanges(sourceDir, targetDir string, selectedFiles []string) (*ChangeResult, error) {
+	return ApplyChangesWithOptions(sourceDir, targetDir, selectedFiles, ApplyOptions{})
+}
+
+// ApplyChangesWithOptions applies the user's selection like ApplyChanges,
+// honoring the given options. The returned result lists what was done (or
+// would be done with DryRun), even if an error occurred. Unless
+// ContinueOnError is set, a failed operation rolls back all changes made so
+// far and the result is empty.
+func ApplyChangesWithOptions(sourceDir, targetDir string, selectedFiles []string, opts ApplyOptions) (*ChangeResult, error) {
+	result := &ChangeResult{}
+
+	// The source may have been unmounted or removed while the UI was open
+	if err := CheckDirExists(sourceDir); err != nil {
+		return result, fmt.Errorf("source directory is no longer available: %w", err)
+	}
+
+	// Bootstrap mode only runs on a target without managed symlinks,
+	// so there is never anything to remove
+	if opts.Bootstrap {
+		if err := CheckBootstrapTarget(sourceDir, targetDir, opts.Options); err != nil {
+			return result, err
+		}
+	}
+
+	// Names differing only by case collide on case-insensitive targets
+	for _, group := range FindCaseCollisions(selectedFiles) {
+		if err := opts.warnf("names differ only by case and collide on case-insensitive targets: %s", strings.Join(group, ", ")); err != nil {
+			return result, err
+		}
+	}
+
+	changes, state, err := planChanges(sourceDir, targetDir, selectedFiles, opts.Options)
+	if err != nil {
+		return result, err
+	}
+	// Existing links are removed under the name found in the target
+	current := currentLinks(opts.Options, state)
+	if opts.Additive {
+		changes.Remove = nil
+	}
+
+	// In strict mode a removal outside the allowlist fails the apply before
+	// anything is changed
+	if opts.Strict && opts.RemovableAllowlist != nil {
+		var refused []string
+		for _, name := range slices.Concat(changes.Remove, changes.Rename) {
+			if !opts.RemovableAllowlist[name] {
+				refused = append(refused, name)
+			}
+		}
+		if len(refused) > 0 {
+			return result, fmt.Errorf("refusing to remove %s: not in removable allowlist", strings.Join(refused, ", "))
+		}
+	}
+
+	if opts.CreateTarget && !opts.DryRun {
+		if err := EnsureDir(targetDir); err != nil {
+			return result, fmt.Errorf("target directory: %w", err)
+		}
+	}
+
+	// abort undoes the operations done so far, so a failed apply leaves the
+	// target as it was
+	var undo rollback
+	abort := func(err error) error {
+		if rollbackErr := undo.run(); rollbackErr != nil {
+			return fmt.Errorf("%w (failed to roll back: %v)", err, rollbackErr)
+		}
+		*result = ChangeResult{}
+		return fmt.Errorf("%w (all changes were rolled back)", err)
+	}
+
+	// fail records a per-file error; it aborts the apply unless
+	// ContinueOnError is set, in which case errors are collected
+	var errs []error
+	fail := func(name string, err error) error {
+		if !opts.ContinueOnError {
+			return abort(err)
+		}
+		result.Failed = append(result.Failed, name)
+		errs = append(errs, err)
+		return nil
+	}
+
+	// refused reports whether the allowlist keeps the current link of the
+	// file, which removals and renames would delete
+	refused := func(name string) (bool, error) {
+		if opts.RemovableAllowlist == nil || opts.RemovableAllowlist[name] {
+			return false, nil
+		}
+		if err := opts.warnf("refusing to remove %s: not in removable allowlist", name); err != nil {
+			return true, fail(name, err)
+		}
+		result.Refused = append(result.Refused, name)
+		opts.logf("skipped %s (not in removable allowlist)", name)
+		return true, nil
+	}
+
+	// Normalize source permissions before linking
+	for _, name := range changes.Chmod {
+		if !opts.DryRun {
+			path := filepath.Join(sourceDir, name)
+			undo.recordMode(path)
+			if err := os.Chmod(path, opts.SourceMode.Perm()); err != nil {
+				if err := fail(name, fmt.Errorf("failed to change mode of %s: %w", name, err)); err != nil {
+					return result, err
+				}
+				continue
 			}
 		}
+		result.ModeChanged = append(result.ModeChanged, name)
+		opts.logf("changed the mode of %s to %04o", name, opts.SourceMode.Perm())
+	}
+
+	// Remove symlinks for files that are no longer selected
+	for _, name := range changes.Remove {
+		if skip, err := refused(name); err != nil {
+			return result, err
+		} else if skip {
+			continue
+		}
+		if !opts.DryRun {
+			undo.record(sourceDir, targetDir, name, current)
+			if err := removeLink(sourceDir, targetDir, name, current); err != nil {
+				if err := fail(name, err); err != nil {
+					return result, err
+				}
+				continue
+			}
+		}
+		result.Removed = append(result.Removed, name)
+		opts.logf("unlinked %s", name)
+	}
+
+	if opts.PruneEmptyDirs && !opts.DryRun {
+		if err := PruneEmptyDirs(targetDir, result.Removed); err != nil {
+			if !opts.ContinueOnError {
+				return result, abort(err)
+			}
+			errs = append(errs, err)
+		}
 	}
 
 	// Create symlinks for newly selected files
+	for _, name := range changes.Create {
+		if !opts.DryRun {
+			undo.record(sourceDir, targetDir, name, opts.Options)
+			backupPath, err := CreateSymlinkWithBackup(sourceDir, targetDir, name, opts.Options)
+			if err != nil {
+				if err := fail(name, err); err != nil {
+					return result, err
+				}
+				continue
+			}
+			if backupPath != "" {
+				undo.recordBackup(backupPath, filepath.Join(targetDir, opts.linkName(name)))
+				result.BackedUp = append(result.BackedUp, backupPath)
+			}
+		}
+		result.Created = append(result.Created, name)
+		opts.logf("linked %s%s", name, opts.absoluteNote(sourceDir, targetDir, name))
+	}
+
+	// Relink enabled files under their new name
+	for _, name := range changes.Rename {
+		if skip, err := refused(name); err != nil {
+			return result, err
+		} else if skip {
+			continue
+		}
+		if !opts.DryRun {
+			undo.record(sourceDir, targetDir, name, current)
+			if err := removeLink(sourceDir, targetDir, name, current); err != nil {
+				if err := fail(name, err); err != nil {
+					return result, err
+				}
+				continue
+			}
+			undo.record(sourceDir, targetDir, name, opts.Options)
+			backupPath, err := CreateSymlinkWithBackup(sourceDir, targetDir, name, opts.Options)
+			if err != nil {
+				if err := fail(name, err); err != nil {
+					return result, err
+				}
+				continue
+			}
+			if backupPath != "" {
+				undo.recordBackup(backupPath, filepath.Join(targetDir, opts.linkName(name)))
+				result.BackedUp = append(result.BackedUp, backupPath)
+			}
+		}
+		result.Renamed = append(result.Renamed, name)
+		opts.logf("renamed the link of %s from %s to %s%s", name, current.linkName(name), opts.linkName(name), opts.absoluteNote(sourceDir, targetDir, name))
+	}
+
+	// Replace outdated copies, which are no symlinks to replace otherwise
+	update := opts.Options
+	update.Overwrite = true
+	for _, name := range changes.Update {
+		if !opts.DryRun {
+			undo.record(sourceDir, targetDir, name, opts.Options)
+			backupPath, err := CreateSymlinkWithBackup(sourceDir, targetDir, name, update)
+			if err != nil {
+				if err := fail(name, err); err != nil {
+					return result, err
+				}
+				continue
+			}
+			if backupPath != "" {
+				undo.recordBackup(backupPath, filepath.Join(targetDir, opts.linkName(name)))
+				result.BackedUp = append(result.BackedUp, backupPath)
+			}
+		}
+		result.Relinked = append(result.Relinked, name)
+		opts.logf("updated the copy of %s", name)
+	}
+
+	if opts.OnlyChanged {
+		if err := relinkStale(sourceDir, targetDir, selectedFiles, changes, opts, result, &undo, fail); err != nil {
+			return result, err
+		}
+	}
+
+	// Selected files that were already linked stay as they are
+	kept := keptFiles(selectedFiles, slices.Concat(changes.Create, changes.Rename), result.Relinked)
+	for _, name := range kept {
+		opts.logf("skipped %s (already linked)", name)
+	}
+	result.Unchanged = kept
+
+	if opts.VerifyAfter && !opts.DryRun {
+		if err := verifyLinks(sourceDir, targetDir, opts); err != nil {
+			if !opts.ContinueOnError {
+				return result, err
+			}
+			errs = append(errs, err)
+		}
+	}
+
+	return result, errors.Join(errs...)
+}
+
+// keptFiles returns the selected files that were neither created nor relinked,
+// i.e. whose existing link was left unchanged
+func keptFiles(selectedFiles, created, relinked []string) []string {
+	changed := make(map[string]bool, len(created)+len(relinked))
+	for _, name := range created {
+		changed[name] = true
+	}
+	for _, name := range relinked {
+		changed[name] = true
+	}
+
+	var kept []string
 	for _, name := range selectedFiles {
-		if !currentMap[name] {
-			if err := CreateSymlink(sourceDir, targetDir, name); err != nil {
+		if !changed[name] {
+			changed[name] = true // Report duplicates once
+			kept = append(kept, name)
+		}
+	}
+	return kept
+}
+
+// relinkStale recreates the links of selected files that were already enabled
+// and whose source changed since the link was created
+func relinkStale(sourceDir, targetDir string, selectedFiles []string, changes *ChangeSet, opts ApplyOptions, result *ChangeResult, undo *rollback, fail func(string, error) error) error {
+	created := make(map[string]bool, len(changes.Create)+len(changes.Update))
+	for _, name := range slices.Concat(changes.Create, changes.Update) {
+		created[name] = true
+	}
+
+	for _, name := range selectedFiles {
+		if created[name] {
+			continue
+		}
+		// A failed lookup counts as a failure of the file like a failed link
+		stale, err := staleLinks(sourceDir, targetDir, []string{name}, opts.Options)
+		if err != nil {
+			if err := fail(name, err); err != nil {
 				return err
 			}
+			continue
+		}
+		if len(stale) == 0 {
+			continue
+		}
+
+		if !opts.DryRun {
+			undo.record(sourceDir, targetDir, name, opts.Options)
+			if err := CreateSymlinkWithOptions(sourceDir, targetDir, name, opts.Options); err != nil {
+				if err := fail(name, err); err != nil {
+					return err
+				}
+				continue
+			}
 		}
+		result.Relinked = append(result.Relinked, name)
+		opts.logf("relinked %s%s", name, opts.absoluteNote(sourceDir, targetDir, name))
+	}
+
+	return nil
+}
+
+// verifyLinks reports every dangling symlink in the target directory
+func verifyLinks(sourceDir, targetDir string, opts ApplyOptions) error {
+	dangling, err := ValidateSymlinksWithOptions(sourceDir, targetDir, opts.Options)
+	if err != nil {
+		return fmt.Errorf("failed to verify symlinks: %w", err)
 	}
 
+	sort.Strings(dangling)
+	for _, name := range dangling {
+		if err := opts.warnf("dangling symlink after apply: %s", name); err != nil {
+			return err
+		}
+	}
 	return nil
 }
@@ -1,9 +1,14 @@
 package filesystem
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
 	"testing"
+	"time"
 )
 
 // TestCreateSymlink_SiblingDirectories tests that symlinks are created correctly
@@ -146,6 +151,223 @@ func TestCreateSymlink_NestedDirectories(t *testing.T) {
 	}
 }
 
+// TestCreateSymlink_LinkStyle tests the target form of each link style, with
+// the relative style kept for long paths when their depth is not limited
+func TestCreateSymlink_LinkStyle(t *testing.T) {
+	tempDir := t.TempDir()
+	sourceDir := filepath.Join(tempDir, "source")
+	targetDir := filepath.Join(tempDir, "a", "b", "c", "d", "e", "f", "target")
+	for _, dir := range []string{sourceDir, targetDir} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(sourceDir, "test.conf"), []byte("test"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	absSource, err := filepath.Abs(filepath.Join(sourceDir, "test.conf"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		style LinkStyle
+		want  string
+	}{
+		{style: "", want: filepath.Join("..", "..", "..", "..", "..", "..", "..", "source", "test.conf")},
+		{style: LinkStyleAuto, want: filepath.Join("..", "..", "..", "..", "..", "..", "..", "source", "test.conf")},
+		{style: LinkStyleRelative, want: filepath.Join("..", "..", "..", "..", "..", "..", "..", "source", "test.conf")},
+		{style: LinkStyleAbsolute, want: absSource},
+	}
+	for _, tt := range tests {
+		if err := CreateSymlinkWithOptions(sourceDir, targetDir, "test.conf", Options{Style: tt.style, MaxUpLevels: -1}); err != nil {
+			t.Fatalf("CreateSymlinkWithOptions(%q) failed: %v", tt.style, err)
+		}
+		got, err := os.Readlink(filepath.Join(targetDir, "test.conf"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("style %q: link target = %q, want %q", tt.style, got, tt.want)
+		}
+
+		enabled, err := GetEnabledFilesWithOptions(sourceDir, targetDir, Options{Style: tt.style})
+		if err != nil || !reflect.DeepEqual(enabled, []string{"test.conf"}) {
+			t.Errorf("style %q: GetEnabledFiles = %v, %v, want [test.conf]", tt.style, enabled, err)
+		}
+	}
+}
+
+// TestCreateSymlink_MaxUpLevels tests the absolute fallback for relative
+// targets needing too many levels up, including its report via Logf
+func TestCreateSymlink_MaxUpLevels(t *testing.T) {
+	tempDir := t.TempDir()
+	sourceDir := filepath.Join(tempDir, "source")
+	if err := os.Mkdir(sourceDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(sourceDir, "test.conf"), []byte("test"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	absSource, err := filepath.Abs(filepath.Join(sourceDir, "test.conf"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		targetDir string
+		style     LinkStyle
+		maxUp     int
+		want      string
+		fallback  bool
+	}{
+		{name: "sibling", targetDir: "target", want: filepath.Join("..", "source", "test.conf")},
+		{name: "deeply nested", targetDir: filepath.Join("a", "b", "c", "d", "e", "target"), want: absSource, fallback: true},
+		{name: "custom limit", targetDir: filepath.Join("f", "g", "target"), maxUp: 2, want: absSource, fallback: true},
+		{name: "no limit", targetDir: filepath.Join("h", "i", "j", "k", "l", "target"), maxUp: -1, want: filepath.Join("..", "..", "..", "..", "..", "..", "source", "test.conf")},
+		{name: "relative style", targetDir: filepath.Join("m", "n", "o", "p", "q", "target"), style: LinkStyleRelative, want: filepath.Join("..", "..", "..", "..", "..", "..", "source", "test.conf")},
+		{name: "relative style with limit", targetDir: filepath.Join("r", "s", "target"), style: LinkStyleRelative, maxUp: 2, want: absSource, fallback: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			targetDir := filepath.Join(tempDir, tt.targetDir)
+			if err := os.MkdirAll(targetDir, 0755); err != nil {
+				t.Fatal(err)
+			}
+
+			var reports []string
+			opts := ApplyOptions{
+				Options: Options{Style: tt.style, MaxUpLevels: tt.maxUp},
+				Logf: func(format string, args ...any) {
+					reports = append(reports, fmt.Sprintf(format, args...))
+				},
+			}
+			if _, err := ApplyChangesWithOptions(sourceDir, targetDir, []string{"test.conf"}, opts); err != nil {
+				t.Fatalf("ApplyChangesWithOptions failed: %v", err)
+			}
+			got, err := os.Readlink(filepath.Join(targetDir, "test.conf"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("link target = %q, want %q", got, tt.want)
+			}
+			if len(reports) != 1 {
+				t.Fatalf("reports = %q, want one", reports)
+			}
+			if got := strings.Contains(reports[0], "levels up (max"); got != tt.fallback {
+				t.Errorf("report = %q, want the fallback explained: %v", reports[0], tt.fallback)
+			}
+		})
+	}
+}
+
+// TestCreateSymlink_ReplacesExistingLink tests that an existing symlink is
+// replaced in place without leaving temporary links behind
+func TestCreateSymlink_ReplacesExistingLink(t *testing.T) {
+	sourceDir, targetDir := setupSourceTarget(t, "test.conf")
+	linkPath := filepath.Join(targetDir, "test.conf")
+
+	if err := os.Symlink("/elsewhere/test.conf", linkPath); err != nil {
+		t.Fatalf("Failed to create existing symlink: %v", err)
+	}
+	// Leftover of an interrupted run with the same pid
+	leftover := filepath.Join(targetDir, fmt.Sprintf(".test.conf.tmp-%d", os.Getpid()))
+	if err := os.Symlink("/stale", leftover); err != nil {
+		t.Fatalf("Failed to create leftover symlink: %v", err)
+	}
+
+	if err := CreateSymlink(sourceDir, targetDir, "test.conf"); err != nil {
+		t.Fatalf("CreateSymlink failed: %v", err)
+	}
+
+	linkTarget, err := os.Readlink(linkPath)
+	if err != nil {
+		t.Fatalf("Failed to read symlink: %v", err)
+	}
+	if want := filepath.Join("..", "source", "test.conf"); linkTarget != want {
+		t.Errorf("Symlink target = %q, want %q", linkTarget, want)
+	}
+
+	entries, err := os.ReadDir(targetDir)
+	if err != nil {
+		t.Fatalf("Failed to read target dir: %v", err)
+	}
+	if len(entries) != 1 {
+		var names []string
+		for _, entry := range entries {
+			names = append(names, entry.Name())
+		}
+		t.Errorf("target entries = %v, want only test.conf", names)
+	}
+}
+
+// TestCreateSymlink_TargetDirIsSymlink tests that relative links are computed
+// from the real location of a symlinked target directory
+func TestCreateSymlink_TargetDirIsSymlink(t *testing.T) {
+	// temp/
+	//   ├── etc/
+	//   │   ├── available/
+	//   │   │   └── test.conf
+	//   │   └── enabled -> ../data/enabled
+	//   └── data/
+	//       └── enabled/
+	//           └── test.conf -> ../../etc/available/test.conf
+	tempDir := t.TempDir()
+	sourceDir := filepath.Join(tempDir, "etc", "available")
+	realTarget := filepath.Join(tempDir, "data", "enabled")
+	targetDir := filepath.Join(tempDir, "etc", "enabled")
+
+	for _, dir := range []string{sourceDir, realTarget} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatalf("Failed to create dir: %v", err)
+		}
+	}
+	if err := os.Symlink(filepath.Join("..", "data", "enabled"), targetDir); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(sourceDir, "test.conf"), []byte("config data"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	if err := CreateSymlink(sourceDir, targetDir, "test.conf"); err != nil {
+		t.Fatalf("CreateSymlink failed: %v", err)
+	}
+
+	linkTarget, err := os.Readlink(filepath.Join(realTarget, "test.conf"))
+	if err != nil {
+		t.Fatalf("Failed to read symlink: %v", err)
+	}
+	expected := filepath.Join("..", "..", "etc", "available", "test.conf")
+	if linkTarget != expected {
+		t.Errorf("Symlink target = %q, want %q", linkTarget, expected)
+	}
+
+	content, err := os.ReadFile(filepath.Join(targetDir, "test.conf"))
+	if err != nil || string(content) != "config data" {
+		t.Errorf("Cannot read through symlink: %q, %v", content, err)
+	}
+
+	for _, opts := range []Options{{}, {Recursive: true}} {
+		enabled, err := GetEnabledFilesWithOptions(sourceDir, targetDir, opts)
+		if err != nil {
+			t.Fatalf("GetEnabledFilesWithOptions failed: %v", err)
+		}
+		if !reflect.DeepEqual(enabled, []string{"test.conf"}) {
+			t.Errorf("enabled (recursive=%v) = %v, want [test.conf]", opts.Recursive, enabled)
+		}
+
+		orphaned, err := ValidateSymlinksWithOptions(sourceDir, targetDir, opts)
+		if err != nil {
+			t.Fatalf("ValidateSymlinksWithOptions failed: %v", err)
+		}
+		if len(orphaned) != 0 {
+			t.Errorf("orphaned (recursive=%v) = %v, want none", opts.Recursive, orphaned)
+		}
+	}
+}
+
 // TestListAvailableFiles tests listing files in a directory
 func TestListAvailableFiles(t *testing.T) {
 	tempDir := t.TempDir()
@@ -501,10 +723,22 @@ func TestApplyChanges(t *testing.T) {
 
 	// Apply changes: keep file1, remove file2, add file3
 	selectedFiles := []string{"file1.txt", "file3.txt"}
-	if err := ApplyChanges(sourceDir, targetDir, selectedFiles); err != nil {
+	result, err := ApplyChanges(sourceDir, targetDir, selectedFiles)
+	if err != nil {
 		t.Fatalf("ApplyChanges failed: %v", err)
 	}
 
+	// Verify the reported changes
+	if !reflect.DeepEqual(result.Created, []string{"file3.txt"}) {
+		t.Errorf("Created = %v, want [file3.txt]", result.Created)
+	}
+	if !reflect.DeepEqual(result.Removed, []string{"file2.txt"}) {
+		t.Errorf("Removed = %v, want [file2.txt]", result.Removed)
+	}
+	if !reflect.DeepEqual(result.Unchanged, []string{"file1.txt"}) {
+		t.Errorf("Unchanged = %v, want [file1.txt]", result.Unchanged)
+	}
+
 	// Verify file1 still exists
 	link1 := filepath.Join(targetDir, "file1.txt")
 	if _, err := os.Lstat(link1); err != nil {
@@ -534,3 +768,798 @@ func TestApplyChanges(t *testing.T) {
 		t.Errorf("Expected relative symlink, got absolute: %s", target)
 	}
 }
+
+// TestApplyChangesWithOptions_BootstrapEmptyTarget tests that bootstrap mode
+// creates all selected symlinks on a target without managed symlinks
+func TestApplyChangesWithOptions_BootstrapEmptyTarget(t *testing.T) {
+	sourceDir, targetDir := setupSourceTarget(t, "file1.txt", "file2.txt", "file3.txt")
+
+	selectedFiles := []string{"file1.txt", "file3.txt"}
+	opts := ApplyOptions{Bootstrap: true}
+	if _, err := ApplyChangesWithOptions(sourceDir, targetDir, selectedFiles, opts); err != nil {
+		t.Fatalf("ApplyChangesWithOptions failed: %v", err)
+	}
+
+	for _, f := range selectedFiles {
+		if _, err := os.Lstat(filepath.Join(targetDir, f)); err != nil {
+			t.Errorf("%s symlink should have been created", f)
+		}
+	}
+
+	if _, err := os.Lstat(filepath.Join(targetDir, "file2.txt")); !os.IsNotExist(err) {
+		t.Error("file2.txt symlink should not have been created")
+	}
+}
+
+// TestApplyChangesWithOptions_BootstrapPopulatedTarget tests that bootstrap mode
+// refuses to touch a target that already contains managed symlinks
+func TestApplyChangesWithOptions_BootstrapPopulatedTarget(t *testing.T) {
+	sourceDir, targetDir := setupSourceTarget(t, "file1.txt", "file2.txt")
+
+	if err := CreateSymlink(sourceDir, targetDir, "file1.txt"); err != nil {
+		t.Fatalf("Failed to create initial symlink: %v", err)
+	}
+
+	if err := CheckBootstrapTarget(sourceDir, targetDir, Options{}); err == nil {
+		t.Error("CheckBootstrapTarget should refuse a populated target")
+	}
+
+	// Deselecting file1 would normally remove it
+	opts := ApplyOptions{Bootstrap: true}
+	_, err := ApplyChangesWithOptions(sourceDir, targetDir, []string{"file2.txt"}, opts)
+	if err == nil {
+		t.Fatal("ApplyChangesWithOptions should refuse a populated target in bootstrap mode")
+	}
+
+	// Nothing must have changed
+	if _, err := os.Lstat(filepath.Join(targetDir, "file1.txt")); err != nil {
+		t.Error("file1.txt symlink should not have been removed")
+	}
+	if _, err := os.Lstat(filepath.Join(targetDir, "file2.txt")); !os.IsNotExist(err) {
+		t.Error("file2.txt symlink should not have been created")
+	}
+}
+
+// setupSourceTarget creates source and target directories in a temp dir
+// and populates the source directory with the given files
+func setupSourceTarget(t *testing.T, files ...string) (string, string) {
+	t.Helper()
+
+	tempDir := t.TempDir()
+	sourceDir := filepath.Join(tempDir, "source")
+	targetDir := filepath.Join(tempDir, "target")
+
+	if err := os.MkdirAll(sourceDir, 0755); err != nil {
+		t.Fatalf("Failed to create source dir: %v", err)
+	}
+	if err := os.MkdirAll(targetDir, 0755); err != nil {
+		t.Fatalf("Failed to create target dir: %v", err)
+	}
+
+	for _, f := range files {
+		path := filepath.Join(sourceDir, f)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create parent dir for %s: %v", f, err)
+		}
+		if err := os.WriteFile(path, []byte("test"), 0644); err != nil {
+			t.Fatalf("Failed to create source file %s: %v", f, err)
+		}
+	}
+
+	return sourceDir, targetDir
+}
+
+// TestApplyChangesWithOptions_SourceMode tests that only selected files with
+// mismatched permissions are changed, and none in a dry run
+func TestApplyChangesWithOptions_SourceMode(t *testing.T) {
+	sourceDir, targetDir := setupSourceTarget(t, "ok.conf", "private.conf", "script.sh")
+
+	modes := map[string]os.FileMode{
+		"ok.conf":      0644,
+		"private.conf": 0600,
+		"script.sh":    0755,
+	}
+	for name, mode := range modes {
+		if err := os.Chmod(filepath.Join(sourceDir, name), mode); err != nil {
+			t.Fatalf("Failed to chmod %s: %v", name, err)
+		}
+	}
+
+	selection := []string{"ok.conf", "private.conf", "script.sh"}
+	expected := []string{"private.conf", "script.sh"}
+	opts := ApplyOptions{Options: Options{SourceMode: 0644}}
+
+	changes, err := PlanChanges(sourceDir, targetDir, selection, opts.Options)
+	if err != nil {
+		t.Fatalf("PlanChanges failed: %v", err)
+	}
+	if !reflect.DeepEqual(changes.Chmod, expected) {
+		t.Errorf("Chmod = %v, want %v", changes.Chmod, expected)
+	}
+
+	dryRun := opts
+	dryRun.DryRun = true
+	result, err := ApplyChangesWithOptions(sourceDir, targetDir, selection, dryRun)
+	if err != nil {
+		t.Fatalf("ApplyChangesWithOptions (dry run) failed: %v", err)
+	}
+	if !reflect.DeepEqual(result.ModeChanged, expected) {
+		t.Errorf("Dry run ModeChanged = %v, want %v", result.ModeChanged, expected)
+	}
+	if info, err := os.Stat(filepath.Join(sourceDir, "private.conf")); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("Dry run changed the mode of private.conf")
+	}
+
+	result, err = ApplyChangesWithOptions(sourceDir, targetDir, selection, opts)
+	if err != nil {
+		t.Fatalf("ApplyChangesWithOptions failed: %v", err)
+	}
+	if !reflect.DeepEqual(result.ModeChanged, expected) {
+		t.Errorf("ModeChanged = %v, want %v", result.ModeChanged, expected)
+	}
+
+	for name := range modes {
+		info, err := os.Stat(filepath.Join(sourceDir, name))
+		if err != nil {
+			t.Fatalf("Failed to stat %s: %v", name, err)
+		}
+		if info.Mode().Perm() != 0644 {
+			t.Errorf("%s mode = %04o, want 0644", name, info.Mode().Perm())
+		}
+	}
+
+	// Running again changes nothing
+	result, err = ApplyChangesWithOptions(sourceDir, targetDir, selection, opts)
+	if err != nil {
+		t.Fatalf("ApplyChangesWithOptions failed: %v", err)
+	}
+	if len(result.ModeChanged) != 0 {
+		t.Errorf("Expected no mode changes on second run, got %v", result.ModeChanged)
+	}
+}
+
+// TestValidateSymlinksRecursive tests finding broken symlinks nested in subdirectories
+func TestValidateSymlinksRecursive(t *testing.T) {
+	sourceDir, targetDir := setupSourceTarget(t, "valid.txt")
+
+	// Layout:
+	// target/
+	//   ├── valid.txt -> ../source/valid.txt
+	//   ├── broken.txt -> ../source/missing.txt
+	//   └── apps/
+	//       ├── keep.txt (regular file)
+	//       └── web/
+	//           └── broken.conf -> /nonexistent/broken.conf
+	nestedDir := filepath.Join(targetDir, "apps", "web")
+	if err := os.MkdirAll(nestedDir, 0755); err != nil {
+		t.Fatalf("Failed to create nested dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(targetDir, "apps", "keep.txt"), []byte("keep"), 0644); err != nil {
+		t.Fatalf("Failed to create regular file: %v", err)
+	}
+	if err := CreateSymlink(sourceDir, targetDir, "valid.txt"); err != nil {
+		t.Fatalf("Failed to create valid symlink: %v", err)
+	}
+	if err := os.Symlink("../source/missing.txt", filepath.Join(targetDir, "broken.txt")); err != nil {
+		t.Fatalf("Failed to create broken symlink: %v", err)
+	}
+	if err := os.Symlink("/nonexistent/broken.conf", filepath.Join(nestedDir, "broken.conf")); err != nil {
+		t.Fatalf("Failed to create nested broken symlink: %v", err)
+	}
+
+	orphaned, err := ValidateSymlinksRecursive(sourceDir, targetDir)
+	if err != nil {
+		t.Fatalf("ValidateSymlinksRecursive failed: %v", err)
+	}
+
+	expected := []string{filepath.Join("apps", "web", "broken.conf"), "broken.txt"}
+	if !reflect.DeepEqual(orphaned, expected) {
+		t.Errorf("Orphaned = %v, want %v", orphaned, expected)
+	}
+
+	// Clean nested orphans and prune the emptied directory
+	if err := CleanOrphanedSymlinks(targetDir, orphaned); err != nil {
+		t.Fatalf("CleanOrphanedSymlinks failed: %v", err)
+	}
+	if err := PruneEmptyDirs(targetDir, orphaned); err != nil {
+		t.Fatalf("PruneEmptyDirs failed: %v", err)
+	}
+
+	if _, err := os.Lstat(nestedDir); !os.IsNotExist(err) {
+		t.Error("Empty directory apps/web should have been pruned")
+	}
+	if _, err := os.Stat(filepath.Join(targetDir, "apps", "keep.txt")); err != nil {
+		t.Error("Non-empty directory apps should remain")
+	}
+	if _, err := os.Lstat(filepath.Join(targetDir, "valid.txt")); err != nil {
+		t.Error("Valid symlink should remain")
+	}
+}
+
+// TestPruneEmptyDirs tests that directories emptied by removals are pruned
+// bottom-up while non-empty directories and the target root remain
+func TestPruneEmptyDirs(t *testing.T) {
+	_, targetDir := setupSourceTarget(t)
+
+	// Layout:
+	// target/
+	//   ├── top.conf -> /somewhere/top.conf
+	//   ├── apps/
+	//   │   ├── other.conf -> /somewhere/other.conf (kept)
+	//   │   └── web/
+	//   │       └── site.conf -> /somewhere/site.conf
+	//   └── timers/
+	//       └── daily/
+	//           └── backup.timer -> /somewhere/backup.timer
+	names := []string{
+		"top.conf",
+		filepath.Join("apps", "other.conf"),
+		filepath.Join("apps", "web", "site.conf"),
+		filepath.Join("timers", "daily", "backup.timer"),
+	}
+	for _, name := range names {
+		linkPath := filepath.Join(targetDir, name)
+		if err := os.MkdirAll(filepath.Dir(linkPath), 0755); err != nil {
+			t.Fatalf("Failed to create dir for %s: %v", name, err)
+		}
+		if err := os.Symlink("/somewhere/"+filepath.Base(name), linkPath); err != nil {
+			t.Fatalf("Failed to create symlink %s: %v", name, err)
+		}
+	}
+
+	removed := []string{
+		"top.conf",
+		filepath.Join("apps", "web", "site.conf"),
+		filepath.Join("timers", "daily", "backup.timer"),
+	}
+	for _, name := range removed {
+		if err := RemoveSymlink(targetDir, name); err != nil {
+			t.Fatalf("RemoveSymlink(%s) failed: %v", name, err)
+		}
+	}
+
+	if err := PruneEmptyDirs(targetDir, removed); err != nil {
+		t.Fatalf("PruneEmptyDirs failed: %v", err)
+	}
+
+	for _, dir := range []string{filepath.Join("apps", "web"), filepath.Join("timers", "daily"), "timers"} {
+		if _, err := os.Lstat(filepath.Join(targetDir, dir)); !os.IsNotExist(err) {
+			t.Errorf("Empty directory %s should have been pruned", dir)
+		}
+	}
+
+	if _, err := os.Lstat(filepath.Join(targetDir, "apps", "other.conf")); err != nil {
+		t.Error("Non-empty directory apps should remain with its symlink")
+	}
+
+	if _, err := os.Stat(targetDir); err != nil {
+		t.Error("Target directory must never be removed")
+	}
+}
+
+// TestApplyChangesWithOptions_RemovableAllowlist tests that removals outside
+// the allowlist are skipped while listed removals and creations proceed
+func TestApplyChangesWithOptions_RemovableAllowlist(t *testing.T) {
+	sourceDir, targetDir := setupSourceTarget(t, "listed.conf", "unlisted.conf", "new.conf")
+
+	for _, f := range []string{"listed.conf", "unlisted.conf"} {
+		if err := CreateSymlink(sourceDir, targetDir, f); err != nil {
+			t.Fatalf("Failed to create initial symlink for %s: %v", f, err)
+		}
+	}
+
+	var warnings []string
+	opts := ApplyOptions{
+		Options: Options{
+			Warnf: func(format string, args ...any) {
+				warnings = append(warnings, fmt.Sprintf(format, args...))
+			},
+		},
+		RemovableAllowlist: map[string]bool{"listed.conf": true},
+	}
+
+	// Deselect both existing links and select a new one
+	result, err := ApplyChangesWithOptions(sourceDir, targetDir, []string{"new.conf"}, opts)
+	if err != nil {
+		t.Fatalf("ApplyChangesWithOptions failed: %v", err)
+	}
+	if want := []string{"unlisted.conf"}; !reflect.DeepEqual(result.Refused, want) || len(result.Unchanged) != 0 {
+		t.Errorf("Refused = %v, Unchanged = %v, want %v refused only", result.Refused, result.Unchanged, want)
+	}
+
+	if _, err := os.Lstat(filepath.Join(targetDir, "listed.conf")); !os.IsNotExist(err) {
+		t.Error("listed.conf symlink should have been removed")
+	}
+	if _, err := os.Lstat(filepath.Join(targetDir, "unlisted.conf")); err != nil {
+		t.Error("unlisted.conf symlink should have been kept")
+	}
+	if _, err := os.Lstat(filepath.Join(targetDir, "new.conf")); err != nil {
+		t.Error("new.conf symlink should have been created")
+	}
+
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "unlisted.conf") {
+		t.Errorf("Expected one warning about unlisted.conf, got %v", warnings)
+	}
+}
+
+// TestApplyChangesWithOptions_RemovableAllowlistStrict tests that unlisted
+// removals are errors in strict mode, raised before any change is made
+func TestApplyChangesWithOptions_RemovableAllowlistStrict(t *testing.T) {
+	sourceDir, targetDir := setupSourceTarget(t, "listed.conf", "unlisted.conf", "new.conf")
+
+	for _, f := range []string{"listed.conf", "unlisted.conf"} {
+		if err := CreateSymlink(sourceDir, targetDir, f); err != nil {
+			t.Fatalf("Failed to create initial symlink for %s: %v", f, err)
+		}
+	}
+
+	var logs []string
+	opts := ApplyOptions{
+		RemovableAllowlist: map[string]bool{"listed.conf": true},
+		Strict:             true,
+		Logf: func(format string, args ...any) {
+			logs = append(logs, fmt.Sprintf(format, args...))
+		},
+	}
+	_, err := ApplyChangesWithOptions(sourceDir, targetDir, []string{"new.conf"}, opts)
+	if err == nil || !strings.Contains(err.Error(), "unlisted.conf") {
+		t.Errorf("Expected strict error about unlisted.conf, got %v", err)
+	}
+	if len(logs) != 0 {
+		t.Errorf("Expected no operation before the error, got %v", logs)
+	}
+
+	for _, f := range []string{"listed.conf", "unlisted.conf"} {
+		if _, err := os.Lstat(filepath.Join(targetDir, f)); err != nil {
+			t.Errorf("%s symlink should have been kept", f)
+		}
+	}
+	if _, err := os.Lstat(filepath.Join(targetDir, "new.conf")); !os.IsNotExist(err) {
+		t.Error("new.conf symlink should not have been created")
+	}
+}
+
+// TestPlanChanges tests computing the change set without touching the filesystem
+func TestPlanChanges(t *testing.T) {
+	sourceDir, targetDir := setupSourceTarget(t, "keep.conf", "remove.conf", "add.conf")
+
+	for _, f := range []string{"keep.conf", "remove.conf"} {
+		if err := CreateSymlink(sourceDir, targetDir, f); err != nil {
+			t.Fatalf("Failed to create initial symlink for %s: %v", f, err)
+		}
+	}
+
+	changes, err := PlanChanges(sourceDir, targetDir, []string{"keep.conf", "add.conf"}, Options{})
+	if err != nil {
+		t.Fatalf("PlanChanges failed: %v", err)
+	}
+
+	if !reflect.DeepEqual(changes.Create, []string{"add.conf"}) {
+		t.Errorf("Create = %v, want [add.conf]", changes.Create)
+	}
+	if !reflect.DeepEqual(changes.Remove, []string{"remove.conf"}) {
+		t.Errorf("Remove = %v, want [remove.conf]", changes.Remove)
+	}
+	if !changes.HasChanges() {
+		t.Error("HasChanges() should be true")
+	}
+
+	// Nothing must have been touched
+	if _, err := os.Lstat(filepath.Join(targetDir, "remove.conf")); err != nil {
+		t.Error("remove.conf symlink should still exist")
+	}
+	if _, err := os.Lstat(filepath.Join(targetDir, "add.conf")); !os.IsNotExist(err) {
+		t.Error("add.conf symlink should not have been created")
+	}
+
+	// A matching selection plans nothing
+	changes, err = PlanChanges(sourceDir, targetDir, []string{"keep.conf", "remove.conf"}, Options{})
+	if err != nil {
+		t.Fatalf("PlanChanges failed: %v", err)
+	}
+	if changes.HasChanges() {
+		t.Errorf("Expected no changes, got %+v", changes)
+	}
+}
+
+// TestApplyChangesWithOptions_ContinueOnError tests that a failing operation
+// does not stop the remaining ones and that all errors are reported
+func TestApplyChangesWithOptions_ContinueOnError(t *testing.T) {
+	sourceDir, targetDir := setupSourceTarget(t, "a.conf", "b.conf", "blocked.conf", "c.conf")
+
+	// A non-empty directory in the target cannot be replaced by a symlink
+	blocked := filepath.Join(targetDir, "blocked.conf")
+	if err := os.MkdirAll(filepath.Join(blocked, "keep"), 0755); err != nil {
+		t.Fatalf("Failed to create blocking directory: %v", err)
+	}
+
+	selected := []string{"a.conf", "b.conf", "blocked.conf", "c.conf"}
+	opts := ApplyOptions{ContinueOnError: true}
+	result, err := ApplyChangesWithOptions(sourceDir, targetDir, selected, opts)
+	if err == nil || !strings.Contains(err.Error(), "blocked.conf") {
+		t.Fatalf("Expected aggregated error about blocked.conf, got %v", err)
+	}
+
+	if !reflect.DeepEqual(result.Created, []string{"a.conf", "b.conf", "c.conf"}) {
+		t.Errorf("Created = %v, want [a.conf b.conf c.conf]", result.Created)
+	}
+	if !reflect.DeepEqual(result.Failed, []string{"blocked.conf"}) {
+		t.Errorf("Failed = %v, want [blocked.conf]", result.Failed)
+	}
+
+	// Without ContinueOnError the first failure stops processing
+	os.Remove(filepath.Join(targetDir, "c.conf"))
+	result, err = ApplyChangesWithOptions(sourceDir, targetDir, selected, ApplyOptions{})
+	if err == nil {
+		t.Fatal("Expected error without ContinueOnError")
+	}
+	if len(result.Created) != 0 {
+		t.Errorf("Created = %v, want none after early failure", result.Created)
+	}
+}
+
+// TestApplyChangesWithOptions_RollbackOnError tests that a failure midway
+// restores the target to its state before the apply
+func TestApplyChangesWithOptions_RollbackOnError(t *testing.T) {
+	sourceDir, targetDir := setupSourceTarget(t, "keep.conf", "old.conf", "a.conf", "blocked.conf", "z.conf")
+
+	for _, f := range []string{"keep.conf", "old.conf"} {
+		if err := CreateSymlink(sourceDir, targetDir, f); err != nil {
+			t.Fatalf("Failed to create initial symlink: %v", err)
+		}
+	}
+	// A foreign symlink is replaced by the new link and must come back
+	if err := os.Symlink("/elsewhere/a.conf", filepath.Join(targetDir, "a.conf")); err != nil {
+		t.Fatalf("Failed to create foreign symlink: %v", err)
+	}
+	// A non-empty directory in the target cannot be replaced by a symlink
+	if err := os.MkdirAll(filepath.Join(targetDir, "blocked.conf", "keep"), 0755); err != nil {
+		t.Fatalf("Failed to create blocking directory: %v", err)
+	}
+
+	snapshot := func() map[string]string {
+		links, err := ListEnabledSymlinks(sourceDir, targetDir)
+		if err != nil {
+			t.Fatalf("ListEnabledSymlinks failed: %v", err)
+		}
+		return links
+	}
+	before := snapshot()
+
+	selected := []string{"keep.conf", "a.conf", "blocked.conf", "z.conf"}
+	result, err := ApplyChangesWithOptions(sourceDir, targetDir, selected, ApplyOptions{})
+	if err == nil || !strings.Contains(err.Error(), "rolled back") {
+		t.Fatalf("Expected rolled back error, got %v", err)
+	}
+	if len(result.Created) != 0 || len(result.Removed) != 0 {
+		t.Errorf("result = %+v, want no changes after rollback", result)
+	}
+
+	if after := snapshot(); !reflect.DeepEqual(after, before) {
+		t.Errorf("symlinks after rollback = %v, want %v", after, before)
+	}
+	if _, err := os.Stat(filepath.Join(targetDir, "blocked.conf", "keep")); err != nil {
+		t.Errorf("blocking directory was touched: %v", err)
+	}
+}
+
+// TestApplyChangesWithOptions_Additive tests that additive mode keeps all
+// existing links and only creates newly selected ones
+func TestApplyChangesWithOptions_Additive(t *testing.T) {
+	sourceDir, targetDir := setupSourceTarget(t, "old1.conf", "old2.conf", "new.conf", "other.conf")
+
+	for _, f := range []string{"old1.conf", "old2.conf"} {
+		if err := CreateSymlink(sourceDir, targetDir, f); err != nil {
+			t.Fatalf("Failed to create initial symlink: %v", err)
+		}
+	}
+
+	// old2 is selected again, old1 is not selected at all
+	result, err := ApplyChangesWithOptions(sourceDir, targetDir, []string{"old2.conf", "new.conf"}, ApplyOptions{Additive: true})
+	if err != nil {
+		t.Fatalf("ApplyChangesWithOptions failed: %v", err)
+	}
+
+	if len(result.Removed) != 0 {
+		t.Errorf("Removed = %v, want none", result.Removed)
+	}
+	if !reflect.DeepEqual(result.Created, []string{"new.conf"}) {
+		t.Errorf("Created = %v, want [new.conf]", result.Created)
+	}
+
+	enabled, err := GetEnabledFiles(sourceDir, targetDir)
+	if err != nil {
+		t.Fatalf("GetEnabledFiles failed: %v", err)
+	}
+	sort.Strings(enabled)
+	want := []string{"new.conf", "old1.conf", "old2.conf"}
+	if !reflect.DeepEqual(enabled, want) {
+		t.Errorf("enabled = %v, want %v", enabled, want)
+	}
+}
+
+// TestApplyChangesWithOptions_VerifyAfter tests that dangling symlinks in the
+// target are reported after applying, and fail the apply in strict mode
+func TestApplyChangesWithOptions_VerifyAfter(t *testing.T) {
+	sourceDir, targetDir := setupSourceTarget(t, "a.conf")
+
+	// A link into the source whose file does not exist
+	if err := os.Symlink(filepath.Join(sourceDir, "gone.conf"), filepath.Join(targetDir, "alias.conf")); err != nil {
+		t.Fatalf("Failed to create dangling symlink: %v", err)
+	}
+
+	var warnings []string
+	opts := ApplyOptions{
+		Options: Options{
+			Warnf: func(format string, args ...any) {
+				warnings = append(warnings, fmt.Sprintf(format, args...))
+			},
+		},
+		VerifyAfter: true,
+	}
+	if _, err := ApplyChangesWithOptions(sourceDir, targetDir, []string{"a.conf"}, opts); err != nil {
+		t.Fatalf("ApplyChangesWithOptions failed: %v", err)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "alias.conf") {
+		t.Errorf("Expected one warning about alias.conf, got %v", warnings)
+	}
+
+	opts.Strict = true
+	_, err := ApplyChangesWithOptions(sourceDir, targetDir, []string{"a.conf"}, opts)
+	if err == nil || !strings.Contains(err.Error(), "alias.conf") {
+		t.Errorf("Expected strict error about alias.conf, got %v", err)
+	}
+}
+
+// TestApplyChangesWithOptions_OnlyChanged tests that only links whose source
+// is newer than the link are recreated
+func TestApplyChangesWithOptions_OnlyChanged(t *testing.T) {
+	sourceDir, targetDir := setupSourceTarget(t, "newer.conf", "older.conf", "fresh.conf")
+
+	for _, f := range []string{"newer.conf", "older.conf"} {
+		if err := CreateSymlink(sourceDir, targetDir, f); err != nil {
+			t.Fatalf("Failed to create initial symlink: %v", err)
+		}
+	}
+
+	// Links were just created; move the sources around them in time
+	now := time.Now()
+	if err := os.Chtimes(filepath.Join(sourceDir, "newer.conf"), now, now.Add(time.Hour)); err != nil {
+		t.Fatalf("Failed to set mtime: %v", err)
+	}
+	if err := os.Chtimes(filepath.Join(sourceDir, "older.conf"), now, now.Add(-time.Hour)); err != nil {
+		t.Fatalf("Failed to set mtime: %v", err)
+	}
+
+	selected := []string{"newer.conf", "older.conf", "fresh.conf"}
+	result, err := ApplyChangesWithOptions(sourceDir, targetDir, selected, ApplyOptions{OnlyChanged: true})
+	if err != nil {
+		t.Fatalf("ApplyChangesWithOptions failed: %v", err)
+	}
+
+	if !reflect.DeepEqual(result.Relinked, []string{"newer.conf"}) {
+		t.Errorf("Relinked = %v, want [newer.conf]", result.Relinked)
+	}
+	if !reflect.DeepEqual(result.Created, []string{"fresh.conf"}) {
+		t.Errorf("Created = %v, want [fresh.conf]", result.Created)
+	}
+
+	enabled, err := GetEnabledFiles(sourceDir, targetDir)
+	if err != nil {
+		t.Fatalf("GetEnabledFiles failed: %v", err)
+	}
+	if len(enabled) != 3 {
+		t.Errorf("enabled = %v, want all three files linked", enabled)
+	}
+}
+
+// TestFindCaseCollisions tests detection of names differing only by case
+func TestFindCaseCollisions(t *testing.T) {
+	tests := []struct {
+		name  string
+		names []string
+		want  [][]string
+	}{
+		{
+			name:  "no collisions",
+			names: []string{"app.conf", "web.conf"},
+			want:  nil,
+		},
+		{
+			name:  "single collision",
+			names: []string{"App.conf", "web.conf", "app.conf"},
+			want:  [][]string{{"App.conf", "app.conf"}},
+		},
+		{
+			name:  "multiple groups",
+			names: []string{"a.conf", "B.conf", "A.CONF", "b.conf", "a.Conf"},
+			want:  [][]string{{"a.conf", "A.CONF", "a.Conf"}, {"B.conf", "b.conf"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FindCaseCollisions(tt.names); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FindCaseCollisions() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestApplyChangesWithOptions_CaseCollisionStrict tests that case collisions
+// abort the apply in strict mode before anything is linked
+func TestApplyChangesWithOptions_CaseCollisionStrict(t *testing.T) {
+	sourceDir, targetDir := setupSourceTarget(t, "App.conf", "app.conf")
+
+	_, err := ApplyChangesWithOptions(sourceDir, targetDir, []string{"App.conf", "app.conf"}, ApplyOptions{Strict: true})
+	if err == nil || !strings.Contains(err.Error(), "App.conf, app.conf") {
+		t.Fatalf("Expected case collision error, got %v", err)
+	}
+
+	enabled, err := GetEnabledFiles(sourceDir, targetDir)
+	if err != nil {
+		t.Fatalf("GetEnabledFiles failed: %v", err)
+	}
+	if len(enabled) != 0 {
+		t.Errorf("enabled = %v, want nothing linked", enabled)
+	}
+}
+
+// TestApplyChangesWithOptions_Logf tests that each operation is logged, and
+// none in a dry run
+func TestApplyChangesWithOptions_Logf(t *testing.T) {
+	sourceDir, targetDir := setupSourceTarget(t, "keep.conf", "old.conf", "new.conf")
+
+	for _, f := range []string{"keep.conf", "old.conf"} {
+		if err := CreateSymlink(sourceDir, targetDir, f); err != nil {
+			t.Fatalf("Failed to create initial symlink: %v", err)
+		}
+	}
+
+	var logged []string
+	opts := ApplyOptions{Logf: func(format string, args ...any) {
+		logged = append(logged, fmt.Sprintf(format, args...))
+	}}
+	if _, err := ApplyChangesWithOptions(sourceDir, targetDir, []string{"keep.conf", "new.conf"}, opts); err != nil {
+		t.Fatalf("ApplyChangesWithOptions failed: %v", err)
+	}
+
+	want := []string{"unlinked old.conf", "linked new.conf", "skipped keep.conf (already linked)"}
+	if !reflect.DeepEqual(logged, want) {
+		t.Errorf("logged = %v, want %v", logged, want)
+	}
+
+	// Dry runs do not report operations that did not happen
+	logged = nil
+	opts.DryRun = true
+	if _, err := ApplyChangesWithOptions(sourceDir, targetDir, []string{"old.conf"}, opts); err != nil {
+		t.Fatalf("ApplyChangesWithOptions failed: %v", err)
+	}
+	if len(logged) != 0 {
+		t.Errorf("logged = %v, want nothing for a dry run", logged)
+	}
+}
+
+// TestApplyChangesWithOptions_DryRun tests that a dry run reports the planned
+// operations without creating or removing symlinks
+func TestApplyChangesWithOptions_DryRun(t *testing.T) {
+	sourceDir, targetDir := setupSourceTarget(t, "keep.conf", "old.conf", "new.conf")
+
+	for _, f := range []string{"keep.conf", "old.conf"} {
+		if err := CreateSymlink(sourceDir, targetDir, f); err != nil {
+			t.Fatalf("Failed to create initial symlink: %v", err)
+		}
+	}
+
+	result, err := ApplyChangesWithOptions(sourceDir, targetDir, []string{"keep.conf", "new.conf"}, ApplyOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("ApplyChangesWithOptions failed: %v", err)
+	}
+
+	if !reflect.DeepEqual(result.Created, []string{"new.conf"}) {
+		t.Errorf("Created = %v, want [new.conf]", result.Created)
+	}
+	if !reflect.DeepEqual(result.Removed, []string{"old.conf"}) {
+		t.Errorf("Removed = %v, want [old.conf]", result.Removed)
+	}
+
+	enabled, err := GetEnabledFiles(sourceDir, targetDir)
+	if err != nil {
+		t.Fatalf("GetEnabledFiles failed: %v", err)
+	}
+	sort.Strings(enabled)
+	if !reflect.DeepEqual(enabled, []string{"keep.conf", "old.conf"}) {
+		t.Errorf("enabled = %v, want target unchanged", enabled)
+	}
+}
+
+// TestDirs_RoundTrip tests linking a source directory as a whole
+func TestDirs_RoundTrip(t *testing.T) {
+	sourceDir, targetDir := setupSourceTarget(t, "a.conf", filepath.Join("plugin", "init.lua"))
+	opts := Options{Dirs: true}
+
+	available, err := ListAvailableFilesWithOptions(sourceDir, opts)
+	if err != nil {
+		t.Fatalf("ListAvailableFilesWithOptions failed: %v", err)
+	}
+	if want := []string{"a.conf", "plugin"}; !reflect.DeepEqual(available, want) {
+		t.Errorf("available = %v, want %v", available, want)
+	}
+	if files, _ := ListAvailableFiles(sourceDir); !reflect.DeepEqual(files, []string{"a.conf"}) {
+		t.Errorf("ListAvailableFiles() = %v, want directories skipped by default", files)
+	}
+
+	if _, err := ApplyChangesWithOptions(sourceDir, targetDir, []string{"plugin"}, ApplyOptions{Options: opts}); err != nil {
+		t.Fatalf("ApplyChangesWithOptions failed: %v", err)
+	}
+	info, err := os.Lstat(filepath.Join(targetDir, "plugin"))
+	if err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Fatalf("plugin is not a symlink: %v, %v", info, err)
+	}
+	if _, err := os.Stat(filepath.Join(targetDir, "plugin", "init.lua")); err != nil {
+		t.Errorf("cannot reach file through directory link: %v", err)
+	}
+
+	enabled, err := GetEnabledFilesWithOptions(sourceDir, targetDir, opts)
+	if err != nil || !reflect.DeepEqual(enabled, []string{"plugin"}) {
+		t.Errorf("enabled = %v, %v, want [plugin]", enabled, err)
+	}
+	orphaned, err := ValidateSymlinksWithOptions(sourceDir, targetDir, opts)
+	if err != nil || len(orphaned) != 0 {
+		t.Errorf("orphaned = %v, %v, want none", orphaned, err)
+	}
+
+	// Disabling removes only the link, never the directory contents
+	if _, err := ApplyChangesWithOptions(sourceDir, targetDir, nil, ApplyOptions{Options: opts}); err != nil {
+		t.Fatalf("ApplyChangesWithOptions failed: %v", err)
+	}
+	if _, err := os.Lstat(filepath.Join(targetDir, "plugin")); !os.IsNotExist(err) {
+		t.Errorf("plugin link still exists: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(sourceDir, "plugin", "init.lua")); err != nil {
+		t.Errorf("source directory contents were touched: %v", err)
+	}
+}
+
+// TestFindStaleSymlinks tests finding links into the source whose name left
+// it, leaving links elsewhere and aliases alone
+func TestFindStaleSymlinks(t *testing.T) {
+	sourceDir, targetDir := setupSourceTarget(t, "kept.conf")
+	oldDir := filepath.Join(filepath.Dir(sourceDir), "old")
+	if err := os.Mkdir(oldDir, 0755); err != nil {
+		t.Fatalf("Failed to create old dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(oldDir, "moved.conf"), []byte("test"), 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+
+	links := map[string]string{
+		"kept.conf":    filepath.Join(sourceDir, "kept.conf"),
+		"moved.conf":   filepath.Join(oldDir, "moved.conf"),      // Outside the source
+		"deleted.conf": filepath.Join(sourceDir, "deleted.conf"), // Into the source, gone
+		"gone.conf":    filepath.Join(oldDir, "gone.conf"),       // Broken, outside the source
+		"alias.conf":   filepath.Join(sourceDir, "kept.conf"),    // The user's own alias
+	}
+	for name, target := range links {
+		if err := os.Symlink(target, filepath.Join(targetDir, name)); err != nil {
+			t.Fatalf("Failed to create symlink %s: %v", name, err)
+		}
+	}
+
+	stale, err := FindStaleSymlinks(sourceDir, targetDir)
+	if err != nil {
+		t.Fatalf("FindStaleSymlinks failed: %v", err)
+	}
+	if want := []string{"deleted.conf"}; !reflect.DeepEqual(stale, want) {
+		t.Errorf("FindStaleSymlinks() = %v, want %v", stale, want)
+	}
+
+	// Filtered-out names are not lnka's to clean
+	stale, err = FindStaleSymlinksWithOptions(sourceDir, targetDir, Options{Exclude: []string{"deleted.*"}})
+	if err != nil {
+		t.Fatalf("FindStaleSymlinksWithOptions failed: %v", err)
+	}
+	if len(stale) != 0 {
+		t.Errorf("FindStaleSymlinksWithOptions() = %v, want none", stale)
+	}
+}
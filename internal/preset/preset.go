@@ -0,0 +1,85 @@
+// Package preset stores named selections as JSON files so they can be
+// reloaded in later runs (e.g. with --preset).
+package preset
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Dir returns the directory holding preset files
+// (e.g. ~/.config/lnka/presets on Linux)
+func Dir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate config directory: %w", err)
+	}
+	return filepath.Join(dir, "lnka", "presets"), nil
+}
+
+// SavePreset stores the selected files under the given preset name,
+// replacing an existing preset of the same name
+func SavePreset(name string, files []string) error {
+	path, err := presetPath(name)
+	if err != nil {
+		return err
+	}
+
+	if files == nil {
+		files = []string{}
+	}
+	data, err := json.MarshalIndent(files, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode preset: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create preset directory: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write preset %q: %w", name, err)
+	}
+	return nil
+}
+
+// LoadPreset reads the files stored under the given preset name
+func LoadPreset(name string) ([]string, error) {
+	path, err := presetPath(name)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("preset %q not found in %s", name, filepath.Dir(path))
+		}
+		return nil, fmt.Errorf("failed to read preset %q: %w", name, err)
+	}
+
+	var files []string
+	if err := json.Unmarshal(data, &files); err != nil {
+		return nil, fmt.Errorf("failed to parse preset %q: %w", name, err)
+	}
+	if files == nil {
+		files = []string{}
+	}
+	return files, nil
+}
+
+// presetPath returns the file path of the named preset
+func presetPath(name string) (string, error) {
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+		return "", fmt.Errorf("invalid preset name %q", name)
+	}
+
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name+".json"), nil
+}
@@ -0,0 +1,57 @@
+package preset
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/marco-arnold/lnka/internal/testutil"
+)
+
+func TestSaveAndLoadPreset(t *testing.T) {
+	testutil.UseTempConfigDir(t)
+
+	files := []string{"nginx.conf", "redis.conf"}
+	if err := SavePreset("web", files); err != nil {
+		t.Fatalf("SavePreset failed: %v", err)
+	}
+
+	got, err := LoadPreset("web")
+	if err != nil {
+		t.Fatalf("LoadPreset failed: %v", err)
+	}
+	if !reflect.DeepEqual(got, files) {
+		t.Errorf("LoadPreset() = %v, want %v", got, files)
+	}
+
+	// Saving again replaces the preset
+	if err := SavePreset("web", nil); err != nil {
+		t.Fatalf("SavePreset failed: %v", err)
+	}
+	got, err = LoadPreset("web")
+	if err != nil {
+		t.Fatalf("LoadPreset failed: %v", err)
+	}
+	if len(got) != 0 {
+		t.Errorf("LoadPreset() = %v, want empty preset", got)
+	}
+}
+
+func TestLoadPreset_NotFound(t *testing.T) {
+	testutil.UseTempConfigDir(t)
+
+	_, err := LoadPreset("missing")
+	if err == nil || !strings.Contains(err.Error(), `preset "missing" not found`) {
+		t.Errorf("LoadPreset() error = %v, want not found error", err)
+	}
+}
+
+func TestPresetPath_InvalidName(t *testing.T) {
+	testutil.UseTempConfigDir(t)
+
+	for _, name := range []string{"", ".", "..", "../escape", `a\b`} {
+		if err := SavePreset(name, nil); err == nil {
+			t.Errorf("SavePreset(%q) expected error", name)
+		}
+	}
+}
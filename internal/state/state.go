@@ -0,0 +1,90 @@
+// Package state remembers per-target UI state between runs, like the file
+// the cursor was on when the list was closed.
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// targetState is the remembered state of one target directory
+type targetState struct {
+	Cursor string `json:"cursor,omitempty"` // File under the cursor on exit
+}
+
+// Path returns the path of the state file
+// (e.g. ~/.config/lnka/state.json on Linux)
+func Path() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate config directory: %w", err)
+	}
+	return filepath.Join(dir, "lnka", "state.json"), nil
+}
+
+// LoadCursor returns the file the cursor was on when the list for the target
+// directory was last closed ("" = none remembered)
+func LoadCursor(targetDir string) (string, error) {
+	states, _, key, err := readStates(targetDir)
+	if err != nil {
+		return "", err
+	}
+	return states[key].Cursor, nil
+}
+
+// SaveCursor remembers the file under the cursor for the target directory.
+// The state of other targets is kept.
+func SaveCursor(targetDir, name string) error {
+	states, path, key, err := readStates(targetDir)
+	if err != nil {
+		return err
+	}
+	s := states[key]
+	s.Cursor = name
+	states[key] = s
+
+	data, err := json.MarshalIndent(states, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode state: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	return nil
+}
+
+// readStates reads the state file and returns it with its path and the key
+// of the target directory (its absolute path). A missing file is empty.
+func readStates(targetDir string) (map[string]targetState, string, string, error) {
+	path, err := Path()
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	key, err := filepath.Abs(targetDir)
+	if err != nil {
+		return nil, "", "", fmt.Errorf("failed to resolve target directory: %w", err)
+	}
+
+	states := make(map[string]targetState)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return states, path, key, nil
+		}
+		return nil, "", "", fmt.Errorf("failed to read state file: %w", err)
+	}
+	if err := json.Unmarshal(data, &states); err != nil {
+		return nil, "", "", fmt.Errorf("failed to parse state file %s: %w", path, err)
+	}
+	if states == nil {
+		states = make(map[string]targetState) // "null" state file
+	}
+	return states, path, key, nil
+}
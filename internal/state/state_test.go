@@ -0,0 +1,34 @@
+package state
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/marco-arnold/lnka/internal/testutil"
+)
+
+func TestSaveAndLoadCursor(t *testing.T) {
+	testutil.UseTempConfigDir(t)
+	base := t.TempDir()
+	targetA := filepath.Join(base, "a")
+	targetB := filepath.Join(base, "b")
+
+	if cursor, err := LoadCursor(targetA); err != nil || cursor != "" {
+		t.Fatalf("LoadCursor() = %q, %v, want nothing remembered", cursor, err)
+	}
+
+	if err := SaveCursor(targetA, "nginx.conf"); err != nil {
+		t.Fatalf("SaveCursor failed: %v", err)
+	}
+	if err := SaveCursor(targetB, "redis.conf"); err != nil {
+		t.Fatalf("SaveCursor failed: %v", err)
+	}
+
+	// Each target keeps its own cursor, relative paths resolve to the same key
+	if cursor, err := LoadCursor(targetA); err != nil || cursor != "nginx.conf" {
+		t.Errorf("LoadCursor(a) = %q, %v, want nginx.conf", cursor, err)
+	}
+	if cursor, err := LoadCursor(filepath.Join(targetB, "..", "b")); err != nil || cursor != "redis.conf" {
+		t.Errorf("LoadCursor(b) = %q, %v, want redis.conf", cursor, err)
+	}
+}
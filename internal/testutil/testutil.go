@@ -0,0 +1,15 @@
+// Package testutil provides helpers shared by the tests of several packages.
+package testutil
+
+import "testing"
+
+// UseTempConfigDir points the user config dir of all platforms at a fresh
+// temp dir for the duration of the test, so presets, the cursor state and
+// the undo journal of the user are never read or written
+func UseTempConfigDir(t testing.TB) {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir) // Linux/BSD
+	t.Setenv("HOME", dir)            // macOS (~/Library/Application Support)
+	t.Setenv("AppData", dir)         // Windows
+}
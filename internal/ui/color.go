@@ -0,0 +1,16 @@
+package ui
+
+import (
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// usePlainProfile disables all colors and text attributes lipgloss renders
+// when disabled is set (see config.Theme.NoColor). Lipgloss keeps one color
+// profile per process, so the list and the dialogs set it from the theme
+// they are shown with. Selection states are then shown with text markers only.
+func usePlainProfile(disabled bool) {
+	if disabled {
+		lipgloss.SetColorProfile(termenv.Ascii)
+	}
+}
@@ -1,6 +1,16 @@
 package ui
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sync/atomic"
+	"time"
+
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/marco-arnold/lnka/internal/filesystem"
 )
@@ -8,11 +18,22 @@ import (
 // loadFilesCmd creates a command that asynchronously loads both
 // available files and enabled files. This ensures both operations
 // complete before returning a single message.
+// With extraTargets their enabled files are added to the enabled files, and
+// files linked in only some of the targets are reported as partial.
+// Files linked from one of the alsoCheck directories are reported with those
+// links (informational, they don't count as enabled).
+// Loading gives up with an error when ctx is canceled or takes longer than
+// timeout (0 = no limit), e.g. on a hanging network mount.
+// The number of source files scanned so far is counted in scanned (nil = not
+// counted) for the progress shown while loading.
 // Returns filesLoadedMsg when complete.
-func loadFilesCmd(sourceDir, targetDir string) tea.Cmd {
+func loadFilesCmd(ctx context.Context, sourceDir, targetDir string, extraTargets, alsoCheck []string, opts filesystem.Options, timeout time.Duration, scanned *atomic.Int64) tea.Cmd {
 	return func() tea.Msg {
+		ctx, cancel := filesystem.TimeoutContext(ctx, timeout)
+		defer cancel()
+
 		// Load available files
-		availableFiles, err := filesystem.ListAvailableFiles(sourceDir)
+		availableFiles, err := filesystem.ListAvailableFilesContext(ctx, sourceDir, opts)
 		if err != nil {
 			return filesLoadedMsg{
 				availableFiles: nil,
@@ -21,8 +42,19 @@ func loadFilesCmd(sourceDir, targetDir string) tea.Cmd {
 			}
 		}
 
-		// Load enabled files
-		enabledFiles, err := filesystem.GetEnabledFiles(sourceDir, targetDir)
+		// Load enabled files and current link targets (shown with the
+		// target detail toggle) with a single scan of the target
+		state, err := filesystem.ReadTargetStateContext(ctx, sourceDir, targetDir, opts)
+		if err != nil {
+			return filesLoadedMsg{
+				availableFiles: availableFiles,
+				enabledFiles:   nil,
+				err:            err,
+			}
+		}
+
+		// Merge the enabled files of further targets
+		enabled, partial, err := mergeTargets(ctx, sourceDir, state.Enabled, extraTargets, opts)
 		if err != nil {
 			return filesLoadedMsg{
 				availableFiles: availableFiles,
@@ -31,10 +63,139 @@ func loadFilesCmd(sourceDir, targetDir string) tea.Cmd {
 			}
 		}
 
+		// Look for links to the source files in the other known directories
+		external, err := findExternalLinks(sourceDir, availableFiles, alsoCheck)
+		if err != nil {
+			return filesLoadedMsg{
+				availableFiles: availableFiles,
+				enabledFiles:   nil,
+				err:            err,
+			}
+		}
+
+		// Stat source files for the size and age columns
+		// (files that cannot be stat'ed are shown with "?")
+		stats := make(map[string]fileStat, len(availableFiles))
+		for _, name := range availableFiles {
+			if scanned != nil {
+				scanned.Add(1)
+			}
+			info, err := os.Stat(filepath.Join(sourceDir, name))
+			if err != nil {
+				continue
+			}
+			stats[name] = fileStat{size: info.Size(), modTime: info.ModTime()}
+		}
+
 		return filesLoadedMsg{
 			availableFiles: availableFiles,
-			enabledFiles:   enabledFiles,
+			enabledFiles:   enabled,
+			targets:        state.Links,
+			partial:        partial,
+			external:       external,
+			renamed:        state.Renamed,
+			stats:          stats,
 			err:            nil,
 		}
 	}
 }
+
+// mergeTargets adds the enabled files of extraTargets to the enabled files of
+// the first target, keeping their order, and maps the files linked in only
+// some of the targets to a label like "2/3 targets"
+func mergeTargets(ctx context.Context, sourceDir string, enabled, extraTargets []string, opts filesystem.Options) ([]string, map[string]string, error) {
+	if len(extraTargets) == 0 {
+		return enabled, nil, nil
+	}
+
+	counts := make(map[string]int, len(enabled))
+	merged := append([]string(nil), enabled...)
+	for _, name := range enabled {
+		counts[name] = 1
+	}
+	for _, dir := range extraTargets {
+		state, err := filesystem.ReadTargetStateContext(ctx, sourceDir, dir, opts)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, name := range state.Enabled {
+			if counts[name] == 0 {
+				merged = append(merged, name)
+			}
+			counts[name]++
+		}
+	}
+
+	total := len(extraTargets) + 1
+	partial := make(map[string]string)
+	for name, count := range counts {
+		if count < total {
+			partial[name] = fmt.Sprintf("%d/%d targets", count, total)
+		}
+	}
+	return merged, partial, nil
+}
+
+// findExternalLinks maps the source files linked from one of the given
+// directories to the paths of those links
+func findExternalLinks(sourceDir string, files, dirs []string) (map[string][]string, error) {
+	if len(dirs) == 0 {
+		return nil, nil
+	}
+
+	// Each directory is read once, then every file is a lookup
+	links, err := filesystem.MapExternalLinks(dirs)
+	if err != nil {
+		return nil, err
+	}
+
+	external := make(map[string][]string)
+	for _, name := range files {
+		absSource, err := filepath.Abs(filepath.Join(sourceDir, name))
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve source file: %w", err)
+		}
+		realSource, err := filepath.EvalSymlinks(absSource)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve source file: %w", err)
+		}
+		if found := links[realSource]; len(found) > 0 {
+			external[name] = found
+		}
+	}
+	return external, nil
+}
+
+// openInFileManagerCmd creates a command that reveals the target link of the
+// given file in the platform's file manager. The opener is started detached,
+// so the UI keeps running. Returns openResultMsg when the opener was started.
+func openInFileManagerCmd(targetDir, fileName string) tea.Cmd {
+	return func() tea.Msg {
+		if !hasDisplay(runtime.GOOS) {
+			return openResultMsg{err: errors.New("no graphical session available")}
+		}
+
+		argv := openerCommand(runtime.GOOS, filepath.Join(targetDir, fileName))
+		path, err := exec.LookPath(argv[0])
+		if err != nil {
+			return openResultMsg{err: fmt.Errorf("no file opener available (%s not found)", argv[0])}
+		}
+
+		cmd := exec.Command(path, argv[1:]...)
+		if err := cmd.Start(); err != nil {
+			return openResultMsg{err: fmt.Errorf("failed to start %s: %w", argv[0], err)}
+		}
+		// Reap the process in the background
+		go func() { _ = cmd.Wait() }()
+
+		return openResultMsg{}
+	}
+}
+
+// savePresetCmd creates a command that stores the files under the preset name
+// Returns presetSavedMsg when done.
+func savePresetCmd(save func(name string, files []string) error, name string, files []string) tea.Cmd {
+	return func() tea.Msg {
+		return presetSavedMsg{name: name, err: save(name, files)}
+	}
+}
@@ -1,9 +1,14 @@
 package ui
 
 import (
+	"context"
 	"os"
 	"path/filepath"
+	"reflect"
+	"sync/atomic"
 	"testing"
+
+	"github.com/marco-arnold/lnka/internal/filesystem"
 )
 
 func TestLoadFilesCmd_Success(t *testing.T) {
@@ -31,7 +36,8 @@ func TestLoadFilesCmd_Success(t *testing.T) {
 	}
 
 	// Execute command synchronously
-	cmd := loadFilesCmd(sourceDir, targetDir)
+	scanned := new(atomic.Int64)
+	cmd := loadFilesCmd(context.Background(), sourceDir, targetDir, nil, nil, filesystem.Options{}, 0, scanned)
 	msg := cmd()
 
 	// Type assert the message
@@ -55,6 +61,11 @@ func TestLoadFilesCmd_Success(t *testing.T) {
 		t.Errorf("Expected %d enabled files, got %d", len(linkedFiles), len(loadedMsg.enabledFiles))
 	}
 
+	// Check scanned files count
+	if got := scanned.Load(); got != int64(len(testFiles)) {
+		t.Errorf("Expected %d scanned files, got %d", len(testFiles), got)
+	}
+
 	// Verify all available files are present
 	availableMap := make(map[string]bool)
 	for _, f := range loadedMsg.availableFiles {
@@ -84,7 +95,7 @@ func TestLoadFilesCmd_NonExistentSourceDir(t *testing.T) {
 	targetDir := t.TempDir()
 
 	// Execute command synchronously
-	cmd := loadFilesCmd(nonExistentSource, targetDir)
+	cmd := loadFilesCmd(context.Background(), nonExistentSource, targetDir, nil, nil, filesystem.Options{}, 0, nil)
 	msg := cmd()
 
 	// Type assert the message
@@ -111,7 +122,7 @@ func TestLoadFilesCmd_NonExistentTargetDir(t *testing.T) {
 	}
 
 	// Execute command synchronously
-	cmd := loadFilesCmd(sourceDir, nonExistentTarget)
+	cmd := loadFilesCmd(context.Background(), sourceDir, nonExistentTarget, nil, nil, filesystem.Options{}, 0, nil)
 	msg := cmd()
 
 	// Type assert the message
@@ -137,7 +148,7 @@ func TestLoadFilesCmd_EmptyDirs(t *testing.T) {
 	targetDir := t.TempDir()
 
 	// Execute command synchronously
-	cmd := loadFilesCmd(sourceDir, targetDir)
+	cmd := loadFilesCmd(context.Background(), sourceDir, targetDir, nil, nil, filesystem.Options{}, 0, nil)
 	msg := cmd()
 
 	// Type assert the message
@@ -160,3 +171,78 @@ func TestLoadFilesCmd_EmptyDirs(t *testing.T) {
 		t.Errorf("Expected 0 enabled files, got %d", len(loadedMsg.enabledFiles))
 	}
 }
+
+func TestLoadFilesCmd_ExtraTargets(t *testing.T) {
+	sourceDir := t.TempDir()
+	targetDir := t.TempDir()
+	extraDir := t.TempDir()
+
+	for _, name := range []string{"both.conf", "first.conf", "extra.conf", "none.conf"} {
+		if err := os.WriteFile(filepath.Join(sourceDir, name), []byte("test"), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+	links := map[string][]string{
+		targetDir: {"both.conf", "first.conf"},
+		extraDir:  {"both.conf", "extra.conf"},
+	}
+	for dir, names := range links {
+		for _, name := range names {
+			if err := os.Symlink(filepath.Join(sourceDir, name), filepath.Join(dir, name)); err != nil {
+				t.Fatalf("Failed to create symlink: %v", err)
+			}
+		}
+	}
+
+	cmd := loadFilesCmd(context.Background(), sourceDir, targetDir, []string{extraDir}, nil, filesystem.Options{}, 0, nil)
+	loadedMsg, ok := cmd().(filesLoadedMsg)
+	if !ok {
+		t.Fatal("Expected filesLoadedMsg")
+	}
+	if loadedMsg.err != nil {
+		t.Fatalf("Expected no error, got %v", loadedMsg.err)
+	}
+
+	// Files linked in any target start enabled
+	if want := []string{"both.conf", "first.conf", "extra.conf"}; !reflect.DeepEqual(loadedMsg.enabledFiles, want) {
+		t.Errorf("Expected enabled files %v, got %v", want, loadedMsg.enabledFiles)
+	}
+	want := map[string]string{"first.conf": "1/2 targets", "extra.conf": "1/2 targets"}
+	if !reflect.DeepEqual(loadedMsg.partial, want) {
+		t.Errorf("Expected partial files %v, got %v", want, loadedMsg.partial)
+	}
+}
+
+// TestLoadFilesCmd_AlsoCheck tests reporting files linked from another
+// directory without enabling them
+func TestLoadFilesCmd_AlsoCheck(t *testing.T) {
+	sourceDir := t.TempDir()
+	targetDir := t.TempDir()
+	otherDir := t.TempDir()
+
+	for _, name := range []string{"app.conf", "other.conf"} {
+		if err := os.WriteFile(filepath.Join(sourceDir, name), []byte("content"), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+	link := filepath.Join(otherDir, "app.conf")
+	if err := os.Symlink(filepath.Join(sourceDir, "app.conf"), link); err != nil {
+		t.Fatalf("Failed to create symlink: %v", err)
+	}
+
+	cmd := loadFilesCmd(context.Background(), sourceDir, targetDir, nil, []string{otherDir}, filesystem.Options{}, 0, nil)
+	loadedMsg, ok := cmd().(filesLoadedMsg)
+	if !ok {
+		t.Fatal("Expected filesLoadedMsg")
+	}
+	if loadedMsg.err != nil {
+		t.Fatalf("Expected no error, got %v", loadedMsg.err)
+	}
+
+	if len(loadedMsg.enabledFiles) != 0 {
+		t.Errorf("Expected no enabled files, got %v", loadedMsg.enabledFiles)
+	}
+	if want := map[string][]string{"app.conf": {link}}; !reflect.DeepEqual(loadedMsg.external, want) {
+		t.Errorf("Expected external links %v, got %v", want, loadedMsg.external)
+	}
+}
@@ -0,0 +1,114 @@
+package ui
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/charmbracelet/bubbles/list"
+)
+
+// contentSearchBytes limits how much of each file the content filter reads
+const contentSearchBytes = 4 * 1024
+
+// contentCache holds the beginning of the source files for the content
+// filter, read on first use. The filter runs outside the update loop, so
+// access is synchronized.
+type contentCache struct {
+	sourceDir string
+
+	mu       sync.Mutex
+	contents map[string]string // File name -> text ("" for binary or unreadable files)
+}
+
+// newContentCache creates an empty cache for the files of sourceDir
+func newContentCache(sourceDir string) *contentCache {
+	return &contentCache{sourceDir: sourceDir, contents: make(map[string]string)}
+}
+
+// get returns the beginning of the file, reading it once. Binary and
+// unreadable files have no content.
+func (c *contentCache) get(name string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if content, ok := c.contents[name]; ok {
+		return content
+	}
+	content := readContent(filepath.Join(c.sourceDir, name))
+	c.contents[name] = content
+	return content
+}
+
+// clear forgets the cached contents, e.g. after the files changed
+func (c *contentCache) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.contents = make(map[string]string)
+}
+
+// readContent returns the first contentSearchBytes of the file, "" if it
+// cannot be read or does not look like text
+func readContent(path string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(io.LimitReader(f, contentSearchBytes))
+	if err != nil || isBinary(data, len(data) == contentSearchBytes) {
+		return ""
+	}
+	return string(data)
+}
+
+// newContentFilter wraps the name filter so files whose content matches the
+// term are shown as well, after the name matches. The term is a regular
+// expression in regex mode and plain text otherwise; tag queries only match
+// names.
+func newContentFilter(names list.FilterFunc, contents *contentCache, mode filterMode, caseSensitive bool) list.FilterFunc {
+	return func(term string, targets []string) []list.Rank {
+		ranks := names(term, targets)
+		match := contentMatcher(term, mode, caseSensitive)
+		if match == nil {
+			return ranks
+		}
+
+		matched := make(map[int]bool, len(ranks))
+		for _, rank := range ranks {
+			matched[rank.Index] = true
+		}
+		for i, target := range targets {
+			if !matched[i] && match(contents.get(target)) {
+				ranks = append(ranks, list.Rank{Index: i})
+			}
+		}
+		return ranks
+	}
+}
+
+// contentMatcher returns the function matching file contents against the
+// term, nil if contents are not searched for it
+func contentMatcher(term string, mode filterMode, caseSensitive bool) func(string) bool {
+	if term == "" {
+		return nil
+	}
+	if mode == filterRegex {
+		re, err := compileFilterRegex(term, caseSensitive)
+		if err != nil {
+			return nil
+		}
+		return re.MatchString
+	}
+	if _, _, ok := parseTagQuery(term); ok {
+		return nil
+	}
+	if caseSensitive {
+		return func(content string) bool { return strings.Contains(content, term) }
+	}
+	term = strings.ToLower(term)
+	return func(content string) bool { return strings.Contains(strings.ToLower(content), term) }
+}
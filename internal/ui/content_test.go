@@ -0,0 +1,95 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// rankIndexes returns the target indexes of the ranks in order
+func rankIndexes(ranks []list.Rank) []int {
+	indexes := []int{}
+	for _, rank := range ranks {
+		indexes = append(indexes, rank.Index)
+	}
+	return indexes
+}
+
+// TestContentFilter tests matching file contents after the names, skipping
+// binary files and reading each file once
+func TestContentFilter(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"blob.bin":   "listen\x00\x01",
+		"nginx.conf": "server {\n    listen 80;\n}\n",
+		"redis.conf": "port 6379\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+	targets := []string{"blob.bin", "nginx.conf", "redis.conf"}
+	contents := newContentCache(dir)
+
+	tests := []struct {
+		name  string
+		mode  filterMode
+		term  string
+		cased bool
+		want  []int
+	}{
+		{name: "content", mode: filterFuzzy, term: "LISTEN", want: []int{1}},
+		{name: "case-sensitive content", mode: filterFuzzy, term: "LISTEN", cased: true, want: []int{}},
+		{name: "names first", mode: filterFuzzy, term: "redis", want: []int{2}},
+		{name: "regex", mode: filterRegex, term: `port \d+`, want: []int{2}},
+		{name: "tag query", mode: filterFuzzy, term: "#web", want: []int{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter := newContentFilter(newItemFilter(nil, tt.mode, tt.cased), contents, tt.mode, tt.cased)
+			if got := rankIndexes(filter(tt.term, targets)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("filter(%q) = %v, want %v", tt.term, got, tt.want)
+			}
+		})
+	}
+
+	// Further keystrokes use the cached contents until they are cleared
+	if err := os.WriteFile(filepath.Join(dir, "nginx.conf"), []byte("changed"), 0644); err != nil {
+		t.Fatalf("Failed to change test file: %v", err)
+	}
+	filter := newContentFilter(newItemFilter(nil, filterFuzzy, false), contents, filterFuzzy, false)
+	if got := rankIndexes(filter("listen", targets)); !reflect.DeepEqual(got, []int{1}) {
+		t.Errorf("filter() with cached contents = %v, want [1]", got)
+	}
+	contents.clear()
+	if got := rankIndexes(filter("listen", targets)); len(got) != 0 {
+		t.Errorf("filter() after clear = %v, want no matches", got)
+	}
+}
+
+// TestUpdate_FilterContentToggle tests switching content matching with ctrl+g
+func TestUpdate_FilterContentToggle(t *testing.T) {
+	m := newTestModel([]string{"a.conf"})
+	m.sourceDir = t.TempDir()
+	m.applyFilterMode()
+	m.list.SetFilterState(list.Filtering)
+
+	// Single updates, the filter input's cursor blink would keep update going
+	toggle := func(m multiSelectModel) multiSelectModel {
+		model, _ := m.Update(tea.KeyMsg{Type: tea.KeyCtrlG})
+		return model.(multiSelectModel)
+	}
+	m = toggle(m)
+	if !m.contentSearch || m.list.FilterInput.Prompt != "Filter (+content): " {
+		t.Errorf("contentSearch = %v, prompt = %q, want content matching on", m.contentSearch, m.list.FilterInput.Prompt)
+	}
+	m = toggle(m)
+	if m.contentSearch || m.list.FilterInput.Prompt != "Filter: " {
+		t.Errorf("contentSearch = %v, prompt = %q, want content matching off", m.contentSearch, m.list.FilterInput.Prompt)
+	}
+}
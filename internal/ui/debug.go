@@ -1,23 +1,63 @@
 package ui
 
 import (
+	"fmt"
+	"io"
 	"log"
+	"log/slog"
+	"strings"
 )
 
 // debugEnabled controls whether debug logging is active
 var debugEnabled = false
 
+// debugJSON, when set, receives the debug events as structured records
+// instead of the text log (see SetDebugJSON)
+var debugJSON *slog.Logger
+
 // SetDebugEnabled enables or disables debug logging.
 // This should be called from main.go when the --debug flag is set.
 func SetDebugEnabled(enabled bool) {
 	debugEnabled = enabled
 }
 
-// logDebug writes a debug message to the log if debug mode is enabled.
+// SetDebugJSON writes the debug log to w as one JSON object per line (time,
+// level, event, msg and the fields of the event) instead of the text log.
+// A nil writer restores the text log.
+func SetDebugJSON(w io.Writer) {
+	if w == nil {
+		debugJSON = nil
+		return
+	}
+	debugJSON = slog.New(slog.NewJSONHandler(w, &slog.HandlerOptions{Level: slog.LevelDebug}))
+}
+
+// logDebug writes a debug event to the log if debug mode is enabled.
 // The log file is configured in main.go via tea.LogToFile().
 // Debug mode must be explicitly enabled via SetDebugEnabled(true).
-func logDebug(format string, args ...any) {
-	if debugEnabled {
-		log.Printf(format, args...)
+// The event names the operation ("Filter"), msg describes it (may be
+// empty) and attrs are key-value pairs as for slog, e.g.
+// logDebug("Toggle", "", "selectedCount", n). The text log writes them as
+// "Toggle: selectedCount=3", the JSON log as separate fields.
+func logDebug(event, msg string, attrs ...any) {
+	if !debugEnabled {
+		return
+	}
+	if debugJSON != nil {
+		debugJSON.Debug(msg, slog.String("event", event), slog.Group("fields", attrs...))
+		return
+	}
+
+	var b strings.Builder
+	b.WriteString(event + ":")
+	if msg != "" {
+		b.WriteString(" " + msg)
 	}
+	record := slog.Record{}
+	record.Add(attrs...)
+	record.Attrs(func(attr slog.Attr) bool {
+		fmt.Fprintf(&b, " %s=%v", attr.Key, attr.Value)
+		return true
+	})
+	log.Print(b.String())
 }
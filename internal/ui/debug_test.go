@@ -0,0 +1,78 @@
+package ui
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"reflect"
+	"testing"
+)
+
+// TestLogDebug_Text tests writing the event, message and fields as one line
+func TestLogDebug_Text(t *testing.T) {
+	var buf bytes.Buffer
+	output, flags := log.Writer(), log.Flags()
+	log.SetOutput(&buf)
+	log.SetFlags(0)
+	SetDebugEnabled(true)
+	defer func() {
+		SetDebugEnabled(false)
+		log.SetOutput(output)
+		log.SetFlags(flags)
+	}()
+
+	tests := []struct {
+		event string
+		msg   string
+		attrs []any
+		want  string
+	}{
+		{event: "Filter", msg: "entered filter mode", want: "Filter: entered filter mode\n"},
+		{event: "Toggle", attrs: []any{"selectedCount", 3}, want: "Toggle: selectedCount=3\n"},
+		{event: "Sort", msg: "preserving cursor", attrs: []any{"sortOrder", "size", "cursor", "a.conf"}, want: "Sort: preserving cursor sortOrder=size cursor=a.conf\n"},
+	}
+	for _, tt := range tests {
+		buf.Reset()
+		logDebug(tt.event, tt.msg, tt.attrs...)
+		if got := buf.String(); got != tt.want {
+			t.Errorf("logDebug(%q, %q, %v) wrote %q, want %q", tt.event, tt.msg, tt.attrs, got, tt.want)
+		}
+	}
+
+	SetDebugEnabled(false)
+	buf.Reset()
+	logDebug("Toggle", "", "selectedCount", 3)
+	if buf.Len() != 0 {
+		t.Errorf("logDebug() wrote %q with debug mode off", buf.String())
+	}
+}
+
+// TestLogDebug_JSON tests the structured debug log
+func TestLogDebug_JSON(t *testing.T) {
+	var buf bytes.Buffer
+	SetDebugEnabled(true)
+	SetDebugJSON(&buf)
+	defer func() {
+		SetDebugEnabled(false)
+		SetDebugJSON(nil)
+	}()
+
+	logDebug("Toggle", "toggled", "name", "a.conf", "selected", true)
+
+	var record struct {
+		Time   string         `json:"time"`
+		Level  string         `json:"level"`
+		Event  string         `json:"event"`
+		Msg    string         `json:"msg"`
+		Fields map[string]any `json:"fields"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("debug log %q is no JSON object: %v", buf.String(), err)
+	}
+	if record.Time == "" || record.Level != "DEBUG" || record.Event != "Toggle" || record.Msg != "toggled" {
+		t.Errorf("record = %+v, want time, DEBUG level, Toggle event and the message", record)
+	}
+	if want := map[string]any{"name": "a.conf", "selected": true}; !reflect.DeepEqual(record.Fields, want) {
+		t.Errorf("fields = %v, want %v", record.Fields, want)
+	}
+}
@@ -0,0 +1,54 @@
+package ui
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/marco-arnold/lnka/internal/filesystem"
+)
+
+// selectionDiff returns the links the selection would create, remove and
+// rename compared to the files enabled when the list was loaded, all sorted
+func (m multiSelectModel) selectionDiff() *filesystem.ChangeSet {
+	changes := &filesystem.ChangeSet{}
+	initial := make(map[string]bool, len(m.initialEnabled))
+	for _, name := range m.initialEnabled {
+		initial[name] = true
+		if !m.selectedMap[name] {
+			changes.Remove = append(changes.Remove, name)
+		}
+	}
+	for name := range m.selectedMap {
+		if !initial[name] {
+			changes.Create = append(changes.Create, name)
+		}
+	}
+	for name, link := range m.linkNames {
+		if initial[name] && m.selectedMap[name] && link != m.currentLinkName(name) {
+			changes.Rename = append(changes.Rename, name)
+		}
+	}
+	sort.Strings(changes.Create)
+	sort.Strings(changes.Remove)
+	sort.Strings(changes.Rename)
+	return changes
+}
+
+// diffView renders the pending changes of the current selection like the
+// change review, computed anew each time the overlay is opened
+func (m multiSelectModel) diffView() string {
+	changes := m.selectionDiff()
+
+	var b strings.Builder
+	b.WriteString(stylePrompt.Render(changeCounts(changes)))
+	b.WriteString("\n\n")
+	if !changes.HasChanges() {
+		b.WriteString("No changes")
+	} else {
+		b.WriteString(buildReviewContent(changes))
+	}
+
+	b.WriteString("\n\n")
+	b.WriteString(styleHelpFooter.Render("Press any key to close"))
+	return b.String()
+}
@@ -0,0 +1,57 @@
+package ui
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// TestSelectionDiff tests the changes of a selection against the files
+// enabled at load
+func TestSelectionDiff(t *testing.T) {
+	m := newTestModel([]string{"a.conf", "b.conf", "c.conf", "d.conf"}, "a.conf", "d.conf", "c.conf")
+	m.initialEnabled = []string{"a.conf", "b.conf"}
+
+	changes := m.selectionDiff()
+	if want := []string{"c.conf", "d.conf"}; !reflect.DeepEqual(changes.Create, want) {
+		t.Errorf("Create = %v, want %v", changes.Create, want)
+	}
+	if want := []string{"b.conf"}; !reflect.DeepEqual(changes.Remove, want) {
+		t.Errorf("Remove = %v, want %v", changes.Remove, want)
+	}
+}
+
+// TestUpdate_DiffOverlay tests that the overlay reflects the selection each
+// time it is opened and that any key closes it
+func TestUpdate_DiffOverlay(t *testing.T) {
+	m := newTestModel([]string{"a.conf", "b.conf"}, "a.conf")
+	m.initialEnabled = []string{"a.conf"}
+
+	m = update(m, keyRune('d'))
+	if !m.showDiff {
+		t.Fatal("d should open the pending changes")
+	}
+	if view := m.View(); !strings.Contains(view, "No changes") {
+		t.Errorf("View() = %q, want no changes", view)
+	}
+
+	// The closing key is not handled by the list
+	m = update(m, tea.KeyMsg{Type: tea.KeySpace})
+	if m.showDiff || len(m.selectedMap) != 1 {
+		t.Fatalf("showDiff = %v, selectedMap = %v, want the overlay closed and the key ignored", m.showDiff, m.selectedMap)
+	}
+
+	// Deselect a.conf and select b.conf
+	m = update(m, tea.KeyMsg{Type: tea.KeySpace})
+	m = update(m, keyRune('j'))
+	m = update(m, tea.KeyMsg{Type: tea.KeySpace})
+	m = update(m, keyRune('d'))
+	view := m.View()
+	for _, want := range []string{"1 to create, 1 to remove", "+ b.conf", "- a.conf"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("View() = %q, want it to contain %q", view, want)
+		}
+	}
+}
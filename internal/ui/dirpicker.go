@@ -0,0 +1,224 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Special entries shown above the subdirectories of the directory picker
+const (
+	dirPickerSelect = "."  // Choose the current directory
+	dirPickerParent = ".." // Go to the parent directory
+)
+
+// dirPickerModel is the Bubble Tea model for choosing a directory
+// It shows the subdirectories of the current directory and lets the user
+// navigate into and out of them
+type dirPickerModel struct {
+	title   string   // Prompt shown above the listing
+	dir     string   // Current absolute directory
+	entries []string // Special entries followed by subdirectory names
+	cursor  int      // Index into entries
+	chosen  string   // Chosen absolute directory (set on selection)
+	aborted bool     // User pressed ctrl+c or esc
+	err     error    // Error reading the current directory
+	width   int      // Terminal width
+}
+
+// newDirPickerModel creates a directory picker starting at dir
+func newDirPickerModel(title, dir string) (dirPickerModel, error) {
+	m := dirPickerModel{title: title}
+	if err := m.changeDir(dir); err != nil {
+		return m, err
+	}
+	return m, nil
+}
+
+// changeDir makes dir the current directory and reloads the entries
+func (m *dirPickerModel) changeDir(dir string) error {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return fmt.Errorf("failed to resolve directory %s: %w", dir, err)
+	}
+
+	dirs, err := listSubdirectories(abs)
+	if err != nil {
+		return err
+	}
+
+	entries := []string{dirPickerSelect}
+	if filepath.Dir(abs) != abs {
+		entries = append(entries, dirPickerParent)
+	}
+
+	m.dir = abs
+	m.entries = append(entries, dirs...)
+	m.cursor = 0
+	return nil
+}
+
+// listSubdirectories returns the names of the directories in dir
+// Symlinks pointing to directories are included
+func listSubdirectories(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory %s: %w", dir, err)
+	}
+
+	var dirs []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			dirs = append(dirs, entry.Name())
+			continue
+		}
+		if entry.Type()&os.ModeSymlink != 0 {
+			if info, err := os.Stat(filepath.Join(dir, entry.Name())); err == nil && info.IsDir() {
+				dirs = append(dirs, entry.Name())
+			}
+		}
+	}
+	return dirs, nil
+}
+
+// Init initializes the directory picker model.
+// No commands are needed for initialization.
+func (m dirPickerModel) Init() tea.Cmd {
+	return nil
+}
+
+// Update handles messages for the directory picker.
+// Supported keys:
+//   - ↑/k, ↓/j: Move cursor
+//   - enter/→/l: Choose "." or enter the directory under the cursor
+//   - ←/h/backspace: Go to the parent directory
+//   - esc/ctrl+c: Abort
+func (m dirPickerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		return m, nil
+
+	case tea.KeyMsg:
+		m.err = nil
+		switch msg.String() {
+		case "ctrl+c", "esc":
+			m.aborted = true
+			return m, tea.Quit
+		case "up", "k":
+			if m.cursor > 0 {
+				m.cursor--
+			}
+		case "down", "j":
+			if m.cursor < len(m.entries)-1 {
+				m.cursor++
+			}
+		case "left", "h", "backspace":
+			m.enter(dirPickerParent)
+		case "enter", "right", "l":
+			if m.entries[m.cursor] == dirPickerSelect {
+				if msg.String() == "enter" {
+					m.chosen = m.dir
+					return m, tea.Quit
+				}
+				return m, nil
+			}
+			m.enter(m.entries[m.cursor])
+		}
+	}
+	return m, nil
+}
+
+// enter changes into the named entry of the current directory
+// Errors are kept on the model and shown in the view
+func (m *dirPickerModel) enter(name string) {
+	target := filepath.Join(m.dir, name)
+	if name == dirPickerParent {
+		target = filepath.Dir(m.dir)
+		if target == m.dir {
+			return
+		}
+	}
+	if err := m.changeDir(target); err != nil {
+		m.err = err
+	}
+}
+
+// View renders the directory picker with the current path, the entries
+// and a help bar at the bottom.
+func (m dirPickerModel) View() string {
+	if m.aborted || m.chosen != "" {
+		return ""
+	}
+
+	var b strings.Builder
+	if m.title != "" {
+		b.WriteString(m.title)
+		b.WriteString("\n")
+	}
+	b.WriteString(stylePrompt.Render(m.dir))
+	b.WriteString("\n\n")
+
+	for i, name := range m.entries {
+		label := name + string(filepath.Separator)
+		if name == dirPickerSelect {
+			label = ". (use this directory)"
+		}
+		if i == m.cursor {
+			b.WriteString(defaultItemStyles.cursorEnabled.Render("> " + label))
+		} else {
+			b.WriteString("  " + label)
+		}
+		b.WriteString("\n")
+	}
+
+	if m.err != nil {
+		b.WriteString("\n")
+		b.WriteString(styleDanger.Render(m.err.Error()))
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	helpText := "↑/↓: move | enter: open/choose | ←: parent | esc: abort"
+	b.WriteString(styleHelpBar.Width(m.width).Render(" " + helpText))
+
+	return b.String()
+}
+
+// ShowDirectoryPicker displays an interactive directory browser starting at
+// the current working directory and returns the chosen absolute path.
+//
+// Returns:
+//   - string: Absolute path of the chosen directory
+//   - error: Returns an error if user aborts (esc/ctrl+c) or if there's a program error
+func ShowDirectoryPicker(title string) (string, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("failed to get working directory: %w", err)
+	}
+
+	m, err := newDirPickerModel(title, cwd)
+	if err != nil {
+		return "", err
+	}
+
+	p := tea.NewProgram(m)
+	finalModel, err := p.Run()
+	if err != nil {
+		return "", fmt.Errorf("program error: %w", err)
+	}
+
+	model, ok := finalModel.(dirPickerModel)
+	if !ok {
+		return "", fmt.Errorf("unexpected model type")
+	}
+
+	if model.aborted {
+		return "", ErrUserAborted
+	}
+
+	return model.chosen, nil
+}
@@ -0,0 +1,118 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// setupDirTree creates root/{alpha/inner,beta} plus a regular file
+func setupDirTree(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	for _, dir := range []string{"alpha/inner", "beta"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0755); err != nil {
+			t.Fatalf("Failed to create %s: %v", dir, err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(root, "file.txt"), []byte("x"), 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	return root
+}
+
+// pickerKey sends a key to the picker model
+func pickerKey(m dirPickerModel, k string) dirPickerModel {
+	var msg tea.KeyMsg
+	switch k {
+	case "enter":
+		msg = tea.KeyMsg{Type: tea.KeyEnter}
+	case "left":
+		msg = tea.KeyMsg{Type: tea.KeyLeft}
+	case "down":
+		msg = tea.KeyMsg{Type: tea.KeyDown}
+	case "esc":
+		msg = tea.KeyMsg{Type: tea.KeyEsc}
+	}
+	model, _ := m.Update(msg)
+	return model.(dirPickerModel)
+}
+
+// TestDirPicker_ListsOnlyDirectories tests the initial entries
+func TestDirPicker_ListsOnlyDirectories(t *testing.T) {
+	root := setupDirTree(t)
+
+	m, err := newDirPickerModel("", root)
+	if err != nil {
+		t.Fatalf("newDirPickerModel failed: %v", err)
+	}
+
+	want := []string{dirPickerSelect, dirPickerParent, "alpha", "beta"}
+	if !reflect.DeepEqual(m.entries, want) {
+		t.Errorf("entries = %v, want %v", m.entries, want)
+	}
+}
+
+// TestDirPicker_Navigation tests entering and leaving directories and choosing one
+func TestDirPicker_Navigation(t *testing.T) {
+	root := setupDirTree(t)
+
+	m, err := newDirPickerModel("", root)
+	if err != nil {
+		t.Fatalf("newDirPickerModel failed: %v", err)
+	}
+
+	// Enter alpha (entries: ".", "..", "alpha", "beta")
+	m = pickerKey(m, "down")
+	m = pickerKey(m, "down")
+	m = pickerKey(m, "enter")
+	if m.dir != filepath.Join(root, "alpha") {
+		t.Fatalf("dir = %s, want alpha", m.dir)
+	}
+	if !reflect.DeepEqual(m.entries, []string{dirPickerSelect, dirPickerParent, "inner"}) {
+		t.Errorf("entries = %v, want inner only", m.entries)
+	}
+	if m.cursor != 0 {
+		t.Errorf("cursor = %d, want reset to 0", m.cursor)
+	}
+
+	// Leave via the parent key, then via the ".." entry from beta
+	m = pickerKey(m, "left")
+	if m.dir != root {
+		t.Fatalf("dir = %s, want root after left", m.dir)
+	}
+	m = pickerKey(m, "down")
+	m = pickerKey(m, "down")
+	m = pickerKey(m, "down")
+	m = pickerKey(m, "enter")
+	if m.dir != filepath.Join(root, "beta") {
+		t.Fatalf("dir = %s, want beta", m.dir)
+	}
+	m = pickerKey(m, "down")
+	m = pickerKey(m, "enter")
+	if m.dir != root {
+		t.Fatalf("dir = %s, want root after ..", m.dir)
+	}
+
+	// Choose the current directory
+	m = pickerKey(m, "enter")
+	if m.chosen != root {
+		t.Errorf("chosen = %q, want %q", m.chosen, root)
+	}
+}
+
+// TestDirPicker_Abort tests that esc aborts without choosing
+func TestDirPicker_Abort(t *testing.T) {
+	m, err := newDirPickerModel("", setupDirTree(t))
+	if err != nil {
+		t.Fatalf("newDirPickerModel failed: %v", err)
+	}
+
+	m = pickerKey(m, "esc")
+	if !m.aborted || m.chosen != "" {
+		t.Errorf("aborted=%t chosen=%q, want aborted without choice", m.aborted, m.chosen)
+	}
+}
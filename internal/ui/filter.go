@@ -0,0 +1,165 @@
+package ui
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/bubbles/list"
+)
+
+// tagFilterPrefix marks a filter term as a tag query (e.g. "#web")
+const tagFilterPrefix = "#"
+
+// filterMode selects how the / filter matches file names
+type filterMode int
+
+const (
+	filterFuzzy filterMode = iota // Fuzzy matching with tag queries (default)
+	filterRegex                   // Regular expression matching
+)
+
+// parseFilterMode returns the filter mode for a --filter-mode value
+// Unknown values fall back to fuzzy matching.
+func parseFilterMode(s string) filterMode {
+	if s == "regex" {
+		return filterRegex
+	}
+	return filterFuzzy
+}
+
+// newItemFilter returns the list.FilterFunc for the given filter mode,
+// ignoring case unless caseSensitive is set
+func newItemFilter(tags map[string][]string, mode filterMode, caseSensitive bool) list.FilterFunc {
+	if mode == filterRegex {
+		return newRegexFilter(caseSensitive)
+	}
+	return newTagFilter(tags, caseSensitive)
+}
+
+// compileFilterRegex compiles a regex filter term, ignoring case unless
+// caseSensitive is set
+func compileFilterRegex(term string, caseSensitive bool) (*regexp.Regexp, error) {
+	if !caseSensitive {
+		term = "(?i)" + term
+	}
+	return regexp.Compile(term)
+}
+
+// newRegexFilter returns a list.FilterFunc matching the term as a regular
+// expression against the file names, keeping their order. An invalid
+// expression matches nothing (the error is shown by the UI).
+func newRegexFilter(caseSensitive bool) list.FilterFunc {
+	return func(term string, targets []string) []list.Rank {
+		re, err := compileFilterRegex(term, caseSensitive)
+		if err != nil {
+			return nil
+		}
+
+		var ranks []list.Rank
+		for i, target := range targets {
+			if re.MatchString(target) {
+				ranks = append(ranks, list.Rank{Index: i})
+			}
+		}
+		return ranks
+	}
+}
+
+// fuzzyFilter fuzzy-matches the term against the targets like
+// list.DefaultFilter, which ignores case. With caseSensitive, matches whose
+// characters differ in case from the term are dropped.
+func fuzzyFilter(term string, targets []string, caseSensitive bool) []list.Rank {
+	ranks := list.DefaultFilter(term, targets)
+	if !caseSensitive {
+		return ranks
+	}
+
+	kept := ranks[:0]
+	for _, rank := range ranks {
+		if sameCaseMatch(term, targets[rank.Index], rank.MatchedIndexes) {
+			kept = append(kept, rank)
+		}
+	}
+	return kept
+}
+
+// sameCaseMatch reports whether the characters of target at the matched
+// byte indexes spell the term exactly
+func sameCaseMatch(term, target string, matched []int) bool {
+	termRunes := []rune(term)
+	if len(termRunes) != len(matched) {
+		return false
+	}
+	for i, index := range matched {
+		if r, _ := utf8.DecodeRuneInString(target[index:]); r != termRunes[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// newTagFilter returns a list.FilterFunc that understands tag queries.
+//
+// A term starting with "#" restricts the list to items carrying that tag
+// (case-insensitive). Anything after the tag, separated by a space, is
+// fuzzy-matched against the remaining names. Terms without the prefix use
+// the default fuzzy filter. Names ignore case unless caseSensitive is set.
+//
+// Targets are the items' FilterValue() strings, i.e. the file names, which
+// are used as keys into the tag mapping.
+func newTagFilter(tags map[string][]string, caseSensitive bool) list.FilterFunc {
+	return func(term string, targets []string) []list.Rank {
+		tag, rest, ok := parseTagQuery(term)
+		if !ok {
+			return fuzzyFilter(term, targets, caseSensitive)
+		}
+
+		// Collect targets carrying the tag, remembering their original index
+		var indexes []int
+		var names []string
+		for i, target := range targets {
+			if hasTag(tags[target], tag) {
+				indexes = append(indexes, i)
+				names = append(names, target)
+			}
+		}
+
+		// Only a tag given: keep all tagged items in original order
+		if rest == "" {
+			ranks := make([]list.Rank, len(indexes))
+			for i, index := range indexes {
+				ranks[i] = list.Rank{Index: index}
+			}
+			return ranks
+		}
+
+		// Fuzzy-match the rest of the term within the tagged items
+		ranks := fuzzyFilter(rest, names, caseSensitive)
+		for i := range ranks {
+			ranks[i].Index = indexes[ranks[i].Index]
+		}
+		return ranks
+	}
+}
+
+// parseTagQuery splits a filter term like "#web nginx" into the tag ("web")
+// and the remaining term ("nginx"). Returns false if the term is not a tag query.
+func parseTagQuery(term string) (tag string, rest string, ok bool) {
+	if !strings.HasPrefix(term, tagFilterPrefix) {
+		return "", "", false
+	}
+
+	tag, rest, _ = strings.Cut(strings.TrimPrefix(term, tagFilterPrefix), " ")
+	return tag, strings.TrimSpace(rest), true
+}
+
+// hasTag reports whether tags contains tag (case-insensitive)
+func hasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,140 @@
+package ui
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestParseTagQuery(t *testing.T) {
+	tests := []struct {
+		term     string
+		wantTag  string
+		wantRest string
+		wantOK   bool
+	}{
+		{term: "nginx", wantOK: false},
+		{term: "#web", wantTag: "web", wantOK: true},
+		{term: "#web nginx", wantTag: "web", wantRest: "nginx", wantOK: true},
+		{term: "#web  nginx ", wantTag: "web", wantRest: "nginx", wantOK: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.term, func(t *testing.T) {
+			tag, rest, ok := parseTagQuery(tt.term)
+			if tag != tt.wantTag || rest != tt.wantRest || ok != tt.wantOK {
+				t.Errorf("parseTagQuery(%q) = (%q, %q, %t), want (%q, %q, %t)",
+					tt.term, tag, rest, ok, tt.wantTag, tt.wantRest, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestTagFilter(t *testing.T) {
+	tags := map[string][]string{
+		"app.conf":   {"web", "critical"},
+		"nginx.conf": {"Web"},
+		"db.conf":    {"db", "critical"},
+	}
+	targets := []string{"app.conf", "db.conf", "nginx.conf", "plain.conf"}
+	filter := newTagFilter(tags, false)
+
+	// matchedNames maps the returned ranks back to target names
+	matchedNames := func(term string) []string {
+		var names []string
+		for _, rank := range filter(term, targets) {
+			names = append(names, targets[rank.Index])
+		}
+		return names
+	}
+
+	tests := []struct {
+		name string
+		term string
+		want []string
+	}{
+		{
+			name: "tag only keeps original order",
+			term: "#web",
+			want: []string{"app.conf", "nginx.conf"},
+		},
+		{
+			name: "tag shared by several items",
+			term: "#critical",
+			want: []string{"app.conf", "db.conf"},
+		},
+		{
+			name: "tag combined with name term",
+			term: "#web ngx",
+			want: []string{"nginx.conf"},
+		},
+		{
+			name: "unknown tag matches nothing",
+			term: "#unknown",
+			want: nil,
+		},
+		{
+			name: "plain term uses fuzzy filter on names",
+			term: "plain",
+			want: []string{"plain.conf"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := matchedNames(tt.term)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("filter(%q) = %v, want %v", tt.term, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestRegexFilter tests anchored matching and that invalid patterns match nothing
+func TestRegexFilter(t *testing.T) {
+	targets := []string{"db-main.conf", "db-main.conf.bak", "web.conf", "old-db-x.conf"}
+
+	filter := newRegexFilter(false)
+	ranks := filter(`^db-.*\.conf$`, targets)
+	if len(ranks) != 1 || ranks[0].Index != 0 {
+		t.Errorf("regexFilter() = %v, want only db-main.conf", ranks)
+	}
+
+	if ranks := filter(`db-(`, targets); len(ranks) != 0 {
+		t.Errorf("regexFilter() with invalid pattern = %v, want no matches", ranks)
+	}
+}
+
+// TestItemFilter_IgnoreCase tests that a lowercase term matches mixed-case
+// names in both filter modes unless the filter is case-sensitive
+func TestItemFilter_IgnoreCase(t *testing.T) {
+	targets := []string{"Nginx.conf", "NGINX-ssl.conf", "redis.conf", "nginx-old.conf"}
+	tests := []struct {
+		name          string
+		mode          filterMode
+		caseSensitive bool
+		term          string
+		want          []string
+	}{
+		{name: "fuzzy", mode: filterFuzzy, term: "nginx", want: []string{"Nginx.conf", "NGINX-ssl.conf", "nginx-old.conf"}},
+		{name: "regex", mode: filterRegex, term: "^nginx", want: []string{"Nginx.conf", "NGINX-ssl.conf", "nginx-old.conf"}},
+		{name: "fuzzy case-sensitive", mode: filterFuzzy, caseSensitive: true, term: "nginx", want: []string{"nginx-old.conf"}},
+		{name: "regex case-sensitive", mode: filterRegex, caseSensitive: true, term: "^NGINX", want: []string{"NGINX-ssl.conf"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter := newItemFilter(nil, tt.mode, tt.caseSensitive)
+			var got []string
+			for _, rank := range filter(tt.term, targets) {
+				got = append(got, targets[rank.Index])
+			}
+			sort.Strings(got)
+			want := append([]string(nil), tt.want...)
+			sort.Strings(want)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("filter(%q) = %v, want %v", tt.term, got, want)
+			}
+		})
+	}
+}
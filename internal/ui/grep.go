@@ -0,0 +1,53 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// grepSelect selects (or deselects) every item matching the filter, clears
+// the filter and returns the command refreshing the list with the cursor kept
+// on its item. Does nothing without a filter.
+func (m *multiSelectModel) grepSelect(selected bool) tea.Cmd {
+	if m.list.FilterState() == list.Unfiltered {
+		return nil
+	}
+
+	var currentFileName string
+	if fi, ok := m.list.SelectedItem().(fileItem); ok {
+		currentFileName = fi.name
+	}
+
+	changed := 0
+	for _, item := range m.list.VisibleItems() {
+		fi, ok := item.(fileItem)
+		if !ok || m.selectedMap[fi.name] == selected {
+			continue
+		}
+		if selected {
+			m.selectedMap[fi.name] = true
+			m.selectedOrder = append(m.selectedOrder, fi.name)
+		} else {
+			delete(m.selectedMap, fi.name)
+			m.removeFromOrder(fi.name)
+		}
+		changed++
+	}
+
+	query := m.list.FilterValue()
+	if selected {
+		m.status = fmt.Sprintf("Selected %d file(s) matching %q", changed, query)
+	} else {
+		m.status = fmt.Sprintf("Deselected %d file(s) matching %q", changed, query)
+	}
+	logDebug("GrepSelect", "", "selected", selected, "changed", changed, "query", query, "selectedCount", len(m.selectedMap))
+	m.list.ResetFilter()
+
+	// Auto-disable hideUnlinked if no items are selected
+	if m.shouldDisableHideMode() {
+		m.hideUnlinked = false
+	}
+	return m.rebuildItemsCmdWithCursor(currentFileName)
+}
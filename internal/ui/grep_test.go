@@ -0,0 +1,61 @@
+package ui
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// TestUpdate_GrepSelect tests selecting the filter matches and clearing the
+// filter in one step
+func TestUpdate_GrepSelect(t *testing.T) {
+	m := newTestModel([]string{"nginx.conf", "nginx-ssl.conf", "redis.conf", "redis-sentinel.conf"}, "redis.conf")
+	m.list.SetFilterText("nginx")
+	m.list.SetFilterState(list.Filtering)
+
+	m = update(m, tea.KeyMsg{Type: tea.KeyCtrlS})
+	if want := []string{"redis.conf", "nginx.conf", "nginx-ssl.conf"}; !reflect.DeepEqual(m.selectedOrder, want) {
+		t.Errorf("selectedOrder = %v, want %v", m.selectedOrder, want)
+	}
+	if m.list.FilterState() != list.Unfiltered {
+		t.Errorf("filter state = %v, want the filter cleared", m.list.FilterState())
+	}
+	if got := visibleNames(m); len(got) != 4 {
+		t.Errorf("visible items = %v, want all items", got)
+	}
+
+	// An applied filter works as well, deselecting its matches
+	m.list.SetFilterText("redis")
+	m = update(m, tea.KeyMsg{Type: tea.KeyCtrlX})
+	if want := []string{"nginx.conf", "nginx-ssl.conf"}; !reflect.DeepEqual(m.selectedOrder, want) {
+		t.Errorf("selectedOrder = %v, want %v", m.selectedOrder, want)
+	}
+	if m.list.FilterState() != list.Unfiltered {
+		t.Errorf("filter state = %v, want the filter cleared", m.list.FilterState())
+	}
+}
+
+// TestUpdate_GrepSelectUnfiltered tests that grep-select needs a filter
+func TestUpdate_GrepSelectUnfiltered(t *testing.T) {
+	m := newTestModel([]string{"a.conf", "b.conf"})
+	m = update(m, tea.KeyMsg{Type: tea.KeyCtrlS})
+	if len(m.selectedMap) != 0 {
+		t.Errorf("selectedMap = %v, want nothing selected without a filter", m.selectedMap)
+	}
+}
+
+// TestUpdate_GrepSelectOverlay tests that an open overlay receives ctrl+s
+// instead of the list behind it
+func TestUpdate_GrepSelectOverlay(t *testing.T) {
+	m := newTestModel([]string{"nginx.conf", "redis.conf"})
+	m.list.SetFilterText("nginx")
+	m.showHelp = true
+	m.help = newHelpOverlay(m.keys)
+
+	m = update(m, tea.KeyMsg{Type: tea.KeyCtrlS})
+	if len(m.selectedMap) != 0 {
+		t.Errorf("selectedMap = %v, want nothing selected behind the help overlay", m.selectedMap)
+	}
+}
@@ -0,0 +1,129 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// styleHelpFooter renders the hint line at the bottom of the help overlay
+var styleHelpFooter = lipgloss.NewStyle().Faint(true)
+
+// helpEntry is a single keyboard shortcut listed in the help overlay
+type helpEntry struct {
+	keys string // Key description (e.g., "↑/k")
+	desc string // What the shortcut does
+}
+
+// helpOverlay lists all keyboard shortcuts and can filter them by text
+type helpOverlay struct {
+	entries   []helpEntry // All shortcuts in display order
+	filter    string      // Current filter text
+	filtering bool        // Filter text is being typed
+}
+
+// bindings returns every binding of the keymap in display order
+func (k *keyMap) bindings() []key.Binding {
+	return []key.Binding{
+		k.Select, k.SelectAll, k.DeselectAll, k.Invert, k.Range,
+		k.Up, k.Down, k.GoTop, k.GoBottom, k.PageUp, k.PageDown,
+		k.HideToggle, k.Targets, k.Preview, k.Sort, k.SortReverse, k.EnabledFirst, k.Filter, k.FilterMode, k.FilterContent, k.GrepSelect, k.GrepDeselect, k.SavePreset, k.Rename, k.Open, k.Help, k.Info, k.Diff, k.Confirm, k.Quit,
+	}
+}
+
+// newHelpOverlay creates a help overlay from the enabled bindings of the keymap
+func newHelpOverlay(keys *keyMap) helpOverlay {
+	var entries []helpEntry
+	for _, b := range keys.bindings() {
+		if !b.Enabled() {
+			continue
+		}
+		h := b.Help()
+		entries = append(entries, helpEntry{keys: h.Key, desc: h.Desc})
+	}
+	return helpOverlay{entries: entries}
+}
+
+// visibleEntries returns the entries matching the filter (case-insensitive)
+func (h helpOverlay) visibleEntries() []helpEntry {
+	if h.filter == "" {
+		return h.entries
+	}
+
+	query := strings.ToLower(h.filter)
+	var visible []helpEntry
+	for _, e := range h.entries {
+		if strings.Contains(strings.ToLower(e.keys), query) ||
+			strings.Contains(strings.ToLower(e.desc), query) {
+			visible = append(visible, e)
+		}
+	}
+	return visible
+}
+
+// Update handles a key press in the help overlay
+// Returns the updated overlay and whether it should be closed
+func (h helpOverlay) Update(msg tea.KeyMsg) (helpOverlay, bool) {
+	if h.filtering {
+		switch msg.Type {
+		case tea.KeyEnter:
+			h.filtering = false
+		case tea.KeyEsc:
+			h.filtering = false
+			h.filter = ""
+		case tea.KeyBackspace:
+			if r := []rune(h.filter); len(r) > 0 {
+				h.filter = string(r[:len(r)-1])
+			}
+		case tea.KeyRunes, tea.KeySpace:
+			h.filter += string(msg.Runes)
+		}
+		return h, false
+	}
+
+	switch msg.String() {
+	case "/":
+		h.filtering = true
+	case "esc":
+		// Clear an applied filter first, close on the next esc
+		if h.filter != "" {
+			h.filter = ""
+			return h, false
+		}
+		return h, true
+	case "?", "q":
+		return h, true
+	}
+	return h, false
+}
+
+// View renders the help overlay
+func (h helpOverlay) View() string {
+	var b strings.Builder
+	b.WriteString(stylePrompt.Render("Keyboard shortcuts"))
+	b.WriteString("\n\n")
+
+	if h.filtering || h.filter != "" {
+		fmt.Fprintf(&b, "Filter: %s\n\n", h.filter)
+	}
+
+	entries := h.visibleEntries()
+	width := 0
+	for _, e := range entries {
+		width = max(width, lipgloss.Width(e.keys))
+	}
+	for _, e := range entries {
+		padding := strings.Repeat(" ", width-lipgloss.Width(e.keys))
+		fmt.Fprintf(&b, "  %s%s  %s\n", e.keys, padding, e.desc)
+	}
+	if len(entries) == 0 {
+		b.WriteString("  No matching shortcuts\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(styleHelpFooter.Render("/ filter • esc/? close"))
+	return b.String()
+}
@@ -0,0 +1,92 @@
+package ui
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// TestHelpOverlay_ListsEveryBinding tests that the overlay contains each keymap binding
+func TestHelpOverlay_ListsEveryBinding(t *testing.T) {
+	keys := defaultKeyMap()
+	keys.Open.SetEnabled(true)
+	keys.SavePreset.SetEnabled(true)
+	keys.Rename.SetEnabled(true)
+	h := newHelpOverlay(keys)
+
+	fields := reflect.ValueOf(*keys).NumField()
+	if len(h.entries) != fields {
+		t.Fatalf("help overlay has %d entries, keyMap has %d bindings", len(h.entries), fields)
+	}
+
+	view := h.View()
+	for _, b := range keys.bindings() {
+		if !strings.Contains(view, b.Help().Desc) {
+			t.Errorf("help overlay is missing %q", b.Help().Desc)
+		}
+	}
+}
+
+// TestHelpOverlay_SkipsDisabledBindings tests that disabled bindings are not listed
+func TestHelpOverlay_SkipsDisabledBindings(t *testing.T) {
+	keys := defaultKeyMap()
+	keys.Open.SetEnabled(false)
+
+	if view := newHelpOverlay(keys).View(); strings.Contains(view, keys.Open.Help().Desc) {
+		t.Errorf("help overlay lists disabled binding %q", keys.Open.Help().Desc)
+	}
+}
+
+// TestHelpOverlay_Filter tests that typing a filter narrows the displayed entries
+func TestHelpOverlay_Filter(t *testing.T) {
+	h := newHelpOverlay(defaultKeyMap())
+
+	h, _ = h.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("/")})
+	if !h.filtering {
+		t.Fatal("expected / to start filtering")
+	}
+	h, _ = h.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("page")})
+
+	visible := h.visibleEntries()
+	if len(visible) != 2 {
+		t.Fatalf("expected 2 entries matching %q, got %v", h.filter, visible)
+	}
+
+	view := h.View()
+	if !strings.Contains(view, "page down") || strings.Contains(view, "deselect all") {
+		t.Errorf("View() does not reflect filter:\n%s", view)
+	}
+
+	// Enter keeps the filter, esc clears it, a second esc closes
+	h, _ = h.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	h, closed := h.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if closed || h.filter != "" {
+		t.Errorf("expected first esc to clear filter, filter=%q closed=%t", h.filter, closed)
+	}
+	if _, closed = h.Update(tea.KeyMsg{Type: tea.KeyEsc}); !closed {
+		t.Error("expected second esc to close the overlay")
+	}
+}
+
+// TestUpdate_HelpOverlayToggle tests opening and closing the overlay from the list
+func TestUpdate_HelpOverlayToggle(t *testing.T) {
+	m := newTestModel([]string{"a.conf", "b.conf"})
+
+	m = update(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("?")})
+	if !m.showHelp {
+		t.Fatal("expected ? to open the help overlay")
+	}
+
+	// Keys go to the overlay, not the list
+	m = update(m, tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")})
+	if len(m.selectedMap) != 0 {
+		t.Error("space must not toggle selection while help is open")
+	}
+
+	m = update(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("?")})
+	if m.showHelp {
+		t.Error("expected ? to close the help overlay")
+	}
+}
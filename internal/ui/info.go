@@ -0,0 +1,49 @@
+package ui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// version is the build version shown in the info panel
+var version = "dev"
+
+// SetVersion sets the build version shown in the info panel.
+// This should be called from main.go with the version set at build time.
+func SetVersion(v string) {
+	version = v
+}
+
+// absPath returns the absolute form of dir, or dir itself if it cannot be resolved
+func absPath(dir string) string {
+	if abs, err := filepath.Abs(dir); err == nil {
+		return abs
+	}
+	return dir
+}
+
+// infoView renders the info panel with the directories, title, counts and
+// version of the session
+func (m multiSelectModel) infoView() string {
+	var b strings.Builder
+	b.WriteString(stylePrompt.Render("lnka " + version))
+	b.WriteString("\n\n")
+
+	title := m.list.Title
+	if title == "" {
+		title = "(none)"
+	}
+	fmt.Fprintf(&b, "  Source:  %s\n", absPath(m.sourceDir))
+	fmt.Fprintf(&b, "  Target:  %s\n", absPath(m.targetDir))
+	for _, dir := range m.extraTargets {
+		fmt.Fprintf(&b, "           %s\n", absPath(dir))
+	}
+	fmt.Fprintf(&b, "  Title:   %s\n", title)
+	fmt.Fprintf(&b, "  Files:   %d available, %d linked, %d selected\n",
+		len(m.availableFiles), len(m.initialEnabled), len(m.selectedMap))
+
+	b.WriteString("\n")
+	b.WriteString(styleHelpFooter.Render("Press any key to close"))
+	return b.String()
+}
@@ -0,0 +1,55 @@
+package ui
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// TestUpdate_InfoPanel tests opening the info panel and closing it with any key
+func TestUpdate_InfoPanel(t *testing.T) {
+	SetVersion("1.2.3")
+	defer SetVersion("dev")
+
+	m := newTestModel([]string{"a.conf", "b.conf"}, "a.conf")
+	m.sourceDir = "available"
+	m.targetDir = "enabled"
+	m.list.Title = "Sites"
+
+	m = update(m, keyRune('I'))
+	if !m.showInfo {
+		t.Fatal("I should open the info panel")
+	}
+	view := m.View()
+	for _, want := range []string{"lnka 1.2.3", absPath("available"), absPath("enabled"), "Sites", "2 available"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("View() = %q, want it to contain %q", view, want)
+		}
+	}
+	if !filepath.IsAbs(absPath("available")) {
+		t.Errorf("absPath() = %q, want an absolute path", absPath("available"))
+	}
+
+	// The closing key is not handled by the list
+	m = update(m, tea.KeyMsg{Type: tea.KeySpace})
+	if m.showInfo {
+		t.Error("any key should close the info panel")
+	}
+	if len(m.selectedMap) != 1 {
+		t.Errorf("selectedMap = %v, want the closing key ignored", m.selectedMap)
+	}
+}
+
+// TestUpdate_InfoPanelWhileFiltering tests that I is typed into the filter
+func TestUpdate_InfoPanelWhileFiltering(t *testing.T) {
+	m := newTestModel([]string{"a.conf"})
+	m.list.SetFilterState(list.Filtering)
+
+	m = update(m, keyRune('I'))
+	if m.showInfo {
+		t.Error("I should not open the info panel while filtering")
+	}
+}
@@ -0,0 +1,31 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// openerCommand returns the command line that reveals linkPath in the file
+// manager of the given platform (runtime.GOOS value)
+func openerCommand(goos, linkPath string) []string {
+	switch goos {
+	case "darwin":
+		return []string{"open", "-R", linkPath}
+	case "windows":
+		return []string{"explorer", "/select," + linkPath}
+	default:
+		// xdg-open cannot select a file, so open the containing directory
+		return []string{"xdg-open", filepath.Dir(linkPath)}
+	}
+}
+
+// hasDisplay reports whether a graphical session is available to show a
+// file manager. Only X11/Wayland platforms can be checked reliably.
+func hasDisplay(goos string) bool {
+	switch goos {
+	case "darwin", "windows":
+		return true
+	default:
+		return os.Getenv("DISPLAY") != "" || os.Getenv("WAYLAND_DISPLAY") != ""
+	}
+}
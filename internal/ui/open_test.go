@@ -0,0 +1,47 @@
+package ui
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// TestOpenerCommand tests the file manager command chosen per platform
+func TestOpenerCommand(t *testing.T) {
+	linkPath := filepath.Join("target", "site.conf")
+
+	tests := []struct {
+		goos string
+		want []string
+	}{
+		{"darwin", []string{"open", "-R", linkPath}},
+		{"windows", []string{"explorer", "/select," + linkPath}},
+		{"linux", []string{"xdg-open", "target"}},
+		{"freebsd", []string{"xdg-open", "target"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.goos, func(t *testing.T) {
+			if got := openerCommand(tt.goos, linkPath); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("openerCommand(%q) = %v, want %v", tt.goos, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestHasDisplay tests graphical session detection on X11/Wayland platforms
+func TestHasDisplay(t *testing.T) {
+	t.Setenv("DISPLAY", "")
+	t.Setenv("WAYLAND_DISPLAY", "")
+	if hasDisplay("linux") {
+		t.Error("hasDisplay() = true without DISPLAY or WAYLAND_DISPLAY")
+	}
+	if !hasDisplay("darwin") {
+		t.Error("hasDisplay() = false on darwin")
+	}
+
+	t.Setenv("WAYLAND_DISPLAY", "wayland-0")
+	if !hasDisplay("linux") {
+		t.Error("hasDisplay() = false with WAYLAND_DISPLAY set")
+	}
+}
@@ -0,0 +1,169 @@
+package ui
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	// previewDebounce is how long the cursor has to rest on an item before
+	// its file is read, so rapid navigation does not read every file passed
+	previewDebounce = 150 * time.Millisecond
+
+	// previewMaxBytes limits how much of a file is read for the preview
+	previewMaxBytes = 64 * 1024
+
+	// previewBinary is shown instead of the contents of binary files
+	previewBinary = "(binary)"
+)
+
+// stylePreview renders the preview pane with a separator line on its left
+var stylePreview = lipgloss.NewStyle().
+	Border(lipgloss.NormalBorder(), false, false, false, true).
+	BorderForeground(lipgloss.Color("240")).
+	PaddingLeft(1)
+
+// previewTickMsg is sent once the debounce delay of a preview request passed
+// Requests superseded by a later cursor move carry an outdated seq.
+type previewTickMsg struct {
+	seq int
+}
+
+// previewLoadedMsg is sent after reading the preview of a source file
+type previewLoadedMsg struct {
+	name    string
+	content string
+}
+
+// previewTickCmd creates a command that sends previewTickMsg after the
+// debounce delay
+func previewTickCmd(seq int) tea.Cmd {
+	return tea.Tick(previewDebounce, func(time.Time) tea.Msg {
+		return previewTickMsg{seq: seq}
+	})
+}
+
+// loadPreviewCmd creates a command that reads the first lines of a source file
+// Returns previewLoadedMsg when done.
+func loadPreviewCmd(sourceDir, name string, maxLines int) tea.Cmd {
+	return func() tea.Msg {
+		content, err := readPreview(filepath.Join(sourceDir, name), maxLines)
+		if err != nil {
+			content = fmt.Sprintf("(cannot read file: %v)", err)
+		}
+		return previewLoadedMsg{name: name, content: content}
+	}
+}
+
+// readPreview returns up to maxLines lines of the file at path, or
+// previewBinary if the file does not look like text
+func readPreview(path string, maxLines int) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(io.LimitReader(f, previewMaxBytes))
+	if err != nil {
+		return "", err
+	}
+	if isBinary(data, len(data) == previewMaxBytes) {
+		return previewBinary, nil
+	}
+
+	lines := strings.Split(string(data), "\n")
+	if len(lines) > maxLines {
+		lines = lines[:maxLines]
+	}
+	for i, line := range lines {
+		lines[i] = strings.ReplaceAll(strings.TrimSuffix(line, "\r"), "\t", "    ")
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// isBinary reports whether data contains NUL bytes or invalid UTF-8
+// A truncated read may end in the middle of a multi-byte character.
+func isBinary(data []byte, truncated bool) bool {
+	if bytes.IndexByte(data, 0) >= 0 {
+		return true
+	}
+	if truncated {
+		for i := 0; i < utf8.UTFMax-1 && len(data) > 0 && !utf8.Valid(data); i++ {
+			data = data[:len(data)-1]
+		}
+	}
+	return !utf8.Valid(data)
+}
+
+// schedulePreview starts a debounced preview request when the cursor moved
+// to another item since the last request
+func (m *multiSelectModel) schedulePreview() tea.Cmd {
+	var name string
+	if fi, ok := m.list.SelectedItem().(fileItem); ok {
+		name = fi.name
+	}
+	if name == m.previewPending {
+		return nil
+	}
+
+	m.previewPending = name
+	m.previewSeq++
+	if name == "" {
+		m.preview = ""
+		return nil
+	}
+	return previewTickCmd(m.previewSeq)
+}
+
+// previewWidth returns the width of the preview pane
+func (m *multiSelectModel) previewWidth() int {
+	return m.width - m.list.Width()
+}
+
+// resizeList sizes the list to the window, leaving room for the preview pane,
+// or to the configured page size if that fits
+func (m *multiSelectModel) resizeList() {
+	width := m.width
+	if m.showPreview {
+		width = m.width / 2
+	}
+	height := m.height - m.reservedLines()
+	if m.pageSize > 0 {
+		height = min(height, m.listHeaderLines()+m.pageSize*(m.delegate.Height()+m.delegate.Spacing()))
+	}
+	m.list.SetSize(width, height)
+
+	// SetSize repaginates without updating the page keys, which stay
+	// disabled if the items fit on one page before
+	if m.list.FilterState() != list.Filtering {
+		hasPages := m.list.Paginator.TotalPages > 1
+		m.list.KeyMap.NextPage.SetEnabled(hasPages)
+		m.list.KeyMap.PrevPage.SetEnabled(hasPages)
+	}
+}
+
+// previewView renders the preview pane at the height of the list
+func (m *multiSelectModel) previewView() string {
+	height := m.list.Height()
+	width := m.previewWidth()
+	if height <= 0 || width <= 0 {
+		return ""
+	}
+
+	content := m.preview
+	if m.previewPending != "" && m.previewName != m.previewPending {
+		content = m.delegate.style().tag.Render("Loading...")
+	}
+	return stylePreview.Height(height).MaxHeight(height).MaxWidth(width).Render(content)
+}
@@ -0,0 +1,120 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// TestReadPreview tests reading the first lines of text and binary files
+func TestReadPreview(t *testing.T) {
+	dir := t.TempDir()
+	text := filepath.Join(dir, "app.conf")
+	if err := os.WriteFile(text, []byte("one\n\ttwo\r\nthree\nfour\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	binary := filepath.Join(dir, "app.bin")
+	if err := os.WriteFile(binary, []byte{0x7f, 'E', 'L', 'F', 0, 1}, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := readPreview(text, 3)
+	if err != nil {
+		t.Fatalf("readPreview failed: %v", err)
+	}
+	if want := "one\n    two\nthree"; got != want {
+		t.Errorf("readPreview() = %q, want %q", got, want)
+	}
+
+	got, err = readPreview(binary, 3)
+	if err != nil {
+		t.Fatalf("readPreview failed: %v", err)
+	}
+	if got != previewBinary {
+		t.Errorf("readPreview() = %q, want %q", got, previewBinary)
+	}
+
+	if _, err := readPreview(filepath.Join(dir, "missing.conf"), 3); err == nil {
+		t.Error("readPreview() expected error for a missing file")
+	}
+}
+
+// TestIsBinary tests binary detection, allowing a character cut by truncation
+func TestIsBinary(t *testing.T) {
+	cut := []byte("größe")[:3] // "gr" and the first byte of "ö"
+	tests := []struct {
+		name      string
+		data      []byte
+		truncated bool
+		want      bool
+	}{
+		{"text", []byte("server {\n}\n"), false, false},
+		{"utf-8 text", []byte("größe = 1\n"), false, false},
+		{"nul byte", []byte("a\x00b"), false, true},
+		{"invalid utf-8", []byte{0xff, 0xfe, 'a'}, false, true},
+		{"truncated character", cut, true, false},
+		{"cut character at end of file", cut, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isBinary(tt.data, tt.truncated); got != tt.want {
+				t.Errorf("isBinary() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestUpdate_Preview tests toggling the preview pane and that only the latest
+// debounced request is read
+func TestUpdate_Preview(t *testing.T) {
+	sourceDir := t.TempDir()
+	for name, content := range map[string]string{"a.conf": "alpha", "b.conf": "beta"} {
+		if err := os.WriteFile(filepath.Join(sourceDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	m := newTestModel([]string{"a.conf", "b.conf"})
+	m.sourceDir = sourceDir
+	m = update(m, tea.WindowSizeMsg{Width: 80, Height: 24})
+
+	model, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'p'}})
+	m = model.(multiSelectModel)
+	if !m.showPreview || m.list.Width() != 40 {
+		t.Fatalf("after p: showPreview=%t list width=%d, want true and 40", m.showPreview, m.list.Width())
+	}
+	firstSeq := m.previewSeq
+
+	// Moving on before the delay passed supersedes the first request
+	model, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	m = model.(multiSelectModel)
+	if m.previewPending != "b.conf" || m.previewSeq == firstSeq {
+		t.Fatalf("after down: pending=%q seq=%d, want b.conf and a new request", m.previewPending, m.previewSeq)
+	}
+	if _, cmd := m.Update(previewTickMsg{seq: firstSeq}); cmd != nil {
+		t.Error("outdated preview request was read")
+	}
+
+	model, cmd := m.Update(previewTickMsg{seq: m.previewSeq})
+	m = model.(multiSelectModel)
+	if cmd == nil {
+		t.Fatal("latest preview request was not read")
+	}
+	m = update(m, cmd())
+	if m.preview != "beta" {
+		t.Errorf("preview = %q, want beta", m.preview)
+	}
+	if view := m.View(); !strings.Contains(view, "beta") {
+		t.Errorf("View() does not show the preview:\n%s", view)
+	}
+
+	model, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'p'}})
+	m = model.(multiSelectModel)
+	if m.showPreview || m.list.Width() != 80 {
+		t.Errorf("after second p: showPreview=%t list width=%d, want false and 80", m.showPreview, m.list.Width())
+	}
+}
@@ -0,0 +1,84 @@
+package ui
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// startRange marks the item at the cursor as the anchor of a range selection
+func (m *multiSelectModel) startRange() {
+	fi, ok := m.list.SelectedItem().(fileItem)
+	if !ok {
+		return
+	}
+	m.anchorIndex = m.list.Index()
+	m.anchorFile = fi.name
+	logDebug("Range", "anchor set", "file", fi.name, "index", m.anchorIndex)
+}
+
+// cancelRange drops the anchor of a started range selection
+func (m *multiSelectModel) cancelRange() {
+	m.anchorIndex = 0
+	m.anchorFile = ""
+}
+
+// revalidateAnchor moves the anchor index to the anchor file after the
+// visible items changed (rebuild, filter or reload). The range is canceled
+// when the anchor file is no longer visible.
+func (m *multiSelectModel) revalidateAnchor() {
+	if m.anchorFile == "" {
+		return
+	}
+	for i, item := range m.list.VisibleItems() {
+		if fi, ok := item.(fileItem); ok && fi.name == m.anchorFile {
+			m.anchorIndex = i
+			return
+		}
+	}
+	logDebug("Range", "anchor no longer visible, canceling", "file", m.anchorFile)
+	m.status = fmt.Sprintf("Range canceled: %s is no longer listed", m.anchorFile)
+	m.cancelRange()
+}
+
+// toggleRange toggles the selection of every visible item between the anchor
+// and the cursor (both included), ends the range and returns the command
+// refreshing the list with the cursor kept on its item
+func (m *multiSelectModel) toggleRange() tea.Cmd {
+	m.revalidateAnchor()
+	if m.anchorFile == "" {
+		return nil
+	}
+
+	var currentFileName string
+	if fi, ok := m.list.SelectedItem().(fileItem); ok {
+		currentFileName = fi.name
+	}
+
+	from, to := m.anchorIndex, m.list.Index()
+	if from > to {
+		from, to = to, from
+	}
+	items := m.list.VisibleItems()
+	for _, item := range items[from : to+1] {
+		fi, ok := item.(fileItem)
+		if !ok {
+			continue
+		}
+		if m.selectedMap[fi.name] {
+			delete(m.selectedMap, fi.name)
+			m.removeFromOrder(fi.name)
+		} else {
+			m.selectedMap[fi.name] = true
+			m.selectedOrder = append(m.selectedOrder, fi.name)
+		}
+	}
+	logDebug("Range", "toggled items", "toggled", to-from+1, "selectedCount", len(m.selectedMap))
+	m.cancelRange()
+
+	// Auto-disable hideUnlinked if no items are selected
+	if m.shouldDisableHideMode() {
+		m.hideUnlinked = false
+	}
+	return m.rebuildItemsCmdWithCursor(currentFileName)
+}
@@ -0,0 +1,86 @@
+package ui
+
+import (
+	"reflect"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// keyRune returns the key message of typing r
+func keyRune(r rune) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}}
+}
+
+// TestUpdate_ToggleRange tests toggling every item between anchor and cursor
+func TestUpdate_ToggleRange(t *testing.T) {
+	m := newTestModel([]string{"a.conf", "b.conf", "c.conf", "d.conf", "e.conf"}, "c.conf")
+	m.list.Select(1)
+
+	m = update(m, keyRune('v'))
+	if m.anchorFile != "b.conf" {
+		t.Fatalf("anchorFile = %q, want b.conf", m.anchorFile)
+	}
+
+	// Ranges work in both directions, cursor below the anchor here
+	m.list.Select(3)
+	m = update(m, tea.KeyMsg{Type: tea.KeySpace})
+
+	if want := []string{"b.conf", "d.conf"}; !reflect.DeepEqual(m.selectedOrder, want) {
+		t.Errorf("selectedOrder = %v, want %v (c.conf toggled off)", m.selectedOrder, want)
+	}
+	if m.anchorFile != "" {
+		t.Errorf("anchorFile = %q, want range finished", m.anchorFile)
+	}
+	if m.list.Index() != 3 {
+		t.Errorf("cursor = %d, want kept on d.conf", m.list.Index())
+	}
+
+	// Upwards from the anchor on d.conf
+	m = update(m, keyRune('v'))
+	m.list.Select(0)
+	m = update(m, keyRune('v'))
+	if want := []string{"a.conf", "c.conf"}; !reflect.DeepEqual(m.selectedOrder, want) {
+		t.Errorf("selectedOrder = %v, want %v", m.selectedOrder, want)
+	}
+}
+
+// TestUpdate_ToggleRangeEsc tests canceling a started range
+func TestUpdate_ToggleRangeEsc(t *testing.T) {
+	m := newTestModel([]string{"a.conf", "b.conf"})
+	m = update(m, keyRune('v'))
+	m = update(m, tea.KeyMsg{Type: tea.KeyEsc})
+	if m.anchorFile != "" {
+		t.Fatalf("anchorFile = %q, want canceled", m.anchorFile)
+	}
+
+	// Space toggles only the cursor again
+	m.list.Select(1)
+	m = update(m, tea.KeyMsg{Type: tea.KeySpace})
+	if want := []string{"b.conf"}; !reflect.DeepEqual(m.selectedOrder, want) {
+		t.Errorf("selectedOrder = %v, want %v", m.selectedOrder, want)
+	}
+}
+
+// TestRevalidateAnchor tests following the anchor file across rebuilds and
+// canceling the range once it is gone
+func TestRevalidateAnchor(t *testing.T) {
+	m := newTestModel([]string{"a.conf", "b.conf", "c.conf"}, "c.conf")
+	m.list.Select(2)
+	m = update(m, keyRune('v'))
+
+	// Hiding unlinked items moves c.conf to the top
+	m = update(m, keyRune('h'))
+	if m.anchorIndex != 0 || m.anchorFile != "c.conf" {
+		t.Errorf("anchor = %d/%q, want 0/c.conf", m.anchorIndex, m.anchorFile)
+	}
+
+	// A reload without c.conf cancels the range
+	m = update(m, filesReloadedMsg{filesLoadedMsg{availableFiles: []string{"a.conf", "b.conf"}}})
+	if m.anchorFile != "" {
+		t.Errorf("anchorFile = %q, want canceled", m.anchorFile)
+	}
+	if m.status == "" {
+		t.Error("status should report the canceled range")
+	}
+}
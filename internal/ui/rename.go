@@ -0,0 +1,110 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// defaultLinkName returns the link name of a file without a custom name
+// (the file name, rewritten by the rename pattern if there is one)
+func (m *multiSelectModel) defaultLinkName(name string) string {
+	if m.fsOpts.Rename != nil {
+		return m.fsOpts.Rename.Apply(name)
+	}
+	return name
+}
+
+// currentLinkName returns the name the link of a file had when the list was
+// loaded (or would get with the custom names of earlier applies)
+func (m *multiSelectModel) currentLinkName(name string) string {
+	if link, ok := m.loadedLinks[name]; ok {
+		return link
+	}
+	return m.fsOpts.LinkName(name)
+}
+
+// linkNameOf returns the name the link of a file gets on apply
+func (m *multiSelectModel) linkNameOf(name string) string {
+	if link, ok := m.linkNames[name]; ok {
+		return link
+	}
+	return m.currentLinkName(name)
+}
+
+// startRename opens the link name prompt for the file under the cursor,
+// prefilled with its current link name
+func (m *multiSelectModel) startRename() {
+	fi, ok := m.list.SelectedItem().(fileItem)
+	if !ok {
+		return
+	}
+	m.renameFile = fi.name
+	m.renameInput = filepath.Base(m.linkNameOf(fi.name))
+}
+
+// updateRenamePrompt handles a key press while a link name is typed
+// Enter sets the typed name, esc or an empty name cancels
+func (m multiSelectModel) updateRenamePrompt(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEnter:
+		name := m.renameFile
+		m.renameFile = ""
+		if m.renameInput == "" {
+			m.status = "Rename canceled"
+			return m, nil
+		}
+		if err := m.setLinkName(name, m.renameInput); err != nil {
+			m.status = fmt.Sprintf("Cannot rename the link of %s: %v", name, err)
+			return m, nil
+		}
+		return m, m.rebuildItemsCmdWithCursor(name)
+	case tea.KeyEsc:
+		m.renameFile = ""
+	case tea.KeyBackspace:
+		if r := []rune(m.renameInput); len(r) > 0 {
+			m.renameInput = string(r[:len(r)-1])
+		}
+	case tea.KeyRunes:
+		m.renameInput += string(msg.Runes)
+	}
+	return m, nil
+}
+
+// setLinkName gives the link of the file the typed base name, in the
+// directory of the file. Fails for names that are no plain file name,
+// already name the link of another file or exist in a target, which apply
+// would replace.
+func (m *multiSelectModel) setLinkName(name, base string) error {
+	if base == "." || base == ".." || filepath.Base(base) != base {
+		return fmt.Errorf("%q is not a file name", base)
+	}
+
+	link := filepath.Join(filepath.Dir(name), base)
+	for _, other := range m.availableFiles {
+		if other != name && m.linkNameOf(other) == link {
+			return fmt.Errorf("%s is already the link name of %s", link, other)
+		}
+	}
+
+	if link != m.currentLinkName(name) {
+		for _, dir := range append([]string{m.targetDir}, m.extraTargets...) {
+			if dir == "" {
+				continue
+			}
+			if _, err := os.Lstat(filepath.Join(dir, link)); err == nil {
+				return fmt.Errorf("%s already exists in %s", link, dir)
+			}
+		}
+	}
+
+	if m.linkNames == nil {
+		m.linkNames = make(map[string]string)
+	}
+	m.linkNames[name] = link
+	m.status = fmt.Sprintf("%s will be linked as %s", name, link)
+	logDebug("Rename", "", "file", name, "link", link)
+	return nil
+}
@@ -0,0 +1,101 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// typeLinkName opens the link name prompt on the file and types name over
+// the prefilled one
+func typeLinkName(m multiSelectModel, file, name string) multiSelectModel {
+	m.setCursorToFile(file)
+	m = update(m, keyRune('R'))
+	for range m.renameInput {
+		m = update(m, tea.KeyMsg{Type: tea.KeyBackspace})
+	}
+	if name != "" {
+		m = update(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(name)})
+	}
+	return update(m, tea.KeyMsg{Type: tea.KeyEnter})
+}
+
+// TestUpdate_RenameLink tests giving a link a custom name with R
+func TestUpdate_RenameLink(t *testing.T) {
+	m := newTestModel([]string{"a.conf", "b.conf"}, "a.conf")
+	m.keys.Rename.SetEnabled(true)
+	m.initialEnabled = []string{"a.conf"}
+
+	m = update(m, keyRune('R'))
+	if m.renameFile != "a.conf" || m.renameInput != "a.conf" {
+		t.Fatalf("prompt for %q prefilled with %q, want a.conf", m.renameFile, m.renameInput)
+	}
+	m = update(m, tea.KeyMsg{Type: tea.KeyEsc})
+
+	m = typeLinkName(m, "a.conf", "custom.conf")
+	if m.renameFile != "" {
+		t.Error("prompt still open after enter")
+	}
+	if want := map[string]string{"a.conf": "custom.conf"}; !reflect.DeepEqual(m.linkNames, want) {
+		t.Errorf("linkNames = %v, want %v", m.linkNames, want)
+	}
+	if got := m.newFileItem("a.conf").linkName; got != "custom.conf" {
+		t.Errorf("item linkName = %q, want custom.conf", got)
+	}
+	if got := m.selectionDiff().Rename; !reflect.DeepEqual(got, []string{"a.conf"}) {
+		t.Errorf("selectionDiff().Rename = %v, want [a.conf]", got)
+	}
+
+	// The name of another link collides
+	m = typeLinkName(m, "b.conf", "custom.conf")
+	if _, ok := m.linkNames["b.conf"]; ok || !strings.Contains(m.status, "already the link name of a.conf") {
+		t.Errorf("linkNames = %v, status = %q, want a collision", m.linkNames, m.status)
+	}
+
+	// An empty name cancels, invalid names are refused
+	m = typeLinkName(m, "b.conf", "")
+	if _, ok := m.linkNames["b.conf"]; ok || m.status != "Rename canceled" {
+		t.Errorf("linkNames = %v, status = %q, want the rename canceled", m.linkNames, m.status)
+	}
+	m = typeLinkName(m, "b.conf", "sub/b.conf")
+	if _, ok := m.linkNames["b.conf"]; ok || !strings.Contains(m.status, "not a file name") {
+		t.Errorf("linkNames = %v, status = %q, want an invalid name", m.linkNames, m.status)
+	}
+
+	// Files of the target are not replaced
+	m.targetDir = t.TempDir()
+	if err := os.WriteFile(filepath.Join(m.targetDir, "nginx.conf"), []byte("local"), 0644); err != nil {
+		t.Fatalf("Failed to create target file: %v", err)
+	}
+	m = typeLinkName(m, "b.conf", "nginx.conf")
+	if _, ok := m.linkNames["b.conf"]; ok || !strings.Contains(m.status, "nginx.conf already exists") {
+		t.Errorf("linkNames = %v, status = %q, want an existing target file", m.linkNames, m.status)
+	}
+}
+
+// TestUpdate_RenameLoadedLink tests that links found under another name keep
+// it until renamed
+func TestUpdate_RenameLoadedLink(t *testing.T) {
+	m := newTestModel([]string{"a.conf"}, "a.conf")
+	m.keys.Rename.SetEnabled(true)
+	m.initialEnabled = []string{"a.conf"}
+	m.loadedLinks = map[string]string{"a.conf": "old.conf"}
+
+	if got := m.newFileItem("a.conf").linkName; got != "old.conf" {
+		t.Errorf("item linkName = %q, want old.conf", got)
+	}
+
+	// Typing the loaded name again is no change
+	m = typeLinkName(m, "a.conf", "old.conf")
+	if changes := m.selectionDiff(); changes.HasChanges() {
+		t.Errorf("selectionDiff() = %+v, want no changes", changes)
+	}
+	m = typeLinkName(m, "a.conf", "a.conf")
+	if got := m.selectionDiff().Rename; !reflect.DeepEqual(got, []string{"a.conf"}) {
+		t.Errorf("selectionDiff().Rename = %v, want [a.conf]", got)
+	}
+}
@@ -0,0 +1,159 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/marco-arnold/lnka/internal/filesystem"
+)
+
+// reviewChromeLines is the number of lines used by the header and help bar
+// of the change review
+const reviewChromeLines = 4
+
+// Styles for change review lines
+var (
+	styleCreate = lipgloss.NewStyle().Foreground(lipgloss.Color("10")) // Green
+	styleRemove = lipgloss.NewStyle().Foreground(lipgloss.Color("9"))  // Red
+	styleRename = lipgloss.NewStyle().Foreground(lipgloss.Color("11")) // Yellow
+)
+
+// reviewModel is the Bubble Tea model for reviewing a large change set
+// It shows all planned changes in a scrollable viewport
+type reviewModel struct {
+	viewport  viewport.Model
+	header    string // Summary line above the changes
+	content   string // Viewport content (one line per change)
+	ready     bool   // Viewport has been sized
+	confirmed bool   // User pressed enter
+	aborted   bool   // User pressed ctrl+c
+	width     int    // Terminal width
+}
+
+// buildReviewContent lists removals ("- name") followed by creations ("+ name")
+// and renamed links ("~ name")
+func buildReviewContent(changes *filesystem.ChangeSet) string {
+	lines := make([]string, 0, len(changes.Remove)+len(changes.Create)+len(changes.Rename))
+	for _, name := range changes.Remove {
+		lines = append(lines, styleRemove.Render("- "+name))
+	}
+	for _, name := range changes.Create {
+		lines = append(lines, styleCreate.Render("+ "+name))
+	}
+	for _, name := range changes.Rename {
+		lines = append(lines, styleRename.Render("~ "+name))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// changeCounts summarizes the change set, e.g. "2 to create, 1 to remove"
+func changeCounts(changes *filesystem.ChangeSet) string {
+	counts := fmt.Sprintf("%d to create, %d to remove", len(changes.Create), len(changes.Remove))
+	if len(changes.Rename) > 0 {
+		counts += fmt.Sprintf(", %d to rename", len(changes.Rename))
+	}
+	return counts
+}
+
+// newReviewModel creates a review model for the given change set
+func newReviewModel(changes *filesystem.ChangeSet) reviewModel {
+	return reviewModel{
+		header:  changeCounts(changes) + ". Apply these changes?",
+		content: buildReviewContent(changes),
+	}
+}
+
+// Init initializes the review model.
+// No commands are needed for initialization.
+func (m reviewModel) Init() tea.Cmd {
+	return nil
+}
+
+// Update handles messages for the change review.
+// Supported keys:
+//   - enter: Apply the changes
+//   - esc/q: Cancel without applying
+//   - ctrl+c: Abort
+//   - ↑/↓, pgup/pgdn, ...: Scroll (viewport key bindings)
+func (m reviewModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		height := max(msg.Height-reviewChromeLines, 1)
+		if !m.ready {
+			m.viewport = viewport.New(msg.Width, height)
+			m.viewport.SetContent(m.content)
+			m.ready = true
+		} else {
+			m.viewport.Width = msg.Width
+			m.viewport.Height = height
+		}
+		return m, nil
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c":
+			m.aborted = true
+			return m, tea.Quit
+		case "enter":
+			m.confirmed = true
+			return m, tea.Quit
+		case "esc", "q":
+			m.confirmed = false
+			return m, tea.Quit
+		}
+	}
+
+	var cmd tea.Cmd
+	m.viewport, cmd = m.viewport.Update(msg)
+	return m, cmd
+}
+
+// View renders the change review with a summary, the scrollable changes
+// and a help bar at the bottom.
+func (m reviewModel) View() string {
+	if m.aborted || !m.ready {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString(m.header)
+	b.WriteString("\n\n")
+	b.WriteString(m.viewport.View())
+	b.WriteString("\n")
+
+	helpText := fmt.Sprintf("↑/↓ pgup/pgdn: scroll (%3.f%%) | enter: apply | esc: cancel | ctrl+c: abort",
+		m.viewport.ScrollPercent()*100)
+	b.WriteString(styleHelpBar.Width(m.width).Render(" " + helpText))
+
+	return b.String()
+}
+
+// ShowChangeReview displays all planned changes in a scrollable view and asks
+// whether to apply them. It is meant for change sets too large for a plain
+// ShowConfirmation message.
+//
+// Returns:
+//   - bool: true if user pressed Enter, false if cancelled with Esc
+//   - error: Returns an error if user aborts (ctrl+c) or if there's a program error
+func ShowChangeReview(changes *filesystem.ChangeSet) (bool, error) {
+	p := tea.NewProgram(newReviewModel(changes), tea.WithAltScreen())
+	finalModel, err := p.Run()
+	if err != nil {
+		return false, fmt.Errorf("program error: %w", err)
+	}
+
+	model, ok := finalModel.(reviewModel)
+	if !ok {
+		return false, fmt.Errorf("unexpected model type")
+	}
+
+	if model.aborted {
+		return false, ErrUserAborted
+	}
+
+	return model.confirmed, nil
+}
@@ -0,0 +1,73 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/marco-arnold/lnka/internal/filesystem"
+)
+
+// TestBuildReviewContent tests the review lines for a large change set
+func TestBuildReviewContent(t *testing.T) {
+	changes := &filesystem.ChangeSet{}
+	for i := 0; i < 300; i++ {
+		changes.Create = append(changes.Create, fmt.Sprintf("new-%03d.conf", i))
+	}
+	for i := 0; i < 200; i++ {
+		changes.Remove = append(changes.Remove, fmt.Sprintf("old-%03d.conf", i))
+	}
+
+	lines := strings.Split(buildReviewContent(changes), "\n")
+	if len(lines) != 500 {
+		t.Fatalf("expected 500 lines, got %d", len(lines))
+	}
+
+	// Removals come first, then creations, each in plan order
+	if !strings.Contains(lines[0], "- old-000.conf") {
+		t.Errorf("first line = %q, want first removal", lines[0])
+	}
+	if !strings.Contains(lines[199], "- old-199.conf") {
+		t.Errorf("line 199 = %q, want last removal", lines[199])
+	}
+	if !strings.Contains(lines[200], "+ new-000.conf") {
+		t.Errorf("line 200 = %q, want first creation", lines[200])
+	}
+	if !strings.Contains(lines[499], "+ new-299.conf") {
+		t.Errorf("last line = %q, want last creation", lines[499])
+	}
+}
+
+// TestReviewModel_Keys tests confirming and cancelling the review
+func TestReviewModel_Keys(t *testing.T) {
+	changes := &filesystem.ChangeSet{Create: []string{"a.conf"}, Remove: []string{"b.conf"}}
+
+	tests := []struct {
+		name          string
+		key           tea.KeyMsg
+		wantConfirmed bool
+		wantAborted   bool
+	}{
+		{"enter confirms", tea.KeyMsg{Type: tea.KeyEnter}, true, false},
+		{"esc cancels", tea.KeyMsg{Type: tea.KeyEsc}, false, false},
+		{"ctrl+c aborts", tea.KeyMsg{Type: tea.KeyCtrlC}, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var model tea.Model = newReviewModel(changes)
+			model, _ = model.Update(tea.WindowSizeMsg{Width: 80, Height: 10})
+			model, cmd := model.Update(tt.key)
+			m := model.(reviewModel)
+
+			if cmd == nil {
+				t.Error("expected the review to quit")
+			}
+			if m.confirmed != tt.wantConfirmed || m.aborted != tt.wantAborted {
+				t.Errorf("confirmed=%t aborted=%t, want confirmed=%t aborted=%t",
+					m.confirmed, m.aborted, tt.wantConfirmed, tt.wantAborted)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package ui
+
+import (
+	"sort"
+	"time"
+)
+
+// sortOrder is the order of the items in the multi-select list
+type sortOrder int
+
+// Available sort orders, cycled with the sort key
+const (
+	sortByName    sortOrder = iota // Alphabetical
+	sortBySize                     // Largest files first
+	sortByModTime                  // Most recently modified first
+)
+
+// String returns the display name of the sort order
+func (o sortOrder) String() string {
+	switch o {
+	case sortBySize:
+		return "size"
+	case sortByModTime:
+		return "mtime"
+	default:
+		return "name"
+	}
+}
+
+// parseSortOrder returns the sort order with the given name (default: name)
+func parseSortOrder(name string) sortOrder {
+	switch name {
+	case "size":
+		return sortBySize
+	case "mtime":
+		return sortByModTime
+	default:
+		return sortByName
+	}
+}
+
+// next returns the sort order following o in the cycle name → size → mtime
+func (o sortOrder) next() sortOrder {
+	return (o + 1) % 3
+}
+
+// fileStat holds the stat information shown for a source file
+type fileStat struct {
+	size    int64
+	modTime time.Time
+}
+
+// sortFiles returns a sorted copy of files. Files without stat information
+// are placed last and ties are broken by name; reverse inverts the whole order.
+func sortFiles(files []string, stats map[string]fileStat, order sortOrder, reverse bool) []string {
+	less := func(x, y string) bool {
+		if order != sortByName {
+			a, aOK := stats[x]
+			b, bOK := stats[y]
+			if aOK != bOK {
+				return aOK
+			}
+			switch {
+			case !aOK:
+			case order == sortBySize && a.size != b.size:
+				return a.size > b.size
+			case order == sortByModTime && !a.modTime.Equal(b.modTime):
+				return a.modTime.After(b.modTime)
+			}
+		}
+		return x < y
+	}
+
+	sorted := append([]string(nil), files...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if reverse {
+			return less(sorted[j], sorted[i])
+		}
+		return less(sorted[i], sorted[j])
+	})
+	return sorted
+}
+
+// groupEnabledFirst returns a copy of files with the enabled files before
+// the others, keeping the order within both groups
+func groupEnabledFirst(files []string, enabled map[string]bool) []string {
+	grouped := make([]string, 0, len(files))
+	for _, name := range files {
+		if enabled[name] {
+			grouped = append(grouped, name)
+		}
+	}
+	for _, name := range files {
+		if !enabled[name] {
+			grouped = append(grouped, name)
+		}
+	}
+	return grouped
+}
@@ -0,0 +1,150 @@
+package ui
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestSortFiles(t *testing.T) {
+	now := time.Now()
+	files := []string{"b.conf", "d.conf", "a.conf", "c.conf", "unknown.conf"}
+	stats := map[string]fileStat{
+		"a.conf": {size: 10, modTime: now.Add(-time.Hour)},
+		"b.conf": {size: 300, modTime: now.Add(-48 * time.Hour)},
+		"c.conf": {size: 10, modTime: now},
+		"d.conf": {size: 5, modTime: now.Add(-time.Hour)},
+	}
+
+	// a.conf and c.conf tie on size, a.conf and d.conf on mtime;
+	// ties are broken by name
+	tests := []struct {
+		name    string
+		order   sortOrder
+		reverse bool
+		want    []string
+	}{
+		{"name", sortByName, false, []string{"a.conf", "b.conf", "c.conf", "d.conf", "unknown.conf"}},
+		{"name reversed", sortByName, true, []string{"unknown.conf", "d.conf", "c.conf", "b.conf", "a.conf"}},
+		{"size", sortBySize, false, []string{"b.conf", "a.conf", "c.conf", "d.conf", "unknown.conf"}},
+		{"size reversed", sortBySize, true, []string{"unknown.conf", "d.conf", "c.conf", "a.conf", "b.conf"}},
+		{"mtime", sortByModTime, false, []string{"c.conf", "a.conf", "d.conf", "b.conf", "unknown.conf"}},
+		{"mtime reversed", sortByModTime, true, []string{"unknown.conf", "b.conf", "d.conf", "a.conf", "c.conf"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sortFiles(files, stats, tt.order, tt.reverse); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("sortFiles() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if !reflect.DeepEqual(files, []string{"b.conf", "d.conf", "a.conf", "c.conf", "unknown.conf"}) {
+		t.Errorf("sortFiles() modified its input: %v", files)
+	}
+}
+
+func TestFormatFileColumns(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		item fileItem
+		want string
+	}{
+		{"bytes", fileItem{size: 512, modTime: now.Add(-30 * time.Second)}, "   512B  just now"},
+		{"kilobytes", fileItem{size: 1536, modTime: now.Add(-5 * time.Minute)}, "   1.5K    5m ago"},
+		{"megabytes", fileItem{size: 3 << 20, modTime: now.Add(-3 * time.Hour)}, "   3.0M    3h ago"},
+		{"days", fileItem{size: 0, modTime: now.Add(-72 * time.Hour)}, "     0B    3d ago"},
+		{"stat failed", fileItem{size: -1}, "      ?         ?"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatFileColumns(tt.item, now); got != tt.want {
+				t.Errorf("formatFileColumns() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestUpdate_CycleSort tests that s cycles the sort order and keeps the cursor
+// on the same file
+func TestUpdate_CycleSort(t *testing.T) {
+	m := newTestModel([]string{"a.conf", "b.conf", "c.conf"})
+	m.stats = map[string]fileStat{
+		"a.conf": {size: 1},
+		"b.conf": {size: 2},
+		"c.conf": {size: 3},
+	}
+	m.list.Select(1) // b.conf
+
+	m = update(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'s'}})
+	if m.sortOrder != sortBySize {
+		t.Fatalf("sortOrder = %s, want size", m.sortOrder)
+	}
+	if got := visibleNames(m); !reflect.DeepEqual(got, []string{"c.conf", "b.conf", "a.conf"}) {
+		t.Errorf("visible = %v, want largest first", got)
+	}
+	if item := m.list.SelectedItem().(fileItem); item.name != "b.conf" {
+		t.Errorf("cursor on %s, want b.conf", item.name)
+	}
+
+	m = update(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'S'}})
+	if got := visibleNames(m); !reflect.DeepEqual(got, []string{"a.conf", "b.conf", "c.conf"}) {
+		t.Errorf("visible = %v after S, want smallest first", got)
+	}
+
+	m = update(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'s'}})
+	m = update(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'s'}})
+	if m.sortOrder != sortByName {
+		t.Errorf("sortOrder = %s after a full cycle, want name", m.sortOrder)
+	}
+}
+
+// TestUpdate_SortKeepsSelectionOrder tests that the selection is returned in
+// the order items were selected, not in display order
+func TestUpdate_SortKeepsSelectionOrder(t *testing.T) {
+	m := newTestModel([]string{"a.conf", "b.conf"}, "b.conf", "a.conf")
+	m = update(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'S'}})
+	if !reflect.DeepEqual(m.selectedOrder, []string{"b.conf", "a.conf"}) {
+		t.Errorf("selectedOrder = %v, want [b.conf a.conf]", m.selectedOrder)
+	}
+}
+
+// TestUpdate_EnabledFirst tests that e groups the selected items first, each
+// group in name order, without changing the selection order
+func TestUpdate_EnabledFirst(t *testing.T) {
+	m := newTestModel([]string{"a.conf", "b.conf", "c.conf", "d.conf", "e.conf"}, "d.conf", "b.conf")
+	m.list.Select(2) // c.conf
+
+	m = update(m, keyRune('e'))
+	if !m.enabledFirst {
+		t.Fatal("enabledFirst = false after e, want true")
+	}
+	if got := visibleNames(m); !reflect.DeepEqual(got, []string{"b.conf", "d.conf", "a.conf", "c.conf", "e.conf"}) {
+		t.Errorf("visible = %v, want selected items first", got)
+	}
+	if item := m.list.SelectedItem().(fileItem); item.name != "c.conf" {
+		t.Errorf("cursor on %s, want c.conf", item.name)
+	}
+	if !reflect.DeepEqual(m.selectedOrder, []string{"d.conf", "b.conf"}) {
+		t.Errorf("selectedOrder = %v, want [d.conf b.conf]", m.selectedOrder)
+	}
+
+	m = update(m, keyRune('e'))
+	if got := visibleNames(m); !reflect.DeepEqual(got, []string{"a.conf", "b.conf", "c.conf", "d.conf", "e.conf"}) {
+		t.Errorf("visible = %v after a second e, want name order", got)
+	}
+}
+
+func TestParseSortOrder(t *testing.T) {
+	for name, want := range map[string]sortOrder{"": sortByName, "name": sortByName, "size": sortBySize, "mtime": sortByModTime} {
+		if got := parseSortOrder(name); got != want {
+			t.Errorf("parseSortOrder(%q) = %s, want %s", name, got, want)
+		}
+	}
+}
@@ -13,7 +13,7 @@
 //   - Smart cursor positioning: Maintains cursor position across mode switches
 //   - Vim-style navigation: j/k for up/down, g/G for top/bottom
 //   - Bulk operations: ctrl+a to select all, ctrl+d to deselect all
-//   - Visual feedback: Bold for linked items, gray for unlinked, bold green for cursor
+//   - Visual feedback: Checkbox glyphs, bold for linked items, gray for unlinked, bold green for cursor
 //
 // # Multi-Select UI
 //
@@ -25,20 +25,40 @@
 //   - g/G: Jump to top/bottom
 //   - PgUp/PgDn or ctrl+b/ctrl+f: Page up/down
 //   - ctrl+a: Select all visible items
-//   - ctrl+d: Deselect all items
-//   - /: Enter filter mode to search
+//   - ctrl+d: Deselect all items (only the visible items while a filter is applied)
+//   - i: Invert the selection of all files
+//   - v: Start a range at the cursor; v or Space toggles every item from there
+//     to the cursor, Esc cancels
+//   - /: Enter filter mode to search (prefix with # to filter by tag)
+//   - ctrl+r: Switch the filter between fuzzy and regex matching while filtering
+//   - ctrl+g: Switch matching the beginning of the file contents on and off while filtering
+//   - ctrl+s / ctrl+x: Select / deselect all items matching the filter and clear it
 //   - h: Toggle between showing all items or only linked items
-//   - Enter: Confirm selection
-//   - ?: Toggle help (ctrl+c to abort in extended help)
-//   - ctrl+c: Abort (shown in extended help with ?)
+//   - Enter: Confirm selection (with ConfirmApply, a summary of the changes asks first)
+//   - t: Toggle showing the current symlink target of linked items
+//   - p: Toggle a preview pane with the first lines of the file at the cursor
+//   - s: Cycle the sort order between name, size and modification time
+//   - S: Reverse the sort order
+//   - e: Toggle grouping the selected items before the others
+//   - w: Save the selection as a named preset (with SavePreset)
+//   - R: Give the link of the item a custom name, applied on confirm (with SetLinkNames)
+//   - O: Reveal the item's link in the file manager (with AllowOpen)
+//   - ?: Show all shortcuts in a help overlay (/ filters the entries)
+//   - I: Show the source and target paths, title, counts and version (any key closes)
+//   - d: Show the links the selection would create and remove (any key closes)
+//   - ctrl+c: Abort (listed in the help overlay)
+//   - Mouse (with Mouse): click a row to move the cursor, click it again to
+//     select/deselect, scroll with the wheel
+//   - Watch (with Watch): files created or removed in the source or target
+//     appear and vanish without restarting
 //
 // Example usage:
 //
 //	sourceDir := "/path/to/source"
 //	targetDir := "/path/to/target"
-//	selected, err := ui.ShowMultiSelect(sourceDir, targetDir, "Select files")
+//	selected, err := ui.ShowFileSelect(sourceDir, targetDir, "Select files", ui.FileSelectOptions{})
 //	if err != nil {
-//	    // Handle error (user aborted or other error)
+//	    // Handle error (ui.ErrUserAborted or other error)
 //	}
 //	// Use selected files
 //
@@ -46,14 +66,19 @@
 //
 // The confirmation dialog shows a simple yes/no prompt:
 //
-//	confirmed, err := ui.ShowConfirmation("Delete all files?")
+//	confirmed, err := ui.ShowConfirmation("Delete all files?", config.DefaultTheme())
 //	if err != nil {
-//	    // Handle error (user aborted)
+//	    // Handle error (ui.ErrUserAborted)
 //	}
 //	if confirmed {
 //	    // Perform action
 //	}
 //
+// ShowChoice asks with more answers, e.g. y/n/a/q for one item after the other:
+//
+//	choices := []string{ui.ChoiceYes, ui.ChoiceNo, ui.ChoiceAll, ui.ChoiceQuit}
+//	choice, err := ui.ShowChoice("Link nginx.conf?", choices, config.DefaultTheme())
+//
 // # Performance Considerations
 //
 // The UI is optimized for large lists (1000+ items) with:
@@ -64,25 +89,43 @@
 package ui
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"strings"
+	"sync/atomic"
+	"time"
 
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/fsnotify/fsnotify"
+	"github.com/marco-arnold/lnka/internal/config"
+	"github.com/marco-arnold/lnka/internal/filesystem"
 )
 
+// ErrUserAborted is returned by the prompts when the user aborts with ctrl+c
+var ErrUserAborted = errors.New("user aborted")
+
+// ErrNoFilesAvailable is returned by ShowFileSelect when the source directory
+// has no files to select
+var ErrNoFilesAvailable = errors.New("no files available to enable")
+
 // UI layout constants
 const (
-	// helpBarReservedLines is the number of lines reserved for the help bar
-	// and optional list chrome (title when set, help bar)
-	helpBarReservedLines = 4
+	// footerReservedLines is the number of lines reserved below the list for
+	// the selection count footer and a status or prompt line; the help bar
+	// between them takes the lines it renders to (see reservedLines)
+	footerReservedLines = 2
 )
 
 // lipgloss styles for terminal UI
 var (
 	stylePrompt = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("10")) // Bold Green
+	styleDanger = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("9"))  // Bold Red
+	styleFooter = lipgloss.NewStyle().Faint(true).PaddingLeft(2)                  // Dimmed, aligned with the help bar
 
 	// Help bar style for confirmation dialog - inverse video spanning full width
 	styleHelpBar = lipgloss.NewStyle().
@@ -92,19 +135,36 @@ var (
 
 // keyMap defines all keyboard shortcuts for the multi-select UI
 type keyMap struct {
-	Quit        key.Binding // Abort operation (ctrl+c) - shown only in full help
-	Confirm     key.Binding // Confirm selection (enter)
-	Filter      key.Binding // Enter filter mode (/)
-	HideToggle  key.Binding // Toggle hide unlinked items (h)
-	Select      key.Binding // Select/deselect item at cursor (space)
-	Up          key.Binding // Move cursor up (↑/k)
-	Down        key.Binding // Move cursor down (↓/j)
-	GoTop       key.Binding // Jump to top (g)
-	GoBottom    key.Binding // Jump to bottom (G)
-	SelectAll   key.Binding // Select all visible items (ctrl+a)
-	DeselectAll key.Binding // Deselect all items (ctrl+d)
-	PageDown    key.Binding // Page down (pgdn/ctrl+f)
-	PageUp      key.Binding // Page up (pgup/ctrl+b)
+	Quit          key.Binding // Abort operation (ctrl+c) - shown only in full help
+	Confirm       key.Binding // Confirm selection (enter)
+	Filter        key.Binding // Enter filter mode (/)
+	FilterMode    key.Binding // Switch the filter between fuzzy and regex matching while filtering (ctrl+r)
+	FilterContent key.Binding // Switch matching file contents on and off while filtering (ctrl+g)
+	GrepSelect    key.Binding // Select all items matching the filter and clear it (ctrl+s)
+	GrepDeselect  key.Binding // Deselect all items matching the filter and clear it (ctrl+x)
+	HideToggle    key.Binding // Toggle hide unlinked items (h)
+	Select        key.Binding // Select/deselect item at cursor (space)
+	Up            key.Binding // Move cursor up (↑/k)
+	Down          key.Binding // Move cursor down (↓/j)
+	GoTop         key.Binding // Jump to top (g)
+	GoBottom      key.Binding // Jump to bottom (G)
+	SelectAll     key.Binding // Select all visible items (ctrl+a)
+	DeselectAll   key.Binding // Deselect all items, or the visible items of an applied filter (ctrl+d)
+	Invert        key.Binding // Invert the selection of all files (i)
+	Range         key.Binding // Start a range at the cursor, then toggle the items up to the cursor (v)
+	PageDown      key.Binding // Page down (pgdn/ctrl+f)
+	PageUp        key.Binding // Page up (pgup/ctrl+b)
+	Help          key.Binding // Show help overlay (?)
+	Info          key.Binding // Show source, target, counts and version (I)
+	Diff          key.Binding // Show the pending changes of the selection (d)
+	Open          key.Binding // Reveal the item's link in the file manager (O) - requires AllowOpen
+	Targets       key.Binding // Toggle showing symlink targets (t)
+	Preview       key.Binding // Toggle the preview pane (p)
+	Sort          key.Binding // Cycle sort order between name, size and mtime (s)
+	SortReverse   key.Binding // Reverse the sort order (S)
+	EnabledFirst  key.Binding // Toggle grouping the selected items first (e)
+	SavePreset    key.Binding // Save the selection as a named preset (w) - requires SavePreset
+	Rename        key.Binding // Give the item's link a custom name (R) - requires SetLinkNames
 }
 
 // defaultKeyMap returns the default keyboard shortcuts for the multi-select UI.
@@ -124,6 +184,22 @@ func defaultKeyMap() *keyMap {
 			key.WithKeys("/"),
 			key.WithHelp("/", "filter"),
 		),
+		FilterMode: key.NewBinding(
+			key.WithKeys("ctrl+r"),
+			key.WithHelp("ctrl+r", "switch filter between fuzzy/regex"),
+		),
+		FilterContent: key.NewBinding(
+			key.WithKeys("ctrl+g"),
+			key.WithHelp("ctrl+g", "also match file contents in the filter"),
+		),
+		GrepSelect: key.NewBinding(
+			key.WithKeys("ctrl+s"),
+			key.WithHelp("ctrl+s", "select all filter matches and clear the filter"),
+		),
+		GrepDeselect: key.NewBinding(
+			key.WithKeys("ctrl+x"),
+			key.WithHelp("ctrl+x", "deselect all filter matches and clear the filter"),
+		),
 		HideToggle: key.NewBinding(
 			key.WithKeys("h"),
 			key.WithHelp("h", "toggle"),
@@ -156,6 +232,14 @@ func defaultKeyMap() *keyMap {
 			key.WithKeys("ctrl+d"),
 			key.WithHelp("ctrl+d", "deselect all"),
 		),
+		Invert: key.NewBinding(
+			key.WithKeys("i"),
+			key.WithHelp("i", "invert selection"),
+		),
+		Range: key.NewBinding(
+			key.WithKeys("v"),
+			key.WithHelp("v", "toggle range from anchor"),
+		),
 		PageDown: key.NewBinding(
 			key.WithKeys("pgdown", "ctrl+f"),
 			key.WithHelp("pgdn/ctrl+f", "page down"),
@@ -164,53 +248,189 @@ func defaultKeyMap() *keyMap {
 			key.WithKeys("pgup", "ctrl+b"),
 			key.WithHelp("pgup/ctrl+b", "page up"),
 		),
+		Help: key.NewBinding(
+			key.WithKeys("?"),
+			key.WithHelp("?", "help"),
+		),
+		Info: key.NewBinding(
+			key.WithKeys("I"),
+			key.WithHelp("I", "show directories and version"),
+		),
+		Diff: key.NewBinding(
+			key.WithKeys("d"),
+			key.WithHelp("d", "show pending changes"),
+		),
+		Open: key.NewBinding(
+			key.WithKeys("O"),
+			key.WithHelp("O", "open in file manager"),
+			key.WithDisabled(),
+		),
+		Targets: key.NewBinding(
+			key.WithKeys("t"),
+			key.WithHelp("t", "toggle link targets"),
+		),
+		Preview: key.NewBinding(
+			key.WithKeys("p"),
+			key.WithHelp("p", "toggle file preview"),
+		),
+		Sort: key.NewBinding(
+			key.WithKeys("s"),
+			key.WithHelp("s", "sort by name/size/mtime"),
+		),
+		SortReverse: key.NewBinding(
+			key.WithKeys("S"),
+			key.WithHelp("S", "reverse sort order"),
+		),
+		EnabledFirst: key.NewBinding(
+			key.WithKeys("e"),
+			key.WithHelp("e", "group selected items first"),
+		),
+		SavePreset: key.NewBinding(
+			key.WithKeys("w"),
+			key.WithHelp("w", "save selection as preset"),
+			key.WithDisabled(),
+		),
+		Rename: key.NewBinding(
+			key.WithKeys("R"),
+			key.WithHelp("R", "rename link"),
+			key.WithDisabled(),
+		),
 	}
 }
 
 // multiSelectModel is the Bubble Tea model for multi-select UI
 // It manages the state for selecting multiple items from a list
 type multiSelectModel struct {
-	list           list.Model      // Bubble Tea list component (replaces: choices, cursor, filter, filtered)
-	selectedMap    map[string]bool // Selected items (renamed from 'selected' for clarity)
-	selectedOrder  []string        // Order of selection for result (preserved for consistent output)
-	sourceDir      string          // Source directory for Commands
-	targetDir      string          // Target directory for Commands
-	availableFiles []string        // Unfiltered source list (for rebuilding items after mode changes)
-	aborted        bool            // User pressed ctrl+c
-	hideUnlinked   bool            // Hide unlinked items when true
-	loading        bool            // Files are being loaded
-	err            error           // Error during loading
-	keys           *keyMap         // Keyboard shortcuts (now a pointer following Go conventions)
+	list           list.Model          // Bubble Tea list component (replaces: choices, cursor, filter, filtered)
+	selectedMap    map[string]bool     // Selected items (renamed from 'selected' for clarity)
+	selectedOrder  []string            // Order of selection for result (preserved for consistent output)
+	sourceDir      string              // Source directory for Commands
+	targetDir      string              // Target directory for Commands
+	extraTargets   []string            // Further target directories getting the same selection
+	alsoCheck      []string            // Directories whose links to source files are shown (not managed)
+	fsOpts         filesystem.Options  // Options for recognizing enabled symlinks
+	ctx            context.Context     // Canceled when the UI exits, abandoning pending loads (nil = never)
+	loadTimeout    time.Duration       // Time limit for loading the files (0 = no limit)
+	availableFiles []string            // Unfiltered source list (for rebuilding items after mode changes)
+	aborted        bool                // User pressed ctrl+c
+	hideUnlinked   bool                // Hide unlinked items when true
+	loading        bool                // Files are being loaded
+	spinner        spinner.Model       // Animated while loading
+	scanned        *atomic.Int64       // Source files scanned so far while loading (nil = not counted)
+	err            error               // Error during loading
+	keys           *keyMap             // Keyboard shortcuts (now a pointer following Go conventions)
+	tags           map[string][]string // Optional user-defined tags per file name
+	targets        map[string]string   // Current symlink target per linked file name
+	partial        map[string]string   // Files linked in some but not all targets -> "2/3 targets"
+	external       map[string][]string // Files linked from an --also-check directory -> those links
+	delegate       fileItemDelegate    // Item renderer (replaced on target detail toggle)
+	stats          map[string]fileStat // Size and mtime per source file (missing = stat failed)
+	sortOrder      sortOrder           // Current item order
+	sortReverse    bool                // Reverse the item order
+	enabledFirst   bool                // Group the selected items before the others
+	filterMode     filterMode          // How the / filter matches file names
+	noColor        bool                // Render without styling (see config.Theme.NoColor)
+	caseSensitive  bool                // Whether the / filter matches the case of the term
+	contentSearch  bool                // Whether the / filter also matches the beginning of the file contents
+	contents       *contentCache       // File contents read for the content filter
+	pageSize       int                 // Items per page (0 = fit the window)
+
+	pendingCursorFile string // Cursor target waiting for asynchronous filter results
+	restoreCursor     string // File to put the cursor on once the files are loaded ("" = top)
+
+	anchorIndex int    // Visible index of the anchor of a started range selection
+	anchorFile  string // File at the anchor ("" = no range started)
+
+	showHelp bool        // Help overlay is displayed instead of the list
+	showInfo bool        // Info panel is displayed instead of the list
+	showDiff bool        // Pending changes are displayed instead of the list
+	help     helpOverlay // Help overlay state
+
+	status string // One-line message shown below the list until the next key
+
+	preselect    []string                                // Initial selection replacing the enabled files (nil = enabled files)
+	savePreset   func(name string, files []string) error // Stores a named preset (nil = disabled)
+	presetPrompt bool                                    // Preset name is being typed
+	presetName   string                                  // Preset name typed so far
+
+	linkNames   map[string]string // Custom link names typed with R (source file -> link name)
+	loadedLinks map[string]string // Enabled files linked under another name when loaded (-> link name)
+	renameFile  string            // File whose link name is being typed ("" = none)
+	renameInput string            // Link name typed so far
+
+	confirmApply   bool         // Ask before confirming a changed selection
+	confirming     bool         // Apply confirmation is displayed instead of the list
+	confirm        confirmModel // Apply confirmation state
+	initialEnabled []string     // Files enabled when the list was loaded
+
+	width, height  int    // Terminal size (split between list and preview pane)
+	showPreview    bool   // Preview pane is displayed next to the list
+	preview        string // Preview content of previewName
+	previewName    string // File the preview content belongs to
+	previewPending string // File under the cursor the preview was last requested for
+	previewSeq     int    // Sequence number of the latest preview request
+
+	watcher  *fsnotify.Watcher // Reports changes of the source and target (nil = not watching)
+	watchSeq int               // Sequence number of the latest change
 }
 
 // Init initializes the model
 // Returns command to load available and enabled files asynchronously
 func (m multiSelectModel) Init() tea.Cmd {
-	logDebug("Init: starting async load from sourceDir=%s, targetDir=%s", m.sourceDir, m.targetDir)
-	return loadFilesCmd(m.sourceDir, m.targetDir)
+	logDebug("Init", "starting async load", "sourceDir", m.sourceDir, "targetDir", m.targetDir)
+	cmds := []tea.Cmd{m.loadFilesCmd(), m.spinner.Tick}
+	if m.watcher != nil {
+		cmds = append(cmds, waitForChangeCmd(m.watcher))
+	}
+	return tea.Batch(cmds...)
+}
+
+// loadFilesCmd creates a command loading the files of the model's directories
+func (m multiSelectModel) loadFilesCmd() tea.Cmd {
+	return loadFilesCmd(m.ctx, m.sourceDir, m.targetDir, m.extraTargets, m.alsoCheck, m.fsOpts, m.loadTimeout, m.scanned)
 }
 
 // Update handles messages
+// The preview pane follows the cursor after every message.
 func (m multiSelectModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	model, cmd := m.handleMsg(msg)
+	next, ok := model.(multiSelectModel)
+	if !ok || !next.showPreview || next.loading {
+		return model, cmd
+	}
+	return next, tea.Batch(cmd, next.schedulePreview())
+}
+
+// handleMsg processes a single message for Update
+func (m multiSelectModel) handleMsg(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 
 	// Handle async file loading message
 	case filesLoadedMsg:
 		if msg.err != nil {
-			logDebug("filesLoadedMsg: error loading files: %v", msg.err)
+			logDebug("filesLoadedMsg", "error loading files", "error", msg.err)
 			m.err = msg.err
 			m.aborted = true
 			return m, tea.Quit
 		}
 
-		logDebug("filesLoadedMsg: loaded %d available files, %d enabled files",
-			len(msg.availableFiles), len(msg.enabledFiles))
+		logDebug("filesLoadedMsg", "loaded files", "available", len(msg.availableFiles), "enabled", len(msg.enabledFiles))
 
-		// Store available files
+		// Store available files and link targets
 		m.availableFiles = msg.availableFiles
-
-		// Build initial selection map from enabled files
-		for _, file := range msg.enabledFiles {
+		m.targets = msg.targets
+		m.partial = msg.partial
+		m.external = msg.external
+		m.loadedLinks = msg.renamed
+		m.stats = msg.stats
+
+		// Build initial selection map from enabled files (or the preselection)
+		m.initialEnabled = msg.enabledFiles
+		initial := msg.enabledFiles
+		if m.preselect != nil {
+			initial = m.preselect
+		}
+		for _, file := range initial {
 			m.selectedMap[file] = true
 			m.selectedOrder = append(m.selectedOrder, file)
 		}
@@ -219,74 +439,334 @@ func (m multiSelectModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		items := m.buildItemList()
 		cmd := m.list.SetItems(items)
 		m.loading = false
-		logDebug("filesLoadedMsg: loading complete, displaying %d items", len(items))
+
+		// Start where the previous run left off (the top if the file is gone)
+		m.setCursorToFile(m.restoreCursor)
+		logDebug("filesLoadedMsg", "loading complete", "items", len(items))
 
 		return m, cmd
 
+	case spinner.TickMsg:
+		// Stop animating once the files are loaded
+		if !m.loading {
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.spinner, cmd = m.spinner.Update(msg)
+		return m, cmd
+
 	case itemsRefreshedMsg:
 		// Item list was rebuilt (e.g., after hideUnlinked toggle)
 		cmd := m.list.SetItems(msg.items)
 
+		// With an active filter the visible items are recomputed asynchronously,
+		// so the cursor can only be positioned once the filter matches arrive
+		if m.list.FilterState() != list.Unfiltered {
+			m.pendingCursorFile = msg.cursorFileName
+			return m, cmd
+		}
+
 		// If a cursor filename was specified, try to position cursor on that item
 		if msg.cursorFileName != "" {
 			m.setCursorToFile(msg.cursorFileName)
 		}
+		m.revalidateAnchor()
+
+		return m, cmd
 
+	case list.FilterMatchesMsg:
+		// Filter results arrived; apply a cursor position pending from a rebuild
+		var cmd tea.Cmd
+		m.list, cmd = m.list.Update(msg)
+		if m.pendingCursorFile != "" {
+			m.setCursorToFile(m.pendingCursorFile)
+			m.pendingCursorFile = ""
+		}
+		m.revalidateAnchor()
 		return m, cmd
 
+	case presetSavedMsg:
+		if msg.err != nil {
+			m.status = fmt.Sprintf("Cannot save preset: %v", msg.err)
+		} else {
+			m.status = fmt.Sprintf("Saved preset %s", msg.name)
+		}
+		return m, nil
+
+	case openResultMsg:
+		if msg.err != nil {
+			logDebug("Open", "failed", "error", msg.err)
+			m.status = fmt.Sprintf("Cannot open file manager: %v", msg.err)
+		}
+		return m, nil
+
+	case previewTickMsg:
+		// Only the latest request is read, earlier ones were superseded
+		if msg.seq != m.previewSeq || !m.showPreview {
+			return m, nil
+		}
+		return m, loadPreviewCmd(m.sourceDir, m.previewPending, m.list.Height())
+
+	case dirChangedMsg:
+		// Wait for the burst of changes to end before reloading
+		m.watchSeq++
+		return m, tea.Batch(waitForChangeCmd(m.watcher), watchTickCmd(m.watchSeq))
+
+	case watchTickMsg:
+		// The initial load already sees the change
+		if msg.seq != m.watchSeq || m.loading {
+			return m, nil
+		}
+		return m, m.reloadFilesCmd()
+
+	case filesReloadedMsg:
+		if m.loading {
+			return m, nil
+		}
+		return m, m.applyReload(msg.filesLoadedMsg)
+
+	case previewLoadedMsg:
+		if msg.name == m.previewPending {
+			m.preview = msg.content
+			m.previewName = msg.name
+		}
+		return m, nil
+
 	case tea.WindowSizeMsg:
-		m.list.SetSize(msg.Width, msg.Height-helpBarReservedLines)
+		m.width, m.height = msg.Width, msg.Height
+		m.resizeList()
+		m.confirm.width = msg.Width
 		return m, nil
 
+	case tea.MouseMsg:
+		return m.updateMouse(msg)
+
 	case tea.KeyMsg:
 		// Don't handle keys while loading
 		if m.loading {
 			return m, nil
 		}
 
-		// Check if list is in filter mode
+		// Any key dismisses the status message
+		m.status = ""
+
+		// Distinguish typing a filter query (Filtering) from browsing the
+		// filtered results (FilterApplied). Only text entry suppresses our
+		// keys; selection works on the filtered items once a filter is applied.
 		wasFiltering := m.list.FilterState() == list.Filtering
 		isFiltering := wasFiltering
 
 		// Handle quit keys
 		if key.Matches(msg, m.keys.Quit) {
-			logDebug("Quit: user aborted")
+			logDebug("Quit", "user aborted")
 			m.aborted = true
 			return m, tea.Quit
 		}
 
+		// Handle filter mode switch (ctrl+r) while typing a filter
+		if key.Matches(msg, m.keys.FilterMode) && isFiltering {
+			if m.filterMode == filterRegex {
+				m.filterMode = filterFuzzy
+			} else {
+				m.filterMode = filterRegex
+			}
+			m.applyFilterMode()
+			logDebug("Filter", "", "filterMode", int(m.filterMode))
+
+			// Re-run the filter with the new matching and keep typing
+			m.list.SetFilterText(m.list.FilterValue())
+			m.list.SetFilterState(list.Filtering)
+			return m, nil
+		}
+
+		// Handle content matching switch (ctrl+g) while typing a filter
+		if key.Matches(msg, m.keys.FilterContent) && isFiltering {
+			m.contentSearch = !m.contentSearch
+			m.applyFilterMode()
+			logDebug("Filter", "", "contentSearch", m.contentSearch)
+
+			m.list.SetFilterText(m.list.FilterValue())
+			m.list.SetFilterState(list.Filtering)
+			return m, nil
+		}
+
+		// While the apply confirmation is open it receives all other keys
+		if m.confirming {
+			return m.updateConfirm(msg)
+		}
+
+		// While the preset prompt is open it receives all other keys
+		if m.presetPrompt {
+			return m.updatePresetPrompt(msg)
+		}
+
+		// While the link name prompt is open it receives all other keys
+		if m.renameFile != "" {
+			return m.updateRenamePrompt(msg)
+		}
+
+		// While the help overlay is open it receives all other keys
+		if m.showHelp {
+			var closed bool
+			m.help, closed = m.help.Update(msg)
+			if closed {
+				m.showHelp = false
+			}
+			return m, nil
+		}
+
+		// Any key closes the info panel and the pending changes
+		if m.showInfo || m.showDiff {
+			m.showInfo, m.showDiff = false, false
+			return m, nil
+		}
+
+		// Handle grep-select (ctrl+s) and grep-deselect (ctrl+x) of the
+		// items matching a typed or applied filter
+		if key.Matches(msg, m.keys.GrepSelect) {
+			return m, m.grepSelect(true)
+		}
+		if key.Matches(msg, m.keys.GrepDeselect) {
+			return m, m.grepSelect(false)
+		}
+
+		// Handle info panel (I)
+		if key.Matches(msg, m.keys.Info) && !isFiltering {
+			m.showInfo = true
+			return m, nil
+		}
+
+		// Handle pending changes (d)
+		if key.Matches(msg, m.keys.Diff) && !isFiltering {
+			m.showDiff = true
+			return m, nil
+		}
+
+		// Handle help overlay (?)
+		if key.Matches(msg, m.keys.Help) && !isFiltering {
+			m.help = newHelpOverlay(m.keys)
+			m.showHelp = true
+			return m, nil
+		}
+
+		// Handle save preset (w)
+		if key.Matches(msg, m.keys.SavePreset) && !isFiltering {
+			m.presetPrompt = true
+			m.presetName = ""
+			return m, nil
+		}
+
+		// Handle link rename (R)
+		if key.Matches(msg, m.keys.Rename) && !isFiltering {
+			m.startRename()
+			return m, nil
+		}
+
+		// Handle open in file manager (O)
+		if key.Matches(msg, m.keys.Open) && !isFiltering {
+			if item, ok := m.list.SelectedItem().(fileItem); ok {
+				return m, openInFileManagerCmd(m.targetDir, item.name)
+			}
+			return m, nil
+		}
+
+		// Handle link target detail toggle (t)
+		if key.Matches(msg, m.keys.Targets) && !isFiltering {
+			m.delegate.showTargets = !m.delegate.showTargets
+			m.list.SetDelegate(m.delegate)
+			logDebug("Targets", "", "showTargets", m.delegate.showTargets)
+			return m, nil
+		}
+
+		// Handle preview pane toggle (p)
+		if key.Matches(msg, m.keys.Preview) && !isFiltering {
+			m.showPreview = !m.showPreview
+			m.preview, m.previewName, m.previewPending = "", "", ""
+			m.resizeList()
+			logDebug("Preview", "", "showPreview", m.showPreview)
+			return m, nil
+		}
+
+		// Handle sort order cycle (s)
+		if key.Matches(msg, m.keys.Sort) && !isFiltering {
+			var currentFileName string
+			if fi, ok := m.list.SelectedItem().(fileItem); ok {
+				currentFileName = fi.name
+			}
+
+			m.sortOrder = m.sortOrder.next()
+			logDebug("Sort", "preserving cursor", "sortOrder", m.sortOrder.String(), "cursor", currentFileName)
+			return m, m.rebuildItemsCmdWithCursor(currentFileName)
+		}
+
+		// Handle sort order reversal (S)
+		if key.Matches(msg, m.keys.SortReverse) && !isFiltering {
+			var currentFileName string
+			if fi, ok := m.list.SelectedItem().(fileItem); ok {
+				currentFileName = fi.name
+			}
+
+			m.sortReverse = !m.sortReverse
+			logDebug("Sort", "preserving cursor", "sortReverse", m.sortReverse, "cursor", currentFileName)
+			return m, m.rebuildItemsCmdWithCursor(currentFileName)
+		}
+
+		// Handle grouping the selected items first (e)
+		if key.Matches(msg, m.keys.EnabledFirst) && !isFiltering {
+			var currentFileName string
+			if fi, ok := m.list.SelectedItem().(fileItem); ok {
+				currentFileName = fi.name
+			}
+
+			m.enabledFirst = !m.enabledFirst
+			logDebug("Sort", "preserving cursor", "enabledFirst", m.enabledFirst, "cursor", currentFileName)
+			return m, m.rebuildItemsCmdWithCursor(currentFileName)
+		}
+
 		// Handle confirm key (Enter)
 		if key.Matches(msg, m.keys.Confirm) {
 			if !isFiltering {
-				logDebug("Confirm: user confirmed selection with %d items", len(m.selectedMap))
+				if m.confirmApply {
+					if create, remove, rename := m.pendingChanges(); create+remove+rename > 0 {
+						message := fmt.Sprintf("Will create %d, remove %d", create, remove)
+						if rename > 0 {
+							message += fmt.Sprintf(", rename %d", rename)
+						}
+						m.confirm = newConfirmModel(message+". Apply?", true)
+						m.confirm.noColor = m.noColor
+						m.confirm.width = m.list.Width()
+						m.confirming = true
+						return m, nil
+					}
+				}
+				logDebug("Confirm", "user confirmed selection", "selectedCount", len(m.selectedMap))
 				return m, tea.Quit
 			}
 			// If filtering, let list.Model handle it
 		}
 
+		// Finish a started range (v or Space) or cancel it (Esc)
+		if m.anchorFile != "" && !isFiltering {
+			if key.Matches(msg, m.keys.Range, m.keys.Select) {
+				return m, m.toggleRange()
+			}
+			if msg.Type == tea.KeyEsc {
+				logDebug("Range", "canceled")
+				m.cancelRange()
+				return m, nil
+			}
+		}
+
+		// Handle range start (v)
+		if key.Matches(msg, m.keys.Range) && !isFiltering {
+			m.startRange()
+			return m, nil
+		}
+
 		// Handle toggle selection (Space)
 		if key.Matches(msg, m.keys.Select) {
 			if !isFiltering {
-				// Remember current cursor position before toggling
-				var currentFileName string
-				if item := m.list.SelectedItem(); item != nil {
-					if fi, ok := item.(fileItem); ok {
-						currentFileName = fi.name
-					}
-				}
-
-				modeChanged := m.handleToggleSelection()
-				logDebug("Toggle: selectedCount=%d", len(m.selectedMap))
-
-				// If mode changed (hideUnlinked was auto-disabled), rebuild entire list
-				// and preserve cursor on the toggled file
-				if modeChanged {
-					return m, m.rebuildItemsCmdWithCursor(currentFileName)
-				}
-
-				// Otherwise just refresh current item
-				cmd := m.refreshCurrentItem()
-				return m, cmd
+				return m, m.toggleCursorItem()
 			}
 		}
 
@@ -311,7 +791,7 @@ func (m multiSelectModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 						}
 					}
 				}
-				logDebug("SelectAll: selected %d new items (total: %d), preserving cursor on: %s", len(m.selectedMap)-countBefore, len(m.selectedMap), currentFileName)
+				logDebug("SelectAll", "preserving cursor", "added", len(m.selectedMap)-countBefore, "selectedCount", len(m.selectedMap), "cursor", currentFileName)
 				// Refresh all items while preserving cursor position
 				return m, m.rebuildItemsCmdWithCursor(currentFileName)
 			}
@@ -328,13 +808,25 @@ func (m multiSelectModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					}
 				}
 
-				logDebug("DeselectAll: clearing all selections")
-				m.selectedMap = make(map[string]bool)
-				m.selectedOrder = []string{}
+				// Like select all, an applied filter limits deselection to the
+				// visible items
+				if m.list.FilterState() == list.FilterApplied {
+					for _, item := range m.list.VisibleItems() {
+						if fi, ok := item.(fileItem); ok && m.selectedMap[fi.name] {
+							delete(m.selectedMap, fi.name)
+							m.removeFromOrder(fi.name)
+						}
+					}
+					logDebug("DeselectAll", "deselected visible items", "selectedCount", len(m.selectedMap))
+				} else {
+					logDebug("DeselectAll", "clearing all selections")
+					m.selectedMap = make(map[string]bool)
+					m.selectedOrder = []string{}
+				}
 
 				// Auto-disable hideUnlinked if no items are selected
 				if m.shouldDisableHideMode() {
-					logDebug("DeselectAll: disabling hideUnlinked mode, preserving cursor on: %s", currentFileName)
+					logDebug("DeselectAll", "disabling hideUnlinked mode", "cursor", currentFileName)
 					m.hideUnlinked = false
 				}
 
@@ -342,6 +834,21 @@ func (m multiSelectModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		}
 
+		// Handle invert selection (i)
+		if key.Matches(msg, m.keys.Invert) {
+			if !isFiltering {
+				// Remember current cursor position before inverting
+				var currentFileName string
+				if fi, ok := m.list.SelectedItem().(fileItem); ok {
+					currentFileName = fi.name
+				}
+
+				m.handleInvertSelection()
+				logDebug("Invert", "preserving cursor", "selectedCount", len(m.selectedMap), "cursor", currentFileName)
+				return m, m.rebuildItemsCmdWithCursor(currentFileName)
+			}
+		}
+
 		// Handle hide toggle (H)
 		if key.Matches(msg, m.keys.HideToggle) {
 			if !isFiltering && len(m.selectedMap) > 0 {
@@ -354,7 +861,7 @@ func (m multiSelectModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 
 				m.hideUnlinked = !m.hideUnlinked
-				logDebug("HideToggle: hideUnlinked=%t, preserving cursor on: %s", m.hideUnlinked, currentFileName)
+				logDebug("HideToggle", "preserving cursor", "hideUnlinked", m.hideUnlinked, "cursor", currentFileName)
 				return m, m.rebuildItemsCmdWithCursor(currentFileName)
 			}
 		}
@@ -366,9 +873,9 @@ func (m multiSelectModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// Log filter mode changes
 		nowFiltering := m.list.FilterState() == list.Filtering
 		if !wasFiltering && nowFiltering {
-			logDebug("Filter: entered filter mode")
+			logDebug("Filter", "entered filter mode")
 		} else if wasFiltering && !nowFiltering {
-			logDebug("Filter: exited filter mode")
+			logDebug("Filter", "exited filter mode")
 		}
 
 		return m, cmd
@@ -380,25 +887,102 @@ func (m multiSelectModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
+// updateConfirm handles a key press in the apply confirmation
+// The embedded confirmModel returns a command only once a choice was made
+func (m multiSelectModel) updateConfirm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	confirm, cmd := m.confirm.Update(msg)
+	m.confirm = confirm.(confirmModel)
+	if cmd == nil {
+		return m, nil
+	}
+
+	m.confirming = false
+	switch {
+	case m.confirm.aborted:
+		logDebug("Confirm", "user aborted")
+		m.aborted = true
+		return m, tea.Quit
+	case m.confirm.choice() == ChoiceYes:
+		logDebug("Confirm", "user confirmed selection", "selectedCount", len(m.selectedMap))
+		return m, tea.Quit
+	}
+	logDebug("Confirm", "user declined, back to the list")
+	return m, nil
+}
+
+// pendingChanges counts the links the selection would create, remove and
+// rename compared to the files enabled when the list was loaded
+func (m *multiSelectModel) pendingChanges() (create, remove, rename int) {
+	changes := m.selectionDiff()
+	return len(changes.Create), len(changes.Remove), len(changes.Rename)
+}
+
+// updatePresetPrompt handles a key press while the preset name is typed
+// Enter saves the selection under the typed name, esc cancels
+func (m multiSelectModel) updatePresetPrompt(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEnter:
+		m.presetPrompt = false
+		if m.presetName == "" {
+			return m, nil
+		}
+		files := append([]string(nil), m.selectedOrder...)
+		return m, savePresetCmd(m.savePreset, m.presetName, files)
+	case tea.KeyEsc:
+		m.presetPrompt = false
+	case tea.KeyBackspace:
+		if r := []rune(m.presetName); len(r) > 0 {
+			m.presetName = string(r[:len(r)-1])
+		}
+	case tea.KeyRunes:
+		m.presetName += string(msg.Runes)
+	}
+	return m, nil
+}
+
 // buildItemList builds the list of items from availableFiles
-// Respects hideUnlinked mode
+// Respects hideUnlinked mode and the sort order
 func (m *multiSelectModel) buildItemList() []list.Item {
 	// Preallocate with capacity to avoid reallocation
-	items := make([]list.Item, 0, len(m.availableFiles))
-	for _, name := range m.availableFiles {
+	files := sortFiles(m.availableFiles, m.stats, m.sortOrder, m.sortReverse)
+	if m.enabledFirst {
+		files = groupEnabledFirst(files, m.selectedMap)
+	}
+
+	items := make([]list.Item, 0, len(files))
+	for _, name := range files {
 		// In hideUnlinked mode, only show selected files
 		if m.hideUnlinked && !m.selectedMap[name] {
 			continue
 		}
 
-		items = append(items, fileItem{
-			name:      name,
-			isEnabled: m.selectedMap[name],
-		})
+		items = append(items, m.newFileItem(name))
 	}
 	return items
 }
 
+// newFileItem creates a list item for the given file name
+// reflecting its current selection state and tags
+func (m *multiSelectModel) newFileItem(name string) fileItem {
+	item := fileItem{
+		name:      name,
+		isEnabled: m.selectedMap[name],
+		tags:      m.tags[name],
+		target:    m.targets[name],
+		partial:   m.partial[name],
+		external:  m.external[name],
+		size:      -1,
+	}
+	if link := m.linkNameOf(name); link != m.defaultLinkName(name) {
+		item.linkName = link
+	}
+	if stat, ok := m.stats[name]; ok {
+		item.size = stat.size
+		item.modTime = stat.modTime
+	}
+	return item
+}
+
 // handleToggleSelection toggles selection of the current item
 // Returns true if hideUnlinked mode was auto-disabled (requires full list rebuild)
 func (m *multiSelectModel) handleToggleSelection() bool {
@@ -422,7 +1006,7 @@ func (m *multiSelectModel) handleToggleSelection() bool {
 
 		// Auto-disable hideUnlinked if no items are selected
 		if m.shouldDisableHideMode() {
-			logDebug("Toggle: auto-disabling hideUnlinked mode (last item deselected)")
+			logDebug("Toggle", "auto-disabling hideUnlinked mode (last item deselected)")
 			m.hideUnlinked = false
 			modeChanged = true
 		}
@@ -435,6 +1019,181 @@ func (m *multiSelectModel) handleToggleSelection() bool {
 	return modeChanged
 }
 
+// selectionCount describes how many files are selected, e.g. "3 selected / 12 total"
+func (m *multiSelectModel) selectionCount() string {
+	count := fmt.Sprintf("%d selected / %d total", len(m.selectedMap), len(m.availableFiles))
+	if m.hideUnlinked {
+		count += fmt.Sprintf(" (%d unlinked hidden)", len(m.availableFiles)-len(m.selectedMap))
+	}
+	return count
+}
+
+// bindPageKeys adds the page keys of the keymap to the list, whose defaults
+// lack ctrl+f/ctrl+b
+func bindPageKeys(l *list.Model, keys *keyMap) {
+	l.KeyMap.NextPage.SetKeys(append(l.KeyMap.NextPage.Keys(), keys.PageDown.Keys()...)...)
+	l.KeyMap.PrevPage.SetKeys(append(l.KeyMap.PrevPage.Keys(), keys.PageUp.Keys()...)...)
+}
+
+// pageIndicator returns the page and cursor position for the footer, e.g.
+// " · page 2/5 · 27/98" ("" while everything fits on one page)
+func (m *multiSelectModel) pageIndicator() string {
+	if m.list.Paginator.TotalPages <= 1 {
+		return ""
+	}
+	return fmt.Sprintf(" · page %d/%d · %d/%d", m.list.Paginator.Page+1, m.list.Paginator.TotalPages,
+		m.list.Index()+1, len(m.list.VisibleItems()))
+}
+
+// applyFilterMode installs the filter function and prompt of the filter mode
+func (m *multiSelectModel) applyFilterMode() {
+	m.list.Filter = newItemFilter(m.tags, m.filterMode, m.caseSensitive)
+	prompt := "Filter"
+	if m.filterMode == filterRegex {
+		prompt = "Regex"
+	}
+	if m.contentSearch {
+		if m.contents == nil {
+			m.contents = newContentCache(m.sourceDir)
+		}
+		m.list.Filter = newContentFilter(m.list.Filter, m.contents, m.filterMode, m.caseSensitive)
+		prompt += " (+content)"
+	}
+	m.list.FilterInput.Prompt = prompt + ": "
+}
+
+// filterError returns the error of an invalid regex filter ("" = valid)
+func (m *multiSelectModel) filterError() string {
+	if m.filterMode != filterRegex || m.list.FilterState() == list.Unfiltered {
+		return ""
+	}
+	if _, err := compileFilterRegex(m.list.FilterValue(), m.caseSensitive); err != nil {
+		return fmt.Sprintf("Invalid regex: %v", err)
+	}
+	return ""
+}
+
+// toggleCursorItem toggles the selection of the item at the cursor and
+// returns the command refreshing the list
+func (m *multiSelectModel) toggleCursorItem() tea.Cmd {
+	// Remember current cursor position before toggling
+	var currentFileName string
+	if item := m.list.SelectedItem(); item != nil {
+		if fi, ok := item.(fileItem); ok {
+			currentFileName = fi.name
+		}
+	}
+
+	modeChanged := m.handleToggleSelection()
+	logDebug("Toggle", "", "selectedCount", len(m.selectedMap))
+
+	// If mode changed (hideUnlinked was auto-disabled), rebuild entire list
+	// and preserve cursor on the toggled file
+	if modeChanged {
+		return m.rebuildItemsCmdWithCursor(currentFileName)
+	}
+
+	// Otherwise just refresh current item
+	return m.refreshCurrentItem()
+}
+
+// updateMouse handles mouse events on the list
+// A click moves the cursor to the clicked row, clicking the row at the cursor
+// toggles it; the wheel moves the cursor. Mouse events are ignored while a
+// filter is typed or an overlay is displayed.
+func (m multiSelectModel) updateMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	if m.loading || m.confirming || m.presetPrompt || m.renameFile != "" || m.showHelp || m.showInfo || m.showDiff || m.list.FilterState() == list.Filtering {
+		return m, nil
+	}
+
+	switch {
+	case msg.Button == tea.MouseButtonWheelUp:
+		m.list.CursorUp()
+	case msg.Button == tea.MouseButtonWheelDown:
+		m.list.CursorDown()
+	case msg.Button == tea.MouseButtonLeft && msg.Action == tea.MouseActionPress:
+		index, ok := m.rowIndex(msg.Y)
+		if !ok || (m.showPreview && msg.X >= m.list.Width()) {
+			return m, nil
+		}
+		m.status = ""
+		if index == m.list.Index() {
+			return m, m.toggleCursorItem()
+		}
+		m.list.Select(index)
+	}
+	return m, nil
+}
+
+// helpBarView renders the help bar below the selection count footer, the
+// short help or, when expanded, the full help
+func (m *multiSelectModel) helpBarView() string {
+	return m.list.Styles.HelpStyle.Render(m.list.Help.View(m.list))
+}
+
+// reservedLines returns the number of window lines left out of the list: the
+// footer lines and the rendered help bar, whose height depends on whether the
+// full help is expanded. The title and status bar are part of the list,
+// which sizes its items around them.
+func (m *multiSelectModel) reservedLines() int {
+	reserved := footerReservedLines
+	if !m.list.ShowHelp() {
+		reserved += lipgloss.Height(m.helpBarView())
+	}
+	return reserved
+}
+
+// listHeaderLines returns the number of lines the list renders above its
+// items. The list always renders a title area: the title bar with its bottom
+// padding when a title is set, otherwise an empty line. The status bar
+// follows when shown.
+func (m *multiSelectModel) listHeaderLines() int {
+	header := 1
+	if m.list.ShowTitle() {
+		header = lipgloss.Height(m.list.Styles.TitleBar.Render(m.list.Title))
+	}
+	if m.list.ShowStatusBar() {
+		header += lipgloss.Height(m.list.Styles.StatusBar.Render(" "))
+	}
+	return header
+}
+
+// rowIndex maps a screen row to the index of the visible item displayed there
+func (m *multiSelectModel) rowIndex(y int) (int, bool) {
+	row := y - m.listHeaderLines()
+	if row < 0 || row >= m.list.Paginator.PerPage {
+		return 0, false
+	}
+	index := m.list.Paginator.Page*m.list.Paginator.PerPage + row
+	if index >= len(m.list.VisibleItems()) {
+		return 0, false
+	}
+	return index, true
+}
+
+// handleInvertSelection flips the selection of every available file
+// Newly selected files are appended to selectedOrder in list order.
+// Returns true if hideUnlinked was auto-disabled (nothing selected anymore).
+func (m *multiSelectModel) handleInvertSelection() bool {
+	for _, name := range m.availableFiles {
+		if m.selectedMap[name] {
+			delete(m.selectedMap, name)
+			m.removeFromOrder(name)
+		} else {
+			m.selectedMap[name] = true
+			m.selectedOrder = append(m.selectedOrder, name)
+		}
+	}
+
+	// Auto-disable hideUnlinked if no items are selected
+	if m.shouldDisableHideMode() {
+		logDebug("Invert", "auto-disabling hideUnlinked mode (nothing selected)")
+		m.hideUnlinked = false
+		return true
+	}
+	return false
+}
+
 // removeFromOrder removes a file from selectedOrder
 func (m *multiSelectModel) removeFromOrder(file string) {
 	for i, f := range m.selectedOrder {
@@ -453,8 +1212,9 @@ func (m *multiSelectModel) shouldDisableHideMode() bool {
 
 // refreshCurrentItem refreshes the currently selected item to update its description
 func (m *multiSelectModel) refreshCurrentItem() tea.Cmd {
-	// Get current index
-	index := m.list.Index()
+	// Get current index in the unfiltered item list
+	// (Index() is relative to the filtered items while a filter is applied)
+	index := m.list.GlobalIndex()
 	if index < 0 || index >= len(m.list.Items()) {
 		return nil
 	}
@@ -466,14 +1226,8 @@ func (m *multiSelectModel) refreshCurrentItem() tea.Cmd {
 		return nil
 	}
 
-	// Update item with new enabled state
-	updatedItem := fileItem{
-		name:      fi.name,
-		isEnabled: m.selectedMap[fi.name],
-	}
-
-	// Replace item in list
-	return m.list.SetItem(index, updatedItem)
+	// Replace item in list with updated enabled state
+	return m.list.SetItem(index, m.newFileItem(fi.name))
 }
 
 // rebuildItemsCmdWithCursor returns a command that rebuilds the item list
@@ -491,23 +1245,24 @@ func (m *multiSelectModel) rebuildItemsCmdWithCursor(fileName string) tea.Cmd {
 
 // setCursorToFile positions the cursor on the item with the specified filename
 // If the file is not found in the current list, cursor stays at current position
+// Only visible items are considered, so this respects an applied filter
 func (m *multiSelectModel) setCursorToFile(fileName string) {
 	if fileName == "" {
 		return
 	}
 
-	items := m.list.Items()
+	items := m.list.VisibleItems()
 	for i, item := range items {
 		if fi, ok := item.(fileItem); ok {
 			if fi.name == fileName {
 				m.list.Select(i)
-				logDebug("setCursorToFile: positioned cursor on %s at index %d", fileName, i)
+				logDebug("setCursorToFile", "positioned cursor", "file", fileName, "index", i)
 				return
 			}
 		}
 	}
 
-	logDebug("setCursorToFile: file %s not found in list, cursor unchanged", fileName)
+	logDebug("setCursorToFile", "file not found in list, cursor unchanged", "file", fileName)
 }
 
 // View renders the UI
@@ -519,7 +1274,10 @@ func (m multiSelectModel) View() string {
 
 	// Show loading state
 	if m.loading {
-		return "Loading files...\n"
+		if m.scanned != nil && m.scanned.Load() > 0 {
+			return fmt.Sprintf("%s Loading files... scanned %d files\n", m.spinner.View(), m.scanned.Load())
+		}
+		return fmt.Sprintf("%s Loading files...\n", m.spinner.View())
 	}
 
 	// Show error state
@@ -527,8 +1285,143 @@ func (m multiSelectModel) View() string {
 		return fmt.Sprintf("Error: %v\n", m.err)
 	}
 
+	if m.showHelp {
+		return m.help.View()
+	}
+
+	if m.showInfo {
+		return m.infoView()
+	}
+
+	if m.showDiff {
+		return m.diffView()
+	}
+
+	if m.confirming {
+		return m.confirm.View()
+	}
+
 	// Delegate everything to list.Model (includes built-in help bar)
-	return m.list.View()
+	body := m.list.View()
+	if m.showPreview {
+		body = lipgloss.JoinHorizontal(lipgloss.Top, body, m.previewView())
+	}
+	footer := m.selectionCount() + m.pageIndicator()
+	if m.contentSearch && m.list.FilterState() != list.Unfiltered {
+		footer += " · matching contents"
+	}
+	if m.anchorFile != "" {
+		footer += fmt.Sprintf(" · range from %s (v/space: toggle, esc: cancel)", m.anchorFile)
+	}
+	body += "\n" + styleFooter.Render(footer)
+	if !m.list.ShowHelp() {
+		body += "\n" + m.helpBarView()
+	}
+	if m.presetPrompt {
+		return body + "\n" + stylePrompt.Render("Save preset as: ") + m.presetName + "█"
+	}
+	if m.renameFile != "" {
+		return body + "\n" + stylePrompt.Render("Link "+m.renameFile+" as: ") + m.renameInput + "█"
+	}
+	if m.status != "" {
+		return body + "\n" + styleDanger.Render(m.status)
+	}
+	if msg := m.filterError(); msg != "" {
+		return body + "\n" + styleDanger.Render(msg)
+	}
+	return body
+}
+
+// FileSelectOptions configures optional features of ShowFileSelect
+type FileSelectOptions struct {
+	// Filesystem controls how enabled symlinks are recognized
+	Filesystem filesystem.Options
+
+	// Tags maps file names to user-defined tags, displayed after each name
+	// and searchable with a "#tag" filter prefix
+	Tags map[string][]string
+
+	// AllowOpen enables the O key to reveal a link in the file manager
+	AllowOpen bool
+
+	// ASCIICheckboxes renders "[x]"/"[ ]" instead of the unicode checkbox set
+	ASCIICheckboxes bool
+
+	// Theme sets the item colors (empty colors use the default theme)
+	Theme config.Theme
+
+	// SortBy is the initial sort order: "name" (default), "size" or "mtime"
+	SortBy string
+
+	// EnabledFirst groups the selected items before the others, each group
+	// in the sort order; items stay in place when toggled until the list is
+	// rebuilt (e.g. with e or s)
+	EnabledFirst bool
+
+	// FilterMode is the initial matching of the / filter: "fuzzy" (default,
+	// with tag queries) or "regex"; ctrl+r switches it while filtering
+	FilterMode string
+
+	// PageSize fixes the number of items shown per page, e.g. for consistent
+	// screenshots (0 = as many as fit the window; fewer if the window is
+	// too small)
+	PageSize int
+
+	// CaseSensitive makes the / filter match the case of the term; names are
+	// matched ignoring case otherwise
+	CaseSensitive bool
+
+	// ContentSearch makes the / filter also match the first 4 KB of each
+	// text file (read once when first filtered); ctrl+g switches it while filtering
+	ContentSearch bool
+
+	// Preselect replaces the currently enabled files as the initial
+	// selection when non-nil (e.g. a loaded preset)
+	Preselect []string
+
+	// SavePreset enables the w key, which prompts for a name and stores the
+	// current selection under it
+	SavePreset func(name string, files []string) error
+
+	// ConfirmApply asks for confirmation with a summary of the changes
+	// after Enter; declining returns to the list
+	ConfirmApply bool
+
+	// Mouse enables clicking rows (click again to toggle) and scrolling
+	// with the wheel
+	Mouse bool
+
+	// Watch refreshes the list when files are created or removed in the
+	// source or target directory (subdirectories are not watched)
+	Watch bool
+
+	// Cursor is the file to put the cursor on after loading, e.g. where the
+	// previous run left off ("" or a missing file = top)
+	Cursor string
+
+	// SaveCursor, when set, receives the file under the cursor when the list
+	// is closed (also when aborted)
+	SaveCursor func(name string)
+
+	// Timeout bounds each load of the source and target directory
+	// (0 = no limit); a load taking longer fails with an error
+	Timeout time.Duration
+
+	// ExtraTargets are further target directories getting the same
+	// selection. Files linked in any target start selected, files linked in
+	// only some of them are marked with the number of targets.
+	ExtraTargets []string
+
+	// SetLinkNames enables the R key, which gives the link of a file a custom
+	// name. It receives the link names of the renamed files (source file ->
+	// link name, see filesystem.Options.LinkNames) once the selection is
+	// confirmed.
+	SetLinkNames func(linkNames map[string]string)
+
+	// AlsoCheck are directories managed elsewhere, e.g. other targets of
+	// the same source. Files linked from them are marked in the list; the
+	// selection is not affected.
+	AlsoCheck []string
 }
 
 // ShowFileSelect displays an interactive multi-select list in the terminal.
@@ -539,6 +1432,7 @@ func (m multiSelectModel) View() string {
 // and bulk operations.
 //
 // Visual feedback:
+//   - Checkbox glyph: [✓] (or [x] with ASCIICheckboxes) for selected, [ ] for unselected
 //   - Bold text: Linked/selected items
 //   - Gray text: Unlinked items
 //   - Bold green with ">": Current cursor position
@@ -546,12 +1440,13 @@ func (m multiSelectModel) View() string {
 // UI elements (conditional):
 //   - Title: Shown only if title parameter is not empty
 //   - Status bar: Shown only when title is set
-//   - Help bar: Always visible (press ? to toggle short/full help)
+//   - Help bar: Always visible (press ? for the searchable help overlay)
 //
 // Parameters:
 //   - sourceDir: Path to the source directory containing available files
 //   - targetDir: Path to the target directory with symlinks
 //   - title: Optional title to display above the list (empty = no title/status bar)
+//   - opts: Optional features (tags, ...), zero value for defaults
 //
 // Returns:
 //   - []string: Ordered list of selected items (in selection order)
@@ -563,32 +1458,42 @@ func (m multiSelectModel) View() string {
 //   - h: Toggle hide unlinked items (only when items are selected)
 //   - /: Enter filter mode
 //   - Enter: Confirm selection and exit
-//   - ?: Show full help
+//   - ?: Show help overlay with all shortcuts
 //
-// Additional shortcuts (full help with ?):
+// Additional shortcuts (listed in the help overlay):
 //   - g/G: Jump to top/bottom of list
 //   - PgUp/PgDn, ctrl+b/ctrl+f: Page up/down
 //   - ctrl+a: Select all visible items
-//   - ctrl+d: Deselect all items
+//   - ctrl+d: Deselect all items (only the visible items while a filter is applied)
+//   - i: Invert the selection of all files
+//   - v: Toggle a range of items from an anchor to the cursor
 //   - ctrl+c: Abort without saving
 //
 // Example:
 //
 //	sourceDir := "/path/to/source/configs"
 //	targetDir := "/path/to/target/configs"
-//	selected, err := ShowFileSelect(sourceDir, targetDir, "Select files to link")
+//	selected, err := ShowFileSelect(sourceDir, targetDir, "Select files to link", FileSelectOptions{})
 //	if err != nil {
-//	    if strings.Contains(err.Error(), "user aborted") {
+//	    if errors.Is(err, ErrUserAborted) {
 //	        fmt.Println("Operation cancelled")
 //	        return
 //	    }
 //	    log.Fatal(err)
 //	}
 //	fmt.Printf("Selected: %v\n", selected)
-func ShowFileSelect(sourceDir, targetDir, title string) ([]string, error) {
+func ShowFileSelect(sourceDir, targetDir, title string, opts FileSelectOptions) ([]string, error) {
 	// Create empty list (items loaded asynchronously in Init())
 	// Use our custom delegate for simple rendering
-	delegate := fileItemDelegate{}
+	usePlainProfile(opts.Theme.NoColor)
+	styles := newItemStyles(opts.Theme)
+	delegate := fileItemDelegate{glyphs: unicodeCheckboxes, styles: &styles}
+	switch {
+	case opts.ASCIICheckboxes:
+		delegate.glyphs = asciiCheckboxes
+	case opts.Theme.NoColor:
+		delegate.glyphs = plainMarkers
+	}
 
 	l := list.New([]list.Item{}, delegate, 0, 0) // width=0, height=0 (set via WindowSizeMsg)
 
@@ -604,33 +1509,82 @@ func ShowFileSelect(sourceDir, targetDir, title string) ([]string, error) {
 	l.SetShowPagination(false)
 	l.SetFilteringEnabled(true)
 
+	// The help bar is rendered by View, below the selection count footer
+	l.SetShowHelp(false)
+
 	// Create model with our custom keys
 	keys := defaultKeyMap()
+	keys.Open.SetEnabled(opts.AllowOpen)
+	keys.SavePreset.SetEnabled(opts.SavePreset != nil)
+	keys.Rename.SetEnabled(opts.SetLinkNames != nil)
+
+	bindPageKeys(&l, keys)
+
+	// The help overlay replaces the list's built-in full help
+	l.KeyMap.ShowFullHelp.SetEnabled(false)
+	l.KeyMap.CloseFullHelp.SetEnabled(false)
 
 	// Add our custom keybindings to the list's help
 	l.AdditionalShortHelpKeys = func() []key.Binding {
-		return []key.Binding{keys.Select, keys.HideToggle, keys.Filter, keys.Confirm}
+		return []key.Binding{keys.Select, keys.HideToggle, keys.Filter, keys.Confirm, keys.Help}
 	}
 
-	l.AdditionalFullHelpKeys = func() []key.Binding {
-		return []key.Binding{
-			keys.Select, keys.SelectAll, keys.DeselectAll,
-			keys.HideToggle, keys.Filter, keys.Confirm, keys.Quit,
-		}
+	// Warnings would garble the UI, so they go to the debug log
+	fsOpts := opts.Filesystem
+	fsOpts.Warnf = func(format string, args ...any) {
+		logDebug("Warning", fmt.Sprintf(format, args...))
 	}
 
 	m := multiSelectModel{
 		list:          l,
 		sourceDir:     sourceDir,
 		targetDir:     targetDir,
+		extraTargets:  opts.ExtraTargets,
+		alsoCheck:     opts.AlsoCheck,
 		selectedMap:   make(map[string]bool),
 		selectedOrder: []string{},
 		loading:       true,
+		spinner:       spinner.New(spinner.WithSpinner(spinner.Dot)),
+		scanned:       new(atomic.Int64),
 		keys:          keys,
+		tags:          opts.Tags,
+		fsOpts:        fsOpts,
+		delegate:      delegate,
+		sortOrder:     parseSortOrder(opts.SortBy),
+		enabledFirst:  opts.EnabledFirst,
+		preselect:     opts.Preselect,
+		savePreset:    opts.SavePreset,
+		confirmApply:  opts.ConfirmApply,
+		filterMode:    parseFilterMode(opts.FilterMode),
+		noColor:       opts.Theme.NoColor,
+		caseSensitive: opts.CaseSensitive,
+		contentSearch: opts.ContentSearch,
+		pageSize:      opts.PageSize,
+		loadTimeout:   opts.Timeout,
+		restoreCursor: opts.Cursor,
+	}
+	m.applyFilterMode()
+
+	// Loads still running when the UI exits are abandoned
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	m.ctx = ctx
+
+	if opts.Watch {
+		watcher, err := newDirWatcher(append([]string{sourceDir, targetDir}, opts.ExtraTargets...)...)
+		if err != nil {
+			return nil, err
+		}
+		defer watcher.Close()
+		m.watcher = watcher
 	}
 
 	// Run the program
-	p := tea.NewProgram(m)
+	var programOpts []tea.ProgramOption
+	if opts.Mouse {
+		programOpts = append(programOpts, tea.WithMouseCellMotion())
+	}
+	p := tea.NewProgram(m, programOpts...)
 	finalModel, err := p.Run()
 	if err != nil {
 		return nil, fmt.Errorf("program error: %w", err)
@@ -642,9 +1596,16 @@ func ShowFileSelect(sourceDir, targetDir, title string) ([]string, error) {
 		return nil, fmt.Errorf("unexpected model type")
 	}
 
+	// Remember the cursor for the next run
+	if opts.SaveCursor != nil {
+		if fi, ok := model.list.SelectedItem().(fileItem); ok {
+			opts.SaveCursor(fi.name)
+		}
+	}
+
 	// Check if aborted
 	if model.aborted {
-		return nil, fmt.Errorf("user aborted")
+		return nil, ErrUserAborted
 	}
 
 	// Check for errors during loading
@@ -654,20 +1615,56 @@ func ShowFileSelect(sourceDir, targetDir, title string) ([]string, error) {
 
 	// Check if no files were found
 	if len(model.availableFiles) == 0 {
-		return nil, fmt.Errorf("no files available to enable")
+		return nil, ErrNoFilesAvailable
+	}
+
+	if opts.SetLinkNames != nil && len(model.linkNames) > 0 {
+		opts.SetLinkNames(model.linkNames)
 	}
 
 	// Return selected items in order
 	return model.selectedOrder, nil
 }
 
+// Answers of the dialogs shown with ShowChoice
+const (
+	ChoiceYes  = "Yes"
+	ChoiceNo   = "No"
+	ChoiceAll  = "All"  // Yes for this and all remaining items
+	ChoiceQuit = "Quit" // No for this and all remaining items
+)
+
+// yesNoChoices are the answers of a yes/no confirmation
+var yesNoChoices = []string{ChoiceYes, ChoiceNo}
+
 // confirmModel is the Bubble Tea model for confirmation dialog
-// It manages the state for a yes/no confirmation prompt
+// It manages the state for a prompt with several answers (yes/no by default)
 type confirmModel struct {
-	message  string
-	selected bool // true = yes, false = no
-	aborted  bool
-	width    int // Terminal width
+	message string
+	choices []string // Answers in display order, each also picked by its first letter
+	cursor  int      // Index of the highlighted answer
+	aborted bool
+	danger  bool // Render message as a warning for destructive operations
+	noColor bool // Mark the choice with text instead of styling (see config.Theme.NoColor)
+	width   int  // Terminal width
+}
+
+// newConfirmModel creates a yes/no confirmation dialog starting on the
+// default choice
+func newConfirmModel(message string, defaultYes bool) confirmModel {
+	m := confirmModel{
+		message: message,
+		choices: yesNoChoices,
+	}
+	if !defaultYes {
+		m.cursor = 1
+	}
+	return m
+}
+
+// choice returns the highlighted answer
+func (m confirmModel) choice() string {
+	return m.choices[m.cursor]
 }
 
 // Init initializes the confirmation dialog model.
@@ -680,9 +1677,8 @@ func (m confirmModel) Init() tea.Cmd {
 // Supported keys:
 //   - ctrl+c: Abort dialog
 //   - enter: Confirm current selection
-//   - left/right: Navigate between Yes/No
-//   - y/Y: Quick select Yes and confirm
-//   - n/N: Quick select No and confirm
+//   - left/right: Navigate between the answers
+//   - First letter of an answer (e.g. y/n): Quick select it and confirm
 func (m confirmModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
@@ -697,22 +1693,43 @@ func (m confirmModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case "enter":
 			return m, tea.Quit
 		case "left":
-			m.selected = true
+			m.cursor = max(m.cursor-1, 0)
 		case "right":
-			m.selected = false
-		case "y", "Y":
-			m.selected = true
-			return m, tea.Quit
-		case "n", "N":
-			m.selected = false
-			return m, tea.Quit
+			m.cursor = min(m.cursor+1, len(m.choices)-1)
+		default:
+			if i := choiceIndex(m.choices, msg.String()); i >= 0 {
+				m.cursor = i
+				return m, tea.Quit
+			}
 		}
 	}
 	return m, nil
 }
 
+// choiceIndex returns the index of the answer starting with the typed key
+// (case-insensitive), or -1 if none does
+func choiceIndex(choices []string, typed string) int {
+	if len([]rune(typed)) != 1 {
+		return -1
+	}
+	for i, choice := range choices {
+		if strings.EqualFold(choiceKey(choice), typed) {
+			return i
+		}
+	}
+	return -1
+}
+
+// choiceKey returns the lowercase first letter picking an answer
+func choiceKey(choice string) string {
+	for _, r := range choice {
+		return strings.ToLower(string(r))
+	}
+	return ""
+}
+
 // View renders the confirmation dialog UI.
-// Shows the message, Yes/No buttons with highlighting, and a help bar at the bottom.
+// Shows the message, the answer buttons with highlighting, and a help bar at the bottom.
 // Returns empty string if dialog was aborted.
 func (m confirmModel) View() string {
 	if m.aborted {
@@ -720,25 +1737,40 @@ func (m confirmModel) View() string {
 	}
 
 	var b strings.Builder
-	b.WriteString(m.message)
-	b.WriteString("\n\n")
-
-	var yesText, noText string
-	if m.selected {
-		yesText = stylePrompt.Render("[ Yes ]")
-		noText = "[ No ]"
+	if m.danger {
+		b.WriteString(styleDanger.Render(m.message))
 	} else {
-		yesText = "[ Yes ]"
-		noText = stylePrompt.Render("[ No ]")
+		b.WriteString(m.message)
 	}
+	b.WriteString("\n\n")
 
-	b.WriteString(yesText)
-	b.WriteString("  ")
-	b.WriteString(noText)
+	buttons := make([]string, len(m.choices))
+	keys := make([]string, len(m.choices))
+	for i, choice := range m.choices {
+		button := "[ " + choice + " ]"
+		switch {
+		case m.noColor && i == m.cursor:
+			// Without styling the choice is marked like the list cursor
+			button = "> " + button
+		case m.noColor:
+			button = "  " + button
+		case i == m.cursor:
+			button = stylePrompt.Render(button)
+		}
+		buttons[i] = button
+		keys[i] = choiceKey(choice)
+	}
+	b.WriteString(strings.Join(buttons, "  "))
 	b.WriteString("\n\n")
 
-	// Help text as inverse bar spanning full width
-	helpText := "arrows: move | enter/y/n: select | ctrl+c: abort"
+	// Help text as inverse bar spanning full width, naming the choice
+	// Enter picks
+	helpText := fmt.Sprintf("arrows: move | enter: %s | %s: select | ctrl+c: abort",
+		strings.ToLower(m.choice()), strings.Join(keys, "/"))
+	if m.noColor {
+		b.WriteString(helpText)
+		return b.String()
+	}
 	helpBar := styleHelpBar.Width(m.width).Render(" " + helpText)
 	b.WriteString(helpBar)
 
@@ -752,6 +1784,7 @@ func (m confirmModel) View() string {
 //
 // Parameters:
 //   - message: The question or message to display to the user
+//   - theme: The colors of the UI; NoColor marks the choice with text only
 //
 // Returns:
 //   - bool: true if user confirmed (pressed enter on "Yes"), false if declined
@@ -761,13 +1794,13 @@ func (m confirmModel) View() string {
 //   - ←/→: Move between Yes/No
 //   - y/n: Quick select Yes/No and confirm
 //   - Enter: Confirm current selection
-//   - ctrl+c: Abort (returns error with "user aborted")
+//   - ctrl+c: Abort (returns ErrUserAborted)
 //
 // Example:
 //
-//	confirmed, err := ShowConfirmation("Delete all files?")
+//	confirmed, err := ShowConfirmation("Delete all files?", config.DefaultTheme())
 //	if err != nil {
-//	    if strings.Contains(err.Error(), "user aborted") {
+//	    if errors.Is(err, ErrUserAborted) {
 //	        fmt.Println("Cancelled")
 //	        return
 //	    }
@@ -780,27 +1813,82 @@ func (m confirmModel) View() string {
 //	    // User selected "No"
 //	    fmt.Println("Keeping files")
 //	}
-func ShowConfirmation(message string) (bool, error) {
-	m := confirmModel{
-		message:  message,
-		selected: true, // Default to Yes
+func ShowConfirmation(message string, theme config.Theme) (bool, error) {
+	return ShowConfirmationWithDefault(message, true, theme)
+}
+
+// ShowConfirmationWithDefault displays a yes/no confirmation dialog like
+// ShowConfirmation with the cursor starting on "Yes" if defaultYes is true
+// and on "No" otherwise, so pressing Enter picks the default.
+func ShowConfirmationWithDefault(message string, defaultYes bool, theme config.Theme) (bool, error) {
+	m := newConfirmModel(message, defaultYes)
+	m.noColor = theme.NoColor
+	return runConfirmation(m)
+}
+
+// ShowDangerConfirmation displays a yes/no confirmation dialog for destructive
+// operations. The message is highlighted as a warning and the cursor starts
+// on "No", so pressing Enter declines.
+//
+// Keyboard shortcuts and return values are the same as for ShowConfirmation.
+func ShowDangerConfirmation(message string, theme config.Theme) (bool, error) {
+	m := newConfirmModel(message, false) // Default to No
+	m.danger = true
+	m.noColor = theme.NoColor
+	return runConfirmation(m)
+}
+
+// ShowChoice displays a dialog asking message with several answers, e.g.
+// ChoiceYes, ChoiceNo, ChoiceAll and ChoiceQuit for classic y/n/a/q prompts
+// confirming one item after the other, and returns the chosen answer.
+//
+// The cursor starts on the first answer. Each answer is also picked by
+// typing its first letter, so the first letters must differ.
+//
+// Returns ErrUserAborted if the user aborts with ctrl+c.
+func ShowChoice(message string, choices []string, theme config.Theme) (string, error) {
+	if len(choices) == 0 {
+		return "", errors.New("no choices to show")
+	}
+	seen := make(map[string]string, len(choices))
+	for _, choice := range choices {
+		key := choiceKey(choice)
+		if key == "" {
+			return "", errors.New("empty choice")
+		}
+		if other, ok := seen[key]; ok {
+			return "", fmt.Errorf("choices %q and %q start with the same letter", other, choice)
+		}
+		seen[key] = choice
 	}
+	return runChoice(confirmModel{message: message, choices: choices, noColor: theme.NoColor})
+}
+
+// runConfirmation runs a yes/no confirmation dialog program and returns
+// whether the user confirmed
+func runConfirmation(m confirmModel, programOpts ...tea.ProgramOption) (bool, error) {
+	choice, err := runChoice(m, programOpts...)
+	return choice == ChoiceYes, err
+}
 
-	p := tea.NewProgram(m)
+// runChoice runs the dialog program and returns the user's answer
+func runChoice(m confirmModel, programOpts ...tea.ProgramOption) (string, error) {
+	usePlainProfile(m.noColor)
+	p := tea.NewProgram(m, programOpts...)
 	finalModel, err := p.Run()
 	if err != nil {
-		return false, fmt.Errorf("program error: %w", err)
+		return "", fmt.Errorf("program error: %w", err)
 	}
 
 	// Type assert with check
 	model, ok := finalModel.(confirmModel)
 	if !ok {
-		return false, fmt.Errorf("unexpected model type")
+		return "", fmt.Errorf("unexpected model type")
 	}
 
 	if model.aborted {
-		return false, fmt.Errorf("user aborted")
+		return "", ErrUserAborted
 	}
 
-	return model.selected, nil
+	return model.choice(), nil
 }
@@ -1,11 +1,19 @@
 package ui
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"reflect"
+	"strings"
+	"sync/atomic"
 	"testing"
 
 	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/marco-arnold/lnka/internal/config"
 )
 
 // TestRemoveFromOrder tests removing items from the selection order
@@ -181,6 +189,61 @@ func TestHandleToggleSelection(t *testing.T) {
 	}
 }
 
+// TestHandleInvertSelection tests flipping the selection of all available files
+func TestHandleInvertSelection(t *testing.T) {
+	delegate := list.NewDefaultDelegate()
+	l := list.New([]list.Item{
+		fileItem{name: "a.txt", isEnabled: false},
+		fileItem{name: "b.txt", isEnabled: true},
+		fileItem{name: "c.txt", isEnabled: false},
+	}, delegate, 80, 10)
+
+	m := &multiSelectModel{
+		list:           l,
+		availableFiles: []string{"a.txt", "b.txt", "c.txt"},
+		selectedMap:    map[string]bool{"b.txt": true},
+		selectedOrder:  []string{"b.txt"},
+	}
+
+	m.handleInvertSelection()
+
+	if m.selectedMap["b.txt"] {
+		t.Error("b.txt should be deselected")
+	}
+	if !reflect.DeepEqual(m.selectedOrder, []string{"a.txt", "c.txt"}) {
+		t.Errorf("selectedOrder = %v, want [a.txt c.txt]", m.selectedOrder)
+	}
+
+	// Inverting again restores the original selection
+	m.handleInvertSelection()
+
+	if !reflect.DeepEqual(m.selectedOrder, []string{"b.txt"}) || len(m.selectedMap) != 1 {
+		t.Errorf("selectedOrder = %v, want [b.txt]", m.selectedOrder)
+	}
+}
+
+// TestHandleInvertSelection_AllSelectedInHideMode tests that inverting a full
+// selection auto-disables hideUnlinked mode
+func TestHandleInvertSelection_AllSelectedInHideMode(t *testing.T) {
+	m := &multiSelectModel{
+		list:           list.New([]list.Item{}, list.NewDefaultDelegate(), 80, 10),
+		availableFiles: []string{"a.txt", "b.txt"},
+		selectedMap:    map[string]bool{"a.txt": true, "b.txt": true},
+		selectedOrder:  []string{"a.txt", "b.txt"},
+		hideUnlinked:   true,
+	}
+
+	if modeChanged := m.handleInvertSelection(); !modeChanged {
+		t.Error("handleInvertSelection() should return true when hideUnlinked is disabled")
+	}
+	if m.hideUnlinked {
+		t.Error("hideUnlinked should be disabled when nothing is selected")
+	}
+	if len(m.selectedMap) != 0 || len(m.selectedOrder) != 0 {
+		t.Errorf("selection = %v / %v, want empty", m.selectedMap, m.selectedOrder)
+	}
+}
+
 func TestHandleToggleSelection_EmptyList(t *testing.T) {
 	delegate := fileItemDelegate{}
 	l := list.New([]list.Item{}, delegate, 80, 10)
@@ -394,12 +457,49 @@ func TestView_Aborted(t *testing.T) {
 func TestView_Loading(t *testing.T) {
 	m := multiSelectModel{
 		loading: true,
+		spinner: spinner.New(spinner.WithSpinner(spinner.Line)),
+		scanned: new(atomic.Int64),
 	}
 
 	view := m.View()
-	if view != "Loading files...\n" {
+	if view != "| Loading files...\n" {
 		t.Errorf("unexpected loading view: %s", view)
 	}
+
+	m.scanned.Store(5000)
+	view = m.View()
+	if view != "| Loading files... scanned 5000 files\n" {
+		t.Errorf("unexpected loading view: %s", view)
+	}
+}
+
+// TestSpinnerTick tests that the spinner animates until the files are loaded
+func TestSpinnerTick(t *testing.T) {
+	m := multiSelectModel{
+		list:        list.New([]list.Item{}, fileItemDelegate{}, 80, 20),
+		selectedMap: make(map[string]bool),
+		keys:        defaultKeyMap(),
+		loading:     true,
+		spinner:     spinner.New(spinner.WithSpinner(spinner.Line)),
+	}
+	tick := spinner.TickMsg{ID: m.spinner.ID()}
+
+	model, cmd := m.Update(tick)
+	m = model.(multiSelectModel)
+	if cmd == nil {
+		t.Error("spinner should keep ticking while loading")
+	}
+	if got := m.spinner.View(); got != "/" {
+		t.Errorf("spinner frame = %q, want %q", got, "/")
+	}
+
+	m = update(m, filesLoadedMsg{availableFiles: []string{"a.conf"}})
+	if m.loading {
+		t.Fatal("files should be loaded")
+	}
+	if _, cmd := m.Update(tick); cmd != nil {
+		t.Error("spinner should stop once the files are loaded")
+	}
 }
 
 func TestView_Error(t *testing.T) {
@@ -413,6 +513,35 @@ func TestView_Error(t *testing.T) {
 	}
 }
 
+// TestUpdate_WindowSizeReservedLines tests that the list gets every window
+// line not used by the footer and the rendered help bar
+func TestUpdate_WindowSizeReservedLines(t *testing.T) {
+	m := newTestModel([]string{"a.conf", "b.conf"})
+	m.list.SetShowHelp(false)
+
+	m = update(m, tea.WindowSizeMsg{Width: 80, Height: 30})
+	if got := m.reservedLines(); got != 4 {
+		t.Errorf("reservedLines() = %d, want 4 (footer, padded help bar, status)", got)
+	}
+	if got := m.list.Height(); got != 26 {
+		t.Errorf("list height = %d, want 26", got)
+	}
+
+	// The expanded full help takes more lines, which the list gives up
+	m.list.Help.ShowAll = true
+	m = update(m, tea.WindowSizeMsg{Width: 80, Height: 30})
+	reserved := m.reservedLines()
+	if reserved <= 4 {
+		t.Errorf("reservedLines() = %d with full help, want more than 4", reserved)
+	}
+	if got := m.list.Height(); got != 30-reserved {
+		t.Errorf("list height = %d, want %d", got, 30-reserved)
+	}
+	if got := lipgloss.Height(m.View()); got > 30 {
+		t.Errorf("view height = %d, want it to fit the window", got)
+	}
+}
+
 // Helper function
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) && (s == substr || len(s) > len(substr) && (s[:len(substr)] == substr || s[len(s)-len(substr):] == substr || containsMiddle(s, substr)))
@@ -531,3 +660,533 @@ func TestRebuildItemsCmdWithCursor(t *testing.T) {
 		t.Errorf("Expected 3 items, got %d", len(refreshMsg.items))
 	}
 }
+
+// newTestModel creates a loaded multi-select model for the given files
+// with the given files preselected
+func newTestModel(files []string, selected ...string) multiSelectModel {
+	m := multiSelectModel{
+		list:           list.New([]list.Item{}, fileItemDelegate{}, 80, 20),
+		selectedMap:    make(map[string]bool),
+		selectedOrder:  []string{},
+		availableFiles: files,
+		keys:           defaultKeyMap(),
+	}
+	for _, name := range selected {
+		m.selectedMap[name] = true
+		m.selectedOrder = append(m.selectedOrder, name)
+	}
+	m.list.SetItems(m.buildItemList())
+	return m
+}
+
+// update sends msg to the model and synchronously feeds resulting messages
+// back into Update until no further messages are produced
+func update(m multiSelectModel, msg tea.Msg) multiSelectModel {
+	for i := 0; msg != nil && i < 10; i++ {
+		model, cmd := m.Update(msg)
+		m = model.(multiSelectModel)
+		msg = nil
+		if cmd != nil {
+			msg = cmd()
+		}
+	}
+	return m
+}
+
+// visibleNames returns the names of the currently visible (filtered) items
+func visibleNames(m multiSelectModel) []string {
+	var names []string
+	for _, item := range m.list.VisibleItems() {
+		names = append(names, item.(fileItem).name)
+	}
+	return names
+}
+
+// TestUpdate_SelectDuringFilterApplied tests that Space toggles the item under
+// the cursor while a filter is applied and refreshes that exact item
+func TestUpdate_SelectDuringFilterApplied(t *testing.T) {
+	m := newTestModel([]string{"app.conf", "db.conf", "web.conf"})
+	m.list.SetFilterText("web")
+
+	if m.list.FilterState() != list.FilterApplied {
+		t.Fatalf("expected FilterApplied state, got %v", m.list.FilterState())
+	}
+
+	m = update(m, tea.KeyMsg{Type: tea.KeySpace})
+
+	if !m.selectedMap["web.conf"] {
+		t.Error("web.conf should be selected")
+	}
+	if len(m.selectedMap) != 1 {
+		t.Errorf("expected only web.conf selected, got %v", m.selectedMap)
+	}
+
+	// The refreshed item must be web.conf, not the item at the same unfiltered index
+	for _, item := range m.list.Items() {
+		fi := item.(fileItem)
+		if fi.isEnabled != (fi.name == "web.conf") {
+			t.Errorf("item %s isEnabled = %t", fi.name, fi.isEnabled)
+		}
+	}
+}
+
+// TestUpdate_SelectAllDuringFilterApplied tests that ctrl+a selects only the
+// filtered items and keeps the cursor within the filtered view
+func TestUpdate_SelectAllDuringFilterApplied(t *testing.T) {
+	m := newTestModel([]string{"app.conf", "nginx-a.conf", "db.conf", "nginx-b.conf"})
+	m.list.SetFilterText("nginx")
+	m.list.Select(1)
+
+	m = update(m, tea.KeyMsg{Type: tea.KeyCtrlA})
+
+	expected := map[string]bool{"nginx-a.conf": true, "nginx-b.conf": true}
+	if !reflect.DeepEqual(m.selectedMap, expected) {
+		t.Errorf("selectedMap = %v, want %v", m.selectedMap, expected)
+	}
+
+	if !reflect.DeepEqual(visibleNames(m), []string{"nginx-a.conf", "nginx-b.conf"}) {
+		t.Errorf("filter should still be applied, visible = %v", visibleNames(m))
+	}
+
+	if item, ok := m.list.SelectedItem().(fileItem); !ok || item.name != "nginx-b.conf" {
+		t.Errorf("cursor should stay on nginx-b.conf, got %v", m.list.SelectedItem())
+	}
+}
+
+// TestUpdate_DeselectAllDuringFilterApplied tests that ctrl+d deselects only
+// the filtered items, matching ctrl+a
+func TestUpdate_DeselectAllDuringFilterApplied(t *testing.T) {
+	m := newTestModel([]string{"app.conf", "nginx-a.conf", "db.conf", "nginx-b.conf"},
+		"app.conf", "nginx-a.conf", "nginx-b.conf")
+	m.list.SetFilterText("nginx")
+	m.list.Select(1)
+
+	m = update(m, tea.KeyMsg{Type: tea.KeyCtrlD})
+
+	if want := map[string]bool{"app.conf": true}; !reflect.DeepEqual(m.selectedMap, want) {
+		t.Errorf("selectedMap = %v, want %v", m.selectedMap, want)
+	}
+	if !reflect.DeepEqual(m.selectedOrder, []string{"app.conf"}) {
+		t.Errorf("selectedOrder = %v, want [app.conf]", m.selectedOrder)
+	}
+	if !reflect.DeepEqual(visibleNames(m), []string{"nginx-a.conf", "nginx-b.conf"}) {
+		t.Errorf("filter should still be applied, visible = %v", visibleNames(m))
+	}
+}
+
+// TestUpdate_DeselectAllWithoutFilter tests that ctrl+d clears the whole
+// selection when no filter is applied
+func TestUpdate_DeselectAllWithoutFilter(t *testing.T) {
+	m := newTestModel([]string{"app.conf", "db.conf"}, "app.conf", "db.conf")
+
+	m = update(m, tea.KeyMsg{Type: tea.KeyCtrlD})
+
+	if len(m.selectedMap) != 0 || len(m.selectedOrder) != 0 {
+		t.Errorf("selection = %v / %v, want empty", m.selectedMap, m.selectedOrder)
+	}
+}
+
+// TestUpdate_SelectIgnoredWhileTypingFilter tests that Space is not treated
+// as a selection while the filter query is being typed
+func TestUpdate_SelectIgnoredWhileTypingFilter(t *testing.T) {
+	m := newTestModel([]string{"app.conf", "db.conf"})
+	m.list.SetFilterState(list.Filtering)
+
+	model, _ := m.Update(tea.KeyMsg{Type: tea.KeySpace})
+	m = model.(multiSelectModel)
+
+	if len(m.selectedMap) != 0 {
+		t.Errorf("Space while typing a filter should not select, got %v", m.selectedMap)
+	}
+}
+
+// TestUpdate_ToggleTargets tests that t toggles the link target detail while
+// filtering keeps matching on names only
+func TestUpdate_ToggleTargets(t *testing.T) {
+	m := newTestModel([]string{"app.conf", "db.conf"}, "app.conf")
+	m.targets = map[string]string{"app.conf": "../available/db.conf"}
+	m.list.SetItems(m.buildItemList())
+
+	m = update(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'t'}})
+	if !m.delegate.showTargets {
+		t.Fatal("showTargets = false after t, want true")
+	}
+	if item := m.list.Items()[0].(fileItem); item.target != "../available/db.conf" {
+		t.Errorf("item target = %q, want ../available/db.conf", item.target)
+	}
+
+	m.list.SetFilterText("db")
+	if got := visibleNames(m); len(got) != 1 || got[0] != "db.conf" {
+		t.Errorf("visible = %v, want [db.conf] (targets must not be filtered on)", got)
+	}
+
+	m.list.ResetFilter()
+	m = update(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'t'}})
+	if m.delegate.showTargets {
+		t.Error("showTargets = true after second t, want false")
+	}
+}
+
+// TestUpdate_SavePreset tests typing a preset name and saving the selection
+func TestUpdate_SavePreset(t *testing.T) {
+	m := newTestModel([]string{"a.conf", "b.conf"}, "b.conf")
+	m.keys.SavePreset.SetEnabled(true)
+
+	var savedName string
+	var savedFiles []string
+	m.savePreset = func(name string, files []string) error {
+		savedName, savedFiles = name, files
+		return nil
+	}
+
+	m = update(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'w'}})
+	if !m.presetPrompt {
+		t.Fatal("presetPrompt = false after w, want true")
+	}
+	for _, r := range "web" {
+		m = update(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	m = update(m, tea.KeyMsg{Type: tea.KeyEnter})
+
+	if m.presetPrompt {
+		t.Error("presetPrompt = true after enter, want false")
+	}
+	if savedName != "web" || !reflect.DeepEqual(savedFiles, []string{"b.conf"}) {
+		t.Errorf("saved %q %v, want \"web\" [b.conf]", savedName, savedFiles)
+	}
+	if m.status != "Saved preset web" {
+		t.Errorf("status = %q, want \"Saved preset web\"", m.status)
+	}
+}
+
+// TestFilesLoadedMsg_Preselect tests that a preselection replaces the enabled files
+func TestFilesLoadedMsg_Preselect(t *testing.T) {
+	m := multiSelectModel{
+		list:        list.New([]list.Item{}, fileItemDelegate{}, 80, 20),
+		selectedMap: make(map[string]bool),
+		keys:        defaultKeyMap(),
+		loading:     true,
+		preselect:   []string{"a.conf"},
+	}
+
+	m = update(m, filesLoadedMsg{availableFiles: []string{"a.conf", "b.conf"}, enabledFiles: []string{"b.conf"}})
+	if !reflect.DeepEqual(m.selectedOrder, []string{"a.conf"}) {
+		t.Errorf("selectedOrder = %v, want [a.conf]", m.selectedOrder)
+	}
+}
+
+// TestFilesLoadedMsg_RestoreCursor tests starting on the remembered file and
+// falling back to the top when it is gone
+func TestFilesLoadedMsg_RestoreCursor(t *testing.T) {
+	for _, tt := range []struct {
+		cursor string
+		want   int
+	}{
+		{"c.conf", 2},
+		{"removed.conf", 0},
+	} {
+		m := multiSelectModel{
+			list:          list.New([]list.Item{}, fileItemDelegate{}, 80, 20),
+			selectedMap:   make(map[string]bool),
+			keys:          defaultKeyMap(),
+			loading:       true,
+			restoreCursor: tt.cursor,
+		}
+
+		m = update(m, filesLoadedMsg{availableFiles: []string{"a.conf", "b.conf", "c.conf"}})
+		if m.list.Index() != tt.want {
+			t.Errorf("cursor for %s = %d, want %d", tt.cursor, m.list.Index(), tt.want)
+		}
+	}
+}
+
+// TestUpdate_ConfirmApply tests the change summary shown after Enter and that
+// declining returns to the list
+func TestUpdate_ConfirmApply(t *testing.T) {
+	m := newTestModel([]string{"a.conf", "b.conf", "c.conf"}, "a.conf", "b.conf")
+	m.initialEnabled = []string{"a.conf"}
+	m.confirmApply = true
+	m.list.Select(0)
+	m = update(m, tea.KeyMsg{Type: tea.KeySpace}) // deselect a.conf
+
+	model, _ := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = model.(multiSelectModel)
+	if !m.confirming {
+		t.Fatal("confirming = false after enter, want true")
+	}
+	if want := "Will create 1, remove 1. Apply?"; m.confirm.message != want {
+		t.Errorf("message = %q, want %q", m.confirm.message, want)
+	}
+
+	model, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'n'}})
+	m = model.(multiSelectModel)
+	if m.confirming || m.aborted || cmd != nil {
+		t.Errorf("after n: confirming=%t aborted=%t cmd=%v, want back to the list", m.confirming, m.aborted, cmd)
+	}
+
+	model, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = model.(multiSelectModel)
+	if !m.confirming {
+		t.Fatal("confirming = false after second enter, want true")
+	}
+	model, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'y'}})
+	m = model.(multiSelectModel)
+	if cmd == nil || m.aborted {
+		t.Error("after y: expected the program to quit with the selection")
+	}
+}
+
+// TestUpdate_Mouse tests moving the cursor by clicking a row, toggling by
+// clicking it again and scrolling with the wheel
+func TestUpdate_Mouse(t *testing.T) {
+	m := newTestModel([]string{"a.conf", "b.conf", "c.conf"})
+
+	// Find the screen row of b.conf in the rendered list
+	y := -1
+	for i, line := range strings.Split(m.View(), "\n") {
+		if strings.Contains(line, "b.conf") {
+			y = i
+		}
+	}
+	if y < 0 {
+		t.Fatal("b.conf not rendered")
+	}
+
+	click := tea.MouseMsg{X: 2, Y: y, Button: tea.MouseButtonLeft, Action: tea.MouseActionPress}
+	m = update(m, click)
+	if got := m.list.SelectedItem().(fileItem).name; got != "b.conf" {
+		t.Fatalf("cursor after click = %s, want b.conf", got)
+	}
+	if m.selectedMap["b.conf"] {
+		t.Error("first click selected b.conf, want cursor move only")
+	}
+
+	m = update(m, click)
+	if !m.selectedMap["b.conf"] {
+		t.Error("second click did not select b.conf")
+	}
+
+	m = update(m, tea.MouseMsg{Button: tea.MouseButtonWheelDown, Action: tea.MouseActionPress})
+	if got := m.list.SelectedItem().(fileItem).name; got != "c.conf" {
+		t.Errorf("cursor after wheel down = %s, want c.conf", got)
+	}
+
+	// Clicks below the last item and while typing a filter are ignored
+	m = update(m, tea.MouseMsg{Y: y + 5, Button: tea.MouseButtonLeft, Action: tea.MouseActionPress})
+	if got := m.list.SelectedItem().(fileItem).name; got != "c.conf" {
+		t.Errorf("cursor after click below the items = %s, want c.conf", got)
+	}
+	m = update(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'/'}})
+	m = update(m, click)
+	if m.list.FilterState() != list.Filtering || len(m.selectedMap) != 1 {
+		t.Errorf("click while filtering changed state: filter=%v selected=%v", m.list.FilterState(), m.selectedMap)
+	}
+}
+
+// TestRowIndex tests mapping screen rows to items for the list layouts used
+// by ShowFileSelect (with and without a title)
+func TestRowIndex(t *testing.T) {
+	for _, title := range []string{"", "Select files"} {
+		m := newTestModel([]string{"a.conf", "b.conf", "c.conf"})
+		m.list.SetShowStatusBar(false)
+		m.list.SetShowTitle(title != "")
+		m.list.Title = title
+
+		for y, line := range strings.Split(m.View(), "\n") {
+			for want, name := range []string{"a.conf", "b.conf", "c.conf"} {
+				if !strings.Contains(line, name) {
+					continue
+				}
+				if got, ok := m.rowIndex(y); !ok || got != want {
+					t.Errorf("title %q: rowIndex(%d) = %d, %t, want %d (%s)", title, y, got, ok, want, name)
+				}
+			}
+		}
+	}
+}
+
+// TestUpdate_FilterModeSwitch tests switching to regex matching while typing
+// a filter and the inline error for an invalid pattern
+func TestUpdate_FilterModeSwitch(t *testing.T) {
+	m := newTestModel([]string{"db-main.conf", "my-db.conf", "web.conf"})
+	m.list.SetFilterText("^db")
+	m.list.SetFilterState(list.Filtering)
+
+	m = update(m, tea.KeyMsg{Type: tea.KeyCtrlR})
+	if m.filterMode != filterRegex || m.list.FilterState() != list.Filtering {
+		t.Fatalf("after ctrl+r: mode=%d state=%v, want regex while filtering", m.filterMode, m.list.FilterState())
+	}
+	if got := visibleNames(m); !reflect.DeepEqual(got, []string{"db-main.conf"}) {
+		t.Errorf("visible with ^db = %v, want [db-main.conf]", got)
+	}
+
+	m.list.SetFilterText("^db(")
+	m.list.SetFilterState(list.Filtering)
+	if len(m.list.VisibleItems()) != 0 {
+		t.Errorf("visible with invalid regex = %v, want none", visibleNames(m))
+	}
+	if view := m.View(); !strings.Contains(view, "Invalid regex") {
+		t.Errorf("View() does not show the regex error:\n%s", view)
+	}
+
+	m = update(m, tea.KeyMsg{Type: tea.KeyCtrlR})
+	if m.filterMode != filterFuzzy || m.filterError() != "" {
+		t.Errorf("after second ctrl+r: mode=%d error=%q, want fuzzy without error", m.filterMode, m.filterError())
+	}
+}
+
+// TestSelectionCount tests the footer count, updated on every toggle
+func TestSelectionCount(t *testing.T) {
+	m := newTestModel([]string{"a.conf", "b.conf", "c.conf"}, "a.conf")
+	if got, want := m.selectionCount(), "1 selected / 3 total"; got != want {
+		t.Errorf("selectionCount() = %q, want %q", got, want)
+	}
+
+	m.list.Select(1)
+	m = update(m, tea.KeyMsg{Type: tea.KeySpace})
+	if view := m.View(); !strings.Contains(view, "2 selected / 3 total") {
+		t.Errorf("View() does not show the updated count:\n%s", view)
+	}
+
+	m.hideUnlinked = true
+	if got, want := m.selectionCount(), "2 selected / 3 total (1 unlinked hidden)"; got != want {
+		t.Errorf("selectionCount() = %q, want %q", got, want)
+	}
+}
+
+// TestConfirmModelView_NoColor tests marking the choice with text when
+// styling is disabled
+func TestConfirmModelView_NoColor(t *testing.T) {
+	m := newConfirmModel("Apply?", true)
+	m.noColor = true
+	m.width = 40
+	if view := m.View(); !strings.Contains(view, "> [ Yes ]") || !strings.Contains(view, "  [ No ]") {
+		t.Errorf("View() does not mark Yes:\n%s", view)
+	}
+
+	m.cursor = 1
+	if view := m.View(); !strings.Contains(view, "  [ Yes ]") || !strings.Contains(view, "> [ No ]") {
+		t.Errorf("View() does not mark No:\n%s", view)
+	}
+}
+
+// TestNewConfirmModel tests that Enter picks the default choice
+func TestNewConfirmModel(t *testing.T) {
+	for _, defaultYes := range []bool{true, false} {
+		m := newConfirmModel("Clean orphans?", defaultYes)
+		if got := m.choice() == ChoiceYes; got != defaultYes {
+			t.Errorf("newConfirmModel(%v) choice = %v", defaultYes, m.choice())
+		}
+
+		wantHelp := "enter: no"
+		if defaultYes {
+			wantHelp = "enter: yes"
+		}
+		if view := m.View(); !strings.Contains(view, wantHelp) {
+			t.Errorf("View() with default %v does not contain %q:\n%s", defaultYes, wantHelp, view)
+		}
+
+		confirmed, err := runConfirmation(m, tea.WithInput(strings.NewReader("\r")), tea.WithOutput(io.Discard))
+		if err != nil {
+			t.Fatalf("runConfirmation() error = %v", err)
+		}
+		if confirmed != defaultYes {
+			t.Errorf("Enter with default %v confirmed = %v", defaultYes, confirmed)
+		}
+	}
+}
+
+// TestConfirmModel_Choices tests picking one of several answers
+func TestConfirmModel_Choices(t *testing.T) {
+	m := confirmModel{message: "Link a.conf?", choices: []string{ChoiceYes, ChoiceNo, ChoiceAll, ChoiceQuit}}
+
+	// Arrows stop at the last answer
+	for range 5 {
+		model, _ := m.Update(tea.KeyMsg{Type: tea.KeyRight})
+		m = model.(confirmModel)
+	}
+	if m.choice() != ChoiceQuit {
+		t.Errorf("choice() = %q, want %q", m.choice(), ChoiceQuit)
+	}
+	if view := m.View(); !strings.Contains(view, "enter: quit") || !strings.Contains(view, "y/n/a/q: select") {
+		t.Errorf("View() does not list the answers:\n%s", view)
+	}
+
+	// Typing a first letter picks the answer
+	model, cmd := m.Update(keyRune('A'))
+	m = model.(confirmModel)
+	if m.choice() != ChoiceAll || cmd == nil {
+		t.Errorf("choice() = %q (cmd %v), want %q and quit", m.choice(), cmd != nil, ChoiceAll)
+	}
+
+	choice, err := runChoice(m, tea.WithInput(strings.NewReader("q")), tea.WithOutput(io.Discard))
+	if err != nil || choice != ChoiceQuit {
+		t.Errorf("runChoice() = %q, %v, want %q", choice, err, ChoiceQuit)
+	}
+}
+
+// TestShowChoice_Invalid tests rejecting answers that cannot be typed apart
+func TestShowChoice_Invalid(t *testing.T) {
+	if _, err := ShowChoice("Link?", nil, config.DefaultTheme()); err == nil {
+		t.Error("ShowChoice() without choices should fail")
+	}
+	if _, err := ShowChoice("Link?", []string{"Yes", "yesterday"}, config.DefaultTheme()); err == nil || !strings.Contains(err.Error(), "same letter") {
+		t.Errorf("ShowChoice() error = %v, want same letter error", err)
+	}
+}
+
+// TestRunConfirmation_Aborted tests that ctrl+c is reported as ErrUserAborted
+func TestRunConfirmation_Aborted(t *testing.T) {
+	_, err := runConfirmation(newConfirmModel("Apply?", true),
+		tea.WithInput(strings.NewReader("\x03")), tea.WithOutput(io.Discard))
+	if !errors.Is(err, ErrUserAborted) {
+		t.Errorf("runConfirmation() error = %v, want ErrUserAborted", err)
+	}
+}
+
+// TestPageSize tests a fixed page size, the page indicator and paging with
+// ctrl+f/ctrl+b while keeping the cursor on a file
+func TestPageSize(t *testing.T) {
+	files := []string{"a.conf", "b.conf", "c.conf", "d.conf", "e.conf", "f.conf", "g.conf", "h.conf", "i.conf", "j.conf"}
+	m := newTestModel(files)
+	m.list.SetShowHelp(false)
+	m.list.SetShowPagination(false)
+	bindPageKeys(&m.list, m.keys)
+	m.pageSize = 3
+
+	m = update(m, tea.WindowSizeMsg{Width: 80, Height: 40})
+	if got := m.list.Paginator.PerPage; got != 3 {
+		t.Fatalf("PerPage = %d, want 3 regardless of the window height", got)
+	}
+	if got := m.pageIndicator(); got != " · page 1/4 · 1/10" {
+		t.Errorf("pageIndicator() = %q, want page 1/4", got)
+	}
+
+	m = update(m, tea.KeyMsg{Type: tea.KeyCtrlF})
+	if got := m.pageIndicator(); got != " · page 2/4 · 4/10" {
+		t.Errorf("pageIndicator() after ctrl+f = %q, want page 2/4", got)
+	}
+	m = update(m, tea.KeyMsg{Type: tea.KeyCtrlB})
+	if m.list.Paginator.Page != 0 {
+		t.Errorf("page after ctrl+b = %d, want 0", m.list.Paginator.Page)
+	}
+
+	// The cursor is restored on a file of a later page
+	m.setCursorToFile("j.conf")
+	if fi, ok := m.list.SelectedItem().(fileItem); !ok || fi.name != "j.conf" || m.list.Paginator.Page != 3 {
+		t.Errorf("cursor on %v at page %d, want j.conf on the last page", m.list.SelectedItem(), m.list.Paginator.Page)
+	}
+
+	// A window too small for the page size wins
+	m = update(m, tea.WindowSizeMsg{Width: 80, Height: 6})
+	if got := m.list.Paginator.PerPage; got >= 3 {
+		t.Errorf("PerPage = %d in a small window, want fewer than 3", got)
+	}
+
+	// Everything on one page needs no indicator
+	m.pageSize = 20
+	m = update(m, tea.WindowSizeMsg{Width: 80, Height: 40})
+	if got := m.pageIndicator(); got != "" {
+		t.Errorf("pageIndicator() = %q, want none on a single page", got)
+	}
+}
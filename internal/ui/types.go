@@ -3,23 +3,62 @@ package ui
 import (
 	"fmt"
 	"io"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/marco-arnold/lnka/internal/config"
 )
 
-// Lipgloss styles for file item rendering
-// These are defined at package level to avoid repeated allocations during rendering
-var (
+// itemStyles are the lipgloss styles for file item rendering
+// They are built once from a theme to avoid repeated allocations during rendering.
+type itemStyles struct {
 	// Cursor styles (item under cursor with ">")
-	styleCursorEnabled  = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("10")) // Bold green for cursor on linked
-	styleCursorDisabled = lipgloss.NewStyle().Foreground(lipgloss.Color("10"))            // Green (not bold) for cursor on unlinked
+	cursorEnabled  lipgloss.Style // Bold cursor color for cursor on linked
+	cursorDisabled lipgloss.Style // Cursor color (not bold) for cursor on unlinked
 
 	// Normal item styles (not under cursor)
-	styleEnabled  = lipgloss.NewStyle().Bold(true)                        // Bold for linked items
-	styleDisabled = lipgloss.NewStyle().Foreground(lipgloss.Color("240")) // Gray for unlinked
-)
+	enabled  lipgloss.Style // Bold (in the linked color) for linked items
+	disabled lipgloss.Style // Unlinked color (gray by default) for unlinked
+
+	// Tag style (tags, link targets and columns shown after the name)
+	tag lipgloss.Style // Dimmed
+}
+
+// defaultItemStyles are the styles of the built-in theme
+var defaultItemStyles = newItemStyles(config.DefaultTheme())
+
+// newItemStyles builds the item styles for a theme
+// Empty colors use the default theme; NoColor disables all styling.
+func newItemStyles(theme config.Theme) itemStyles {
+	if theme.NoColor {
+		plain := lipgloss.NewStyle()
+		return itemStyles{cursorEnabled: plain, cursorDisabled: plain, enabled: plain, disabled: plain, tag: plain}
+	}
+	if theme.Cursor == "" {
+		theme.Cursor = config.DefaultColorCursor
+	}
+	if theme.Unlinked == "" {
+		theme.Unlinked = config.DefaultColorUnlinked
+	}
+
+	color := func(s lipgloss.Style, c string) lipgloss.Style {
+		if c == "" {
+			return s // Terminal default (linked items)
+		}
+		return s.Foreground(lipgloss.Color(c))
+	}
+	return itemStyles{
+		cursorEnabled:  color(lipgloss.NewStyle().Bold(true), theme.Cursor),
+		cursorDisabled: color(lipgloss.NewStyle(), theme.Cursor),
+		enabled:        color(lipgloss.NewStyle().Bold(true), theme.Linked),
+		disabled:       color(lipgloss.NewStyle(), theme.Unlinked),
+		tag:            lipgloss.NewStyle().Faint(true),
+	}
+}
 
 // Message types for async operations
 
@@ -27,6 +66,11 @@ var (
 type filesLoadedMsg struct {
 	availableFiles []string
 	enabledFiles   []string
+	targets        map[string]string   // Symlink name -> current target
+	partial        map[string]string   // Files linked in some but not all targets -> "2/3 targets"
+	external       map[string][]string // Files linked from an --also-check directory -> those links
+	renamed        map[string]string   // Enabled files linked under another name -> link name
+	stats          map[string]fileStat // Source file name -> size and mtime (missing = stat failed)
 	err            error
 }
 
@@ -37,11 +81,29 @@ type itemsRefreshedMsg struct {
 	cursorFileName string // Optional: filename to position cursor on after rebuild
 }
 
+// presetSavedMsg is sent after saving the selection as a preset
+type presetSavedMsg struct {
+	name string
+	err  error
+}
+
+// openResultMsg is sent after trying to open a link in the file manager
+type openResultMsg struct {
+	err error
+}
+
 // fileItem represents a single file in the list
 // It implements the list.Item interface for use with bubbles/list
 type fileItem struct {
 	name      string
-	isEnabled bool // Whether this file is currently selected/linked
+	isEnabled bool      // Whether this file is currently selected/linked
+	tags      []string  // Optional user-defined tags (from --tags file)
+	target    string    // Current symlink target (empty = not linked)
+	partial   string    // Share of targets linking the file, e.g. "2/3 targets" (empty = all or none)
+	external  []string  // Links to the file in other known directories (informational)
+	linkName  string    // Custom name of the link (empty = the usual name)
+	size      int64     // Source file size in bytes (-1 = unknown)
+	modTime   time.Time // Source file modification time (zero = unknown)
 }
 
 // FilterValue implements list.Item interface
@@ -50,8 +112,46 @@ func (i fileItem) FilterValue() string {
 	return i.name
 }
 
+// checkboxGlyphs are the markers rendered in front of each item so the
+// selection state is visible without relying on color
+type checkboxGlyphs struct {
+	checked   string // Selected/linked item
+	unchecked string // Unselected item
+}
+
+// Available checkbox glyph sets
+var (
+	unicodeCheckboxes = checkboxGlyphs{checked: "[✓]", unchecked: "[ ]"}
+	asciiCheckboxes   = checkboxGlyphs{checked: "[x]", unchecked: "[ ]"}
+	plainMarkers      = checkboxGlyphs{checked: "*", unchecked: " "} // Without styling (NO_COLOR)
+)
+
 // fileItemDelegate is a custom delegate for rendering file items
-type fileItemDelegate struct{}
+type fileItemDelegate struct {
+	glyphs      checkboxGlyphs // Checkbox markers (zero value = unicode set)
+	showTargets bool           // Append the current symlink target of linked items
+	styles      *itemStyles    // Item styles (nil = default theme)
+}
+
+// style returns the item styles of the delegate
+func (d fileItemDelegate) style() *itemStyles {
+	if d.styles == nil {
+		return &defaultItemStyles
+	}
+	return d.styles
+}
+
+// checkbox returns the checkbox glyph for the given selection state
+func (d fileItemDelegate) checkbox(enabled bool) string {
+	glyphs := d.glyphs
+	if glyphs == (checkboxGlyphs{}) {
+		glyphs = unicodeCheckboxes
+	}
+	if enabled {
+		return glyphs.checked
+	}
+	return glyphs.unchecked
+}
 
 // Height returns the height of each list item (1 line)
 func (d fileItemDelegate) Height() int { return 1 }
@@ -63,31 +163,133 @@ func (d fileItemDelegate) Spacing() int { return 0 }
 func (d fileItemDelegate) Update(_ tea.Msg, _ *list.Model) tea.Cmd { return nil }
 
 // Render draws a single item in the list
-// Uses pre-defined package-level styles to avoid repeated allocations
+// Uses the pre-built styles of the theme to avoid repeated allocations
 func (d fileItemDelegate) Render(w io.Writer, m list.Model, index int, listItem list.Item) {
 	fi, ok := listItem.(fileItem)
 	if !ok {
 		return
 	}
+	styles := d.style()
+
+	label := d.checkbox(fi.isEnabled) + " " + fi.name
 
 	// Render based on cursor position
+	var line string
 	if index == m.Index() {
 		// Current cursor position with ">"
 		if fi.isEnabled {
-			// Linked item at cursor: bold green
-			fmt.Fprint(w, styleCursorEnabled.Render("> "+fi.name))
+			// Linked item at cursor: bold cursor color
+			line = styles.cursorEnabled.Render("> " + label)
 		} else {
-			// Unlinked item at cursor: green (not bold)
-			fmt.Fprint(w, styleCursorDisabled.Render("> "+fi.name))
+			// Unlinked item at cursor: cursor color (not bold)
+			line = styles.cursorDisabled.Render("> " + label)
 		}
 	} else {
 		// Normal item: styled based on selection status
 		if fi.isEnabled {
 			// Linked items are bold
-			fmt.Fprint(w, styleEnabled.Render("  "+fi.name))
+			line = styles.enabled.Render("  " + label)
 		} else {
-			// Unlinked items are gray
-			fmt.Fprint(w, styleDisabled.Render("  "+fi.name))
+			// Unlinked items use the unlinked color
+			line = styles.disabled.Render("  " + label)
+		}
+	}
+
+	// Append tags dimmed after the name
+	if len(fi.tags) > 0 {
+		line += " " + styles.tag.Render(formatTags(fi.tags))
+	}
+
+	// Show the custom name of the link
+	if fi.linkName != "" {
+		line += " " + styles.tag.Render("(as "+fi.linkName+")")
+	}
+
+	// Mark files linked in only some of the targets
+	if fi.partial != "" {
+		line += " " + styles.tag.Render("("+fi.partial+")")
+	}
+
+	// Mark files already linked from another known directory
+	if len(fi.external) > 0 {
+		line += " " + styles.tag.Render("(also linked in "+formatLinkDirs(fi.external)+")")
+	}
+
+	// Append the link target dimmed for linked items
+	if d.showTargets && fi.isEnabled && fi.target != "" {
+		line += " " + styles.tag.Render("→ "+fi.target)
+	}
+
+	// Right-align size and age at the list width
+	columns := styles.tag.Render(formatFileColumns(fi, time.Now()))
+	padding := m.Width() - lipgloss.Width(line) - lipgloss.Width(columns)
+	fmt.Fprint(w, line+strings.Repeat(" ", max(padding, 2))+columns)
+}
+
+// formatLinkDirs lists the directories of the links, each once
+func formatLinkDirs(links []string) string {
+	var dirs []string
+	seen := make(map[string]bool, len(links))
+	for _, link := range links {
+		dir := filepath.Dir(link)
+		if !seen[dir] {
+			seen[dir] = true
+			dirs = append(dirs, dir)
 		}
 	}
+	return strings.Join(dirs, ", ")
+}
+
+// formatFileColumns renders the size and age columns of an item
+func formatFileColumns(fi fileItem, now time.Time) string {
+	size, age := "?", "?"
+	if fi.size >= 0 {
+		size = formatSize(fi.size)
+	}
+	if !fi.modTime.IsZero() {
+		age = formatAge(fi.modTime, now)
+	}
+	return fmt.Sprintf("%7s  %8s", size, age)
+}
+
+// formatSize renders a byte count in human-readable form (e.g. "1.5K")
+func formatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%dB", size)
+	}
+	value := float64(size) / unit
+	suffixes := "KMGTPE"
+	i := 0
+	for value >= unit && i < len(suffixes)-1 {
+		value /= unit
+		i++
+	}
+	return fmt.Sprintf("%.1f%c", value, suffixes[i])
+}
+
+// formatAge renders the time since t relative to now (e.g. "3d ago")
+func formatAge(t, now time.Time) string {
+	d := now.Sub(t)
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d/time.Minute))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d/time.Hour))
+	case d < 365*24*time.Hour:
+		return fmt.Sprintf("%dd ago", int(d/(24*time.Hour)))
+	default:
+		return fmt.Sprintf("%dy ago", int(d/(365*24*time.Hour)))
+	}
+}
+
+// formatTags renders tags in their filter syntax (e.g. "#web #critical")
+func formatTags(tags []string) string {
+	parts := make([]string, len(tags))
+	for i, tag := range tags {
+		parts[i] = tagFilterPrefix + tag
+	}
+	return strings.Join(parts, " ")
 }
@@ -1,7 +1,13 @@
 package ui
 
 import (
+	"bytes"
+	"strings"
 	"testing"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/marco-arnold/lnka/internal/config"
 )
 
 func TestFileItemFilterValue(t *testing.T) {
@@ -57,3 +63,136 @@ func TestFilesLoadedMsg(t *testing.T) {
 		t.Errorf("Expected no error, got %v", msg.err)
 	}
 }
+
+func TestFileItemDelegateRender_Tags(t *testing.T) {
+	items := []list.Item{
+		fileItem{name: "app.conf", tags: []string{"web", "critical"}},
+		fileItem{name: "plain.conf"},
+	}
+	l := list.New(items, fileItemDelegate{}, 80, 10)
+	delegate := fileItemDelegate{}
+
+	var buf bytes.Buffer
+	delegate.Render(&buf, l, 0, items[0])
+	if got := buf.String(); !strings.Contains(got, "app.conf") || !strings.Contains(got, "#web #critical") {
+		t.Errorf("Render() = %q, want name followed by tags", got)
+	}
+
+	buf.Reset()
+	delegate.Render(&buf, l, 1, items[1])
+	if got := buf.String(); strings.Contains(got, "#") {
+		t.Errorf("Render() = %q, want no tags for untagged item", got)
+	}
+}
+
+func TestFileItemDelegateRender_Checkboxes(t *testing.T) {
+	items := []list.Item{
+		fileItem{name: "on.conf", isEnabled: true},
+		fileItem{name: "off.conf"},
+	}
+	l := list.New(items, fileItemDelegate{}, 80, 10)
+
+	tests := []struct {
+		name     string
+		delegate fileItemDelegate
+		index    int
+		want     string
+	}{
+		{"unicode checked", fileItemDelegate{glyphs: unicodeCheckboxes}, 0, "[✓] on.conf"},
+		{"unicode unchecked", fileItemDelegate{glyphs: unicodeCheckboxes}, 1, "[ ] off.conf"},
+		{"ascii checked", fileItemDelegate{glyphs: asciiCheckboxes}, 0, "[x] on.conf"},
+		{"ascii unchecked", fileItemDelegate{glyphs: asciiCheckboxes}, 1, "[ ] off.conf"},
+		{"zero value defaults to unicode", fileItemDelegate{}, 0, "[✓] on.conf"},
+		{"plain linked at cursor", fileItemDelegate{glyphs: plainMarkers}, 0, "> * on.conf"},
+		{"plain unlinked", fileItemDelegate{glyphs: plainMarkers}, 1, "    off.conf"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			tt.delegate.Render(&buf, l, tt.index, items[tt.index])
+			if got := buf.String(); !strings.Contains(got, tt.want) {
+				t.Errorf("Render() = %q, want it to contain %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFileItemDelegateRender_Targets(t *testing.T) {
+	items := []list.Item{
+		fileItem{name: "on.conf", isEnabled: true, target: "../available/on.conf"},
+		fileItem{name: "off.conf"},
+	}
+	l := list.New(items, fileItemDelegate{}, 80, 10)
+
+	var buf bytes.Buffer
+	fileItemDelegate{}.Render(&buf, l, 0, items[0])
+	if got := buf.String(); strings.Contains(got, "→") {
+		t.Errorf("Render() = %q, want no target while the detail is hidden", got)
+	}
+
+	delegate := fileItemDelegate{showTargets: true}
+	buf.Reset()
+	delegate.Render(&buf, l, 0, items[0])
+	if got := buf.String(); !strings.Contains(got, "→ ../available/on.conf") {
+		t.Errorf("Render() = %q, want the link target", got)
+	}
+
+	// Unlinked items render unchanged
+	var plain bytes.Buffer
+	buf.Reset()
+	fileItemDelegate{}.Render(&plain, l, 1, items[1])
+	delegate.Render(&buf, l, 1, items[1])
+	if buf.String() != plain.String() {
+		t.Errorf("Render() = %q, want %q for an unlinked item", buf.String(), plain.String())
+	}
+}
+
+// TestFileItemDelegateRender_Partial tests marking files linked in only some
+// of the targets
+func TestFileItemDelegateRender_Partial(t *testing.T) {
+	items := []list.Item{
+		fileItem{name: "some.conf", isEnabled: true, partial: "1/2 targets"},
+		fileItem{name: "all.conf", isEnabled: true},
+	}
+	l := list.New(items, fileItemDelegate{}, 80, 10)
+
+	var buf bytes.Buffer
+	fileItemDelegate{}.Render(&buf, l, 0, items[0])
+	if got := buf.String(); !strings.Contains(got, "some.conf (1/2 targets)") {
+		t.Errorf("Render() = %q, want the share of targets", got)
+	}
+
+	buf.Reset()
+	fileItemDelegate{}.Render(&buf, l, 1, items[1])
+	if got := buf.String(); strings.Contains(got, "targets") {
+		t.Errorf("Render() = %q, want no marker for a file linked everywhere", got)
+	}
+}
+
+// TestNewItemStyles tests building the item styles from a theme
+func TestNewItemStyles(t *testing.T) {
+	styles := newItemStyles(config.Theme{Cursor: "33", Unlinked: "#888888"})
+	if got := styles.cursorEnabled.GetForeground(); got != lipgloss.Color("33") {
+		t.Errorf("cursor color = %v, want 33", got)
+	}
+	if got := styles.disabled.GetForeground(); got != lipgloss.Color("#888888") {
+		t.Errorf("unlinked color = %v, want #888888", got)
+	}
+	if _, ok := styles.enabled.GetForeground().(lipgloss.NoColor); !ok || !styles.enabled.GetBold() {
+		t.Errorf("linked style should be bold in the terminal color, got %v", styles.enabled.GetForeground())
+	}
+
+	// Empty colors use the default theme
+	if got := newItemStyles(config.Theme{}).cursorDisabled.GetForeground(); got != lipgloss.Color(config.DefaultColorCursor) {
+		t.Errorf("default cursor color = %v, want %s", got, config.DefaultColorCursor)
+	}
+
+	plain := newItemStyles(config.Theme{Cursor: "33", NoColor: true})
+	if plain.cursorEnabled.GetBold() || plain.tag.GetFaint() {
+		t.Error("NoColor styles should not be bold or faint")
+	}
+	if _, ok := plain.cursorEnabled.GetForeground().(lipgloss.NoColor); !ok {
+		t.Errorf("NoColor cursor color = %v, want none", plain.cursorEnabled.GetForeground())
+	}
+}
@@ -0,0 +1,129 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/fsnotify/fsnotify"
+)
+
+// watchDebounce is how long the directories have to stay unchanged before
+// the files are reloaded, so a burst of events triggers a single reload
+const watchDebounce = 200 * time.Millisecond
+
+// dirChangedMsg is sent when a file was created, removed or renamed in a
+// watched directory
+type dirChangedMsg struct{}
+
+// watchTickMsg is sent once the debounce delay after a change passed
+// Ticks of changes followed by later ones carry an outdated seq.
+type watchTickMsg struct {
+	seq int
+}
+
+// filesReloadedMsg is sent after reloading the files due to a change
+type filesReloadedMsg struct {
+	filesLoadedMsg
+}
+
+// newDirWatcher watches the given directories for created and removed files
+// Subdirectories are not watched, nor directories that do not exist yet
+// (e.g. a target created on apply with --mkdir).
+func newDirWatcher(dirs ...string) (*fsnotify.Watcher, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start watching: %w", err)
+	}
+	for _, dir := range dirs {
+		if _, err := os.Stat(dir); os.IsNotExist(err) {
+			continue
+		}
+		if err := watcher.Add(dir); err != nil {
+			watcher.Close()
+			return nil, fmt.Errorf("failed to watch %s: %w", dir, err)
+		}
+	}
+	return watcher, nil
+}
+
+// waitForChangeCmd creates a command that blocks until a file is created,
+// removed or renamed in a watched directory. Returns dirChangedMsg, or nil
+// once the watcher is closed.
+func waitForChangeCmd(watcher *fsnotify.Watcher) tea.Cmd {
+	return func() tea.Msg {
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return nil
+				}
+				if event.Has(fsnotify.Create) || event.Has(fsnotify.Remove) || event.Has(fsnotify.Rename) {
+					logDebug("watch", "", "event", event.String())
+					return dirChangedMsg{}
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return nil
+				}
+				logDebug("watch", "", "error", err)
+			}
+		}
+	}
+}
+
+// watchTickCmd creates a command that sends watchTickMsg after the debounce
+// delay
+func watchTickCmd(seq int) tea.Cmd {
+	return tea.Tick(watchDebounce, func(time.Time) tea.Msg {
+		return watchTickMsg{seq: seq}
+	})
+}
+
+// reloadFilesCmd creates a command that loads the files like loadFilesCmd
+// Returns filesReloadedMsg when complete.
+func (m *multiSelectModel) reloadFilesCmd() tea.Cmd {
+	load := m.loadFilesCmd()
+	return func() tea.Msg {
+		msg, _ := load().(filesLoadedMsg)
+		return filesReloadedMsg{msg}
+	}
+}
+
+// applyReload replaces the files with reloaded ones, keeping the selection
+// of the files that still exist and the cursor on the same file
+func (m *multiSelectModel) applyReload(msg filesLoadedMsg) tea.Cmd {
+	if msg.err != nil {
+		m.status = fmt.Sprintf("Cannot refresh files: %v", msg.err)
+		return nil
+	}
+
+	available := make(map[string]bool, len(msg.availableFiles))
+	for _, name := range msg.availableFiles {
+		available[name] = true
+	}
+	for _, name := range append([]string(nil), m.selectedOrder...) {
+		if !available[name] {
+			delete(m.selectedMap, name)
+			m.removeFromOrder(name)
+		}
+	}
+
+	m.availableFiles = msg.availableFiles
+	m.initialEnabled = msg.enabledFiles
+	m.targets = msg.targets
+	m.partial = msg.partial
+	m.external = msg.external
+	m.loadedLinks = msg.renamed
+	if m.contents != nil {
+		m.contents.clear()
+	}
+	m.stats = msg.stats
+
+	var cursor string
+	if fi, ok := m.list.SelectedItem().(fileItem); ok {
+		cursor = fi.name
+	}
+	return m.rebuildItemsCmdWithCursor(cursor)
+}
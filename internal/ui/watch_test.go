@@ -0,0 +1,72 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// TestApplyReload tests that a reload keeps the selection and cursor
+func TestApplyReload(t *testing.T) {
+	m := newTestModel([]string{"a.conf", "b.conf", "c.conf"}, "a.conf", "c.conf")
+	m.setCursorToFile("c.conf")
+
+	m = update(m, filesReloadedMsg{filesLoadedMsg{
+		availableFiles: []string{"b.conf", "c.conf", "d.conf"},
+		enabledFiles:   []string{"c.conf"},
+	}})
+
+	if got, want := visibleNames(m), []string{"b.conf", "c.conf", "d.conf"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("visible = %v, want %v", got, want)
+	}
+	if got, want := m.selectedOrder, []string{"c.conf"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("selectedOrder = %v, want %v (deleted a.conf dropped)", got, want)
+	}
+	if fi, ok := m.list.SelectedItem().(fileItem); !ok || fi.name != "c.conf" {
+		t.Errorf("cursor on %v, want c.conf", m.list.SelectedItem())
+	}
+}
+
+// TestWatchDebounce tests that only the tick of the latest change reloads
+func TestWatchDebounce(t *testing.T) {
+	m := newTestModel([]string{"a.conf"})
+
+	model, _ := m.Update(dirChangedMsg{})
+	model, _ = model.(multiSelectModel).Update(dirChangedMsg{})
+	m = model.(multiSelectModel)
+
+	if _, cmd := m.Update(watchTickMsg{seq: 1}); cmd != nil {
+		t.Error("outdated tick should not reload")
+	}
+	if _, cmd := m.Update(watchTickMsg{seq: 2}); cmd == nil {
+		t.Error("latest tick should reload")
+	}
+}
+
+// TestWaitForChangeCmd tests that creating a file is reported
+func TestWaitForChangeCmd(t *testing.T) {
+	dir := t.TempDir()
+	watcher, err := newDirWatcher(dir)
+	if err != nil {
+		t.Fatalf("newDirWatcher() error = %v", err)
+	}
+	defer watcher.Close()
+
+	done := make(chan any, 1)
+	go func() { done <- waitForChangeCmd(watcher)() }()
+
+	if err := os.WriteFile(filepath.Join(dir, "new.conf"), nil, 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+
+	select {
+	case msg := <-done:
+		if _, ok := msg.(dirChangedMsg); !ok {
+			t.Errorf("waitForChangeCmd() = %#v, want dirChangedMsg", msg)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no change reported")
+	}
+}
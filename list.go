@@ -0,0 +1,120 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/marco-arnold/lnka/internal/filesystem"
+	"github.com/mattn/go-isatty"
+	"github.com/spf13/cobra"
+)
+
+var listCmd = &cobra.Command{
+	Use:   "list SOURCE TARGET",
+	Short: "Print the available source files, one per line",
+	Long: `Print the available source files one per line without the UI.
+
+Linked files are shown bold when writing to a terminal; piped output is plain.`,
+	Args: cobra.ExactArgs(2),
+	RunE: quietRunE(runList),
+}
+
+func init() {
+	listCmd.Flags().Bool("enabled-only", false, "Only print linked files")
+	listCmd.Flags().Bool("disabled-only", false, "Only print files that are not linked")
+	listCmd.Flags().Bool("null", false, "Separate names with NUL instead of newline (for xargs -0)")
+	listCmd.ValidArgsFunction = completeDirs(2)
+	rootCmd.AddCommand(listCmd)
+}
+
+// listFilter selects which files the list command prints
+type listFilter int
+
+const (
+	listAll listFilter = iota
+	listEnabled
+	listDisabled
+)
+
+// styleListLinked marks linked files when writing to a terminal
+var styleListLinked = lipgloss.NewStyle().Bold(true)
+
+func runList(cmd *cobra.Command, args []string) error {
+	enabledOnly, err := cmd.Flags().GetBool("enabled-only")
+	if err != nil {
+		return fmt.Errorf("failed to get enabled-only flag: %w", err)
+	}
+	disabledOnly, err := cmd.Flags().GetBool("disabled-only")
+	if err != nil {
+		return fmt.Errorf("failed to get disabled-only flag: %w", err)
+	}
+	null, err := cmd.Flags().GetBool("null")
+	if err != nil {
+		return fmt.Errorf("failed to get null flag: %w", err)
+	}
+	if enabledOnly && disabledOnly {
+		return errors.New("--enabled-only and --disabled-only cannot be used together")
+	}
+
+	filter := listAll
+	switch {
+	case enabledOnly:
+		filter = listEnabled
+	case disabledOnly:
+		filter = listDisabled
+	}
+
+	sourceDir, targetDir := args[0], args[1]
+	if err := filesystem.CheckDirExists(sourceDir); err != nil {
+		return fmt.Errorf("source directory error: %w", err)
+	}
+	if err := filesystem.CheckDirExists(targetDir); err != nil {
+		return fmt.Errorf("target directory error: %w", err)
+	}
+
+	// Links are recognized with the options of the last apply (e.g. --rename)
+	opts := recordedOptions(sourceDir, targetDir)
+	available, err := filesystem.ListAvailableFilesWithOptions(sourceDir, opts)
+	if err != nil {
+		return fmt.Errorf("failed to list available files: %w", err)
+	}
+	enabled, err := filesystem.GetEnabledFilesWithOptions(sourceDir, targetDir, opts)
+	if err != nil {
+		return fmt.Errorf("failed to get enabled files: %w", err)
+	}
+
+	// Only style output for a terminal, never for other programs
+	styled := !null && isatty.IsTerminal(os.Stdout.Fd())
+	return writeList(os.Stdout, available, enabled, filter, null, styled)
+}
+
+// writeList writes the files matching the filter, each followed by a newline
+// or NUL byte. With styled, linked files are rendered bold.
+func writeList(w io.Writer, available, enabled []string, filter listFilter, null, styled bool) error {
+	isEnabled := make(map[string]bool, len(enabled))
+	for _, name := range enabled {
+		isEnabled[name] = true
+	}
+
+	sep := "\n"
+	if null {
+		sep = "\x00"
+	}
+
+	for _, name := range available {
+		linked := isEnabled[name]
+		if (filter == listEnabled && !linked) || (filter == listDisabled && linked) {
+			continue
+		}
+		if linked && styled {
+			name = styleListLinked.Render(name)
+		}
+		if _, err := io.WriteString(w, name+sep); err != nil {
+			return err
+		}
+	}
+	return nil
+}
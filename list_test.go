@@ -0,0 +1,62 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestWriteList tests the filters and separators of the list command
+func TestWriteList(t *testing.T) {
+	available := []string{"a.conf", "b.conf", "c.conf"}
+	enabled := []string{"b.conf"}
+
+	tests := []struct {
+		name   string
+		filter listFilter
+		null   bool
+		want   string
+	}{
+		{name: "all files", filter: listAll, want: "a.conf\nb.conf\nc.conf\n"},
+		{name: "enabled only", filter: listEnabled, want: "b.conf\n"},
+		{name: "disabled only", filter: listDisabled, want: "a.conf\nc.conf\n"},
+		{name: "nul separated", filter: listDisabled, null: true, want: "a.conf\x00c.conf\x00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := writeList(&buf, available, enabled, tt.filter, tt.null, false); err != nil {
+				t.Fatalf("writeList failed: %v", err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("writeList() = %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+// TestRunList tests that links renamed by the last apply count as enabled
+func TestRunList(t *testing.T) {
+	sourceDir, targetDir := t.TempDir(), t.TempDir()
+	for _, name := range []string{"a.conf.disabled", "b.conf"} {
+		if err := os.WriteFile(filepath.Join(sourceDir, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := runLnka(t, sourceDir, targetDir, "--enable", "a.conf.disabled", "--rename-pattern", `s/\.disabled$//`); got != exitCodeOK {
+		t.Fatalf("apply exit code = %d, want %d", got, exitCodeOK)
+	}
+	if _, err := os.Lstat(filepath.Join(targetDir, "a.conf")); err != nil {
+		t.Fatalf("renamed link missing: %v", err)
+	}
+
+	got, stdout, _ := runLnkaOutput(t, "list", sourceDir, targetDir, "--enabled-only")
+	if got != exitCodeOK {
+		t.Fatalf("list exit code = %d, want %d", got, exitCodeOK)
+	}
+	if stdout != "a.conf.disabled\n" {
+		t.Errorf("list --enabled-only = %q, want %q", stdout, "a.conf.disabled\n")
+	}
+}
@@ -1,17 +1,40 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"maps"
 	"os"
+	"path/filepath"
+	"sort"
 	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
 	"github.com/marco-arnold/lnka/internal/config"
 	"github.com/marco-arnold/lnka/internal/filesystem"
+	"github.com/marco-arnold/lnka/internal/preset"
+	"github.com/marco-arnold/lnka/internal/state"
 	"github.com/marco-arnold/lnka/internal/ui"
+	"github.com/mattn/go-isatty"
 	"github.com/spf13/cobra"
 )
 
+// teardownMinLinks is the number of previously enabled symlinks from which
+// removing all of them is treated as a full teardown requiring confirmation
+const teardownMinLinks = 2
+
+// exitCodeChangesPending is the exit code of a detailed dry run when the target
+// does not match the selection yet (like terraform plan -detailed-exitcode)
+const exitCodeChangesPending = 10
+
+// reviewChangesThreshold is the number of planned changes above which the
+// changes are shown in a scrollable review before applying
+const reviewChangesThreshold = 50
+
 // Version information (set by goreleaser via ldflags)
 var (
 	version = "dev"
@@ -20,7 +43,7 @@ var (
 )
 
 var rootCmd = &cobra.Command{
-	Use:   "lnka SOURCE TARGET",
+	Use:   "lnka [SOURCE] [TARGET...]",
 	Short: "Manage symlinks between source and target directories",
 	Long: `lnka is a CLI tool for managing symlinks between a source directory
 and a target directory using an interactive Terminal UI.`,
@@ -29,21 +52,154 @@ and a target directory using an interactive Terminal UI.`,
 		if versionFlag, _ := cmd.Flags().GetBool("version"); versionFlag {
 			return nil
 		}
-		return cobra.ExactArgs(2)(cmd, args)
+		// Missing directories can be picked interactively, further
+		// arguments are more targets getting the same selection
+		return cobra.ArbitraryArgs(cmd, args)
 	},
-	RunE: run,
+	RunE: quietRunE(run),
 }
 
+// Prompts of the interactive UI (replaced in tests)
+var (
+	showFileSelect              = ui.ShowFileSelect
+	showConfirmation            = ui.ShowConfirmation
+	showConfirmationWithDefault = ui.ShowConfirmationWithDefault
+	showDangerConfirmation      = ui.ShowDangerConfirmation
+	showChangeReview            = ui.ShowChangeReview
+	showDirectoryPicker         = ui.ShowDirectoryPicker
+)
+
 func init() {
 	// Define flags with environment variable fallback and shorthands
-	titleDefault := os.Getenv("LNKA_TITLE")
+	titleDefault := os.Getenv(config.TitleEnvVar)
 	rootCmd.Flags().StringP("title", "t", titleDefault, "Title to display in UI (env: LNKA_TITLE)")
 
+	// Add config file flags
+	rootCmd.Flags().String("config", "", "Config file with named profiles (default: ~/.config/lnka/config.yaml)")
+	rootCmd.Flags().StringP("profile", "p", "", "Profile from the config file providing source, target and title")
+	_ = rootCmd.RegisterFlagCompletionFunc("profile", completeProfiles)
+
 	// Add version flag
 	rootCmd.Flags().BoolP("version", "v", false, "Print version information")
 
 	// Add debug flag
 	rootCmd.Flags().StringP("debug", "d", "", "Enable debug logging to specified file (e.g., debug.log)")
+	rootCmd.Flags().String("debug-format", "text", "Format of the debug log: text or json (one object per event)")
+
+	// Add verbose flag
+	rootCmd.Flags().BoolP("verbose", "V", false, "Log each link created, removed or skipped to stderr")
+
+	// Add link prefix flag
+	rootCmd.Flags().String("link-prefix", "", "Create symlinks pointing to PATH/name instead of computing a relative or absolute path")
+	rootCmd.Flags().String("link-style", string(filesystem.LinkStyleAuto), "Target path of new symlinks: auto, relative or absolute")
+	rootCmd.Flags().Int("max-up-levels", 0, fmt.Sprintf("Use an absolute symlink when the relative one needs more than N levels up (-1 = no limit, default %d with --link-style auto)", filesystem.DefaultMaxUpLevels))
+	rootCmd.Flags().String("rename-pattern", "", "Name links after their source file rewritten by a sed-style substitution, e.g. 's/\\.disabled$//'")
+
+	// Add link mode flag
+	rootCmd.Flags().String("mode", string(filesystem.LinkModeSymlink), "How to link selected files: symlink, copy or hardlink")
+
+	// Add backup flag
+	rootCmd.Flags().Bool("backup", false, "Move regular target files in the way of new links to NAME.bak instead of removing them")
+
+	// Add source filter flags
+	rootCmd.Flags().StringSlice("include", nil, "Only manage source files matching these glob patterns (repeatable or comma-separated, e.g. '*.conf')")
+	rootCmd.Flags().StringSlice("exclude", nil, "Glob patterns of source files to ignore (repeatable or comma-separated, e.g. '*.bak,README.md')")
+
+	// Add also-check flag
+	rootCmd.Flags().StringSlice("also-check", nil, "Mark files already linked from these other target directories (repeatable or comma-separated, informational only)")
+
+	// Add recap flag
+	rootCmd.Flags().Bool("recap", false, "Print source, target and file counts before showing the UI")
+
+	// Add checkbox style flag
+	rootCmd.Flags().Bool("checkbox-ascii", false, "Render selection checkboxes as [x]/[ ] instead of unicode glyphs")
+	rootCmd.Flags().String("color-cursor", "", "Color of the item under the cursor: ANSI number or #rrggbb (default 10, env: LNKA_THEME)")
+	rootCmd.Flags().String("color-linked", "", "Color of linked items: ANSI number or #rrggbb (default terminal color, env: LNKA_THEME)")
+	rootCmd.Flags().String("color-unlinked", "", "Color of unlinked items: ANSI number or #rrggbb (default 240, env: LNKA_THEME)")
+	rootCmd.Flags().Bool("no-color", false, "Render the UI without colors or text styling (env: NO_COLOR)")
+	rootCmd.Flags().Bool("no-mouse", false, "Disable mouse support (for terminals that mangle mouse input)")
+	rootCmd.Flags().Bool("watch", false, "Refresh the list when files are created or removed in the source or target")
+
+	// Add sort flag
+	rootCmd.Flags().String("sort", config.SortName, "Initial sort order of the UI: name, mtime or size (s cycles, S reverses)")
+	rootCmd.Flags().Bool("enabled-first", false, "Group the enabled files before the others in the UI (e toggles)")
+	rootCmd.Flags().String("filter-mode", config.FilterFuzzy, "Initial matching of the / filter: fuzzy or regex (ctrl+r switches while filtering)")
+	rootCmd.Flags().Bool("case-sensitive", false, "Match the case of the / filter term instead of ignoring it")
+	rootCmd.Flags().Bool("content-search", false, "Let the / filter also match the first 4 KB of each text file (slower; ctrl+g switches while filtering)")
+	rootCmd.Flags().Int("page-size", 0, "Show N items per page (0 = as many as fit the window)")
+
+	// Add file manager flag
+	rootCmd.Flags().Bool("allow-open", false, "Enable the O key to reveal the selected link in the file manager")
+
+	// Add selection input/output flags
+	rootCmd.Flags().String("select-json", "", "Read the selection as a JSON array from FILE (- for stdin) instead of showing the UI")
+	rootCmd.Flags().Bool("stdin", false, "Read the files to link from stdin, one per line (# comments allowed), instead of showing the UI")
+	rootCmd.Flags().Bool("ignore-missing", false, "With --stdin, skip files missing from the source instead of failing")
+	rootCmd.Flags().Bool("print-selection", false, "Print the selection instead of applying it")
+	rootCmd.Flags().String("format", config.OutputText, "Output format of --print-selection and of the summary of applied changes: text or json")
+	// --output (-o) is the former name of --format, sharing its value
+	rootCmd.Flags().VarP(rootCmd.Flags().Lookup("format").Value, "output", "o", "Output format (deprecated, use --format)")
+	_ = rootCmd.Flags().MarkDeprecated("output", "use --format instead")
+	rootCmd.Flags().BoolP("quiet", "q", false, "Print nothing but errors, e.g. in cron jobs (requires --enable, --disable, --enable-all, --stdin, --apply or --select-json)")
+
+	// Add non-interactive selection flags
+	rootCmd.Flags().StringSlice("enable", nil, "Link these files without showing the UI (repeatable or comma-separated)")
+	rootCmd.Flags().StringSlice("disable", nil, "Unlink these files without showing the UI (repeatable or comma-separated)")
+	rootCmd.Flags().Bool("enable-all", false, "Link every available file without showing the UI")
+
+	// Add preset flags
+	rootCmd.Flags().String("preset", "", "Preselect the files of this saved preset in the UI (save presets with w)")
+	rootCmd.Flags().Bool("apply", false, "Apply the --preset directly without showing the UI")
+
+	// Add tags flag
+	rootCmd.Flags().String("tags", "", "JSON file mapping file names to tags, filterable with #tag")
+
+	// Add confirmation flags
+	rootCmd.Flags().Bool("confirm", false, "Show a summary of the changes and ask before applying the selection")
+	rootCmd.Flags().BoolP("yes", "y", false, "Answer yes to all confirmation prompts")
+	rootCmd.Flags().Bool("allow-teardown", false, "Allow removing all managed symlinks without confirmation")
+
+	// Add source mode flag
+	rootCmd.Flags().String("enforce-source-mode", "", "Set permissions of linked source files to this octal mode (e.g., 0644)")
+
+	// Add recursive flag
+	rootCmd.Flags().BoolP("recursive", "r", false, "Manage files in source subdirectories, linking them into matching target subdirectories")
+	rootCmd.Flags().BoolP("all", "a", false, "Include source files starting with a dot (hidden by default, like ls)")
+	rootCmd.Flags().Bool("follow", false, "Recognize links reaching a source file through other symlinks and show their final targets")
+	rootCmd.Flags().Duration("timeout", 0, "Give up reading the source or target after this long, e.g. 10s on a hanging network mount (0 = no limit)")
+	rootCmd.Flags().Bool("dirs", false, "Also list source directories, linking each as a whole (not with --recursive)")
+
+	// Add shadow flag
+	rootCmd.Flags().Bool("include-shadows-as-orphans", false, "Offer to replace regular target files named like source files with symlinks (keeping a backup)")
+
+	// Add normalize flag
+	rootCmd.Flags().Bool("normalize", false, "Rewrite symlinks into the source (e.g. absolute ones) to the canonical relative form")
+
+	// Add prune flag
+	rootCmd.Flags().Bool("prune-empty-dirs", false, "Remove target subdirectories left empty after removing symlinks")
+
+	// Add safety flags
+	rootCmd.Flags().String("removable-allowlist", "", "File listing the only symlink names that may be removed")
+	rootCmd.Flags().Bool("strict", false, "Treat warnings as errors")
+	rootCmd.Flags().Bool("verify-after", false, "Report symlinks left dangling after applying changes (errors with --strict)")
+	rootCmd.Flags().Bool("continue-on-error", false, "Keep applying remaining changes after a failure and report all errors at the end")
+
+	// Add dry run flags
+	rootCmd.Flags().BoolP("dry-run", "n", false, "Print the planned changes without touching the filesystem")
+	rootCmd.Flags().Bool("detailed-exitcode", false, "With --dry-run, exit with code 10 when changes are pending")
+
+	// Add additive flag
+	rootCmd.Flags().Bool("add", false, "Add the selected files to the existing links without removing any")
+
+	// Add incremental relink flag
+	rootCmd.Flags().Bool("only-changed", false, "Recreate links of selected files whose source is newer than the link")
+
+	// Add systemd plan flag
+	rootCmd.Flags().Bool("emit-systemd", false, "Experimental: print the plan as systemctl enable/disable commands instead of applying it")
+
+	// Add bootstrap flag
+	rootCmd.Flags().Bool("bootstrap", false, "Only create symlinks, refusing to run if the target already has managed symlinks")
+	rootCmd.Flags().Bool("mkdir", false, "Create the target directory (and missing parents) if it does not exist")
 }
 
 func printVersion() {
@@ -53,10 +209,8 @@ func printVersion() {
 }
 
 func main() {
-	if err := rootCmd.Execute(); err != nil {
-		// Cobra already prints the error, just exit
-		os.Exit(1)
-	}
+	// Cobra already prints the error, just exit with its code
+	os.Exit(execute(rootCmd, os.Args[1:]))
 }
 
 func run(cmd *cobra.Command, args []string) error {
@@ -68,6 +222,10 @@ func run(cmd *cobra.Command, args []string) error {
 
 	// Setup debug logging if debug flag is set
 	debugFile, _ := cmd.Flags().GetString("debug")
+	debugFormat, _ := cmd.Flags().GetString("debug-format")
+	if debugFormat != "text" && debugFormat != "json" {
+		return fmt.Errorf("invalid debug format %q: expected text or json", debugFormat)
+	}
 	if debugFile != "" {
 		// Remove existing debug file to start fresh
 		_ = os.Remove(debugFile)
@@ -80,6 +238,22 @@ func run(cmd *cobra.Command, args []string) error {
 
 		// Enable debug logging in UI package
 		ui.SetDebugEnabled(true)
+		if debugFormat == "json" {
+			ui.SetDebugJSON(f)
+		}
+	}
+
+	// Let the user pick omitted directories when running in a terminal
+	// (a profile provides them instead, scripted runs never prompt)
+	profile, _ := cmd.Flags().GetString("profile")
+	scripted := cmd.Flags().Changed("stdin") || cmd.Flags().Changed("enable") || cmd.Flags().Changed("disable") ||
+		cmd.Flags().Changed("enable-all") || cmd.Flags().Changed("apply")
+	if len(args) < 2 && profile == "" && !scripted && isatty.IsTerminal(os.Stdin.Fd()) {
+		var err error
+		args, err = pickMissingDirs(args)
+		if err != nil {
+			return err
+		}
 	}
 
 	// Load configuration
@@ -88,49 +262,1080 @@ func run(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("configuration error: %w", err)
 	}
 
+	// Version shown in the info panel of the UI
+	ui.SetVersion(version)
+
+	// Colors of the UI; NO_COLOR or --no-color turn off styling for all prompts
+	theme := cfg.Theme(warnf)
+
+	// Filesystem options shared by all symlink operations
+	fsOpts := filesystem.Options{
+		LinkPrefix:   cfg.LinkPrefix,
+		Style:        cfg.LinkStyle,
+		MaxUpLevels:  cfg.MaxUpLevels,
+		Mode:         cfg.LinkMode,
+		Backup:       cfg.Backup,
+		Recursive:    cfg.Recursive,
+		Dirs:         cfg.Dirs,
+		Hidden:       cfg.Hidden,
+		Follow:       cfg.Follow,
+		Rename:       cfg.Rename,
+		Include:      cfg.Include,
+		Exclude:      cfg.Exclude,
+		SourceMode:   cfg.SourceMode,
+		CreateTarget: cfg.Mkdir,
+		Warnf:        warnf,
+	}
+	fsOpts.LinkNames, fsOpts.PreviousLinkNames = recordedLinkNames(cfg.SourceDir, cfg.TargetDir)
+
+	// In bootstrap mode refuse a populated target before the user starts selecting
+	if cfg.Bootstrap {
+		for _, dir := range cfg.TargetDirs {
+			if err := filesystem.CheckBootstrapTarget(cfg.SourceDir, dir, fsOpts); err != nil {
+				return err
+			}
+		}
+	}
+
+	// UI options, including the optional tag file
+	selectOpts := ui.FileSelectOptions{
+		Filesystem:      fsOpts,
+		ASCIICheckboxes: cfg.CheckboxASCII,
+		Theme:           theme,
+		AllowOpen:       cfg.AllowOpen,
+		SortBy:          cfg.Sort,
+		EnabledFirst:    cfg.EnabledFirst,
+		FilterMode:      cfg.FilterMode,
+		CaseSensitive:   cfg.CaseSensitive,
+		ContentSearch:   cfg.ContentSearch,
+		PageSize:        cfg.PageSize,
+		SavePreset:      preset.SavePreset,
+		ConfirmApply:    cfg.ConfirmApply && !cfg.AssumeYes,
+		Mouse:           !cfg.NoMouse,
+		Watch:           cfg.Watch,
+		Timeout:         cfg.Timeout,
+		ExtraTargets:    cfg.TargetDirs[1:],
+		AlsoCheck:       cfg.AlsoCheck,
+		SaveCursor: func(name string) {
+			if err := state.SaveCursor(cfg.TargetDir, name); err != nil {
+				warnf("cannot remember the cursor position: %v", err)
+			}
+		},
+		SetLinkNames: func(linkNames map[string]string) {
+			if fsOpts.LinkNames == nil {
+				fsOpts.LinkNames = make(map[string]string, len(linkNames))
+			}
+			maps.Copy(fsOpts.LinkNames, linkNames)
+		},
+	}
+	if selectOpts.Cursor, err = state.LoadCursor(cfg.TargetDir); err != nil {
+		warnf("cannot restore the cursor position: %v", err)
+	}
+	if cfg.TagsFile != "" {
+		selectOpts.Tags, err = config.LoadTags(cfg.TagsFile)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Load optional removable allowlist
+	var removableAllowlist map[string]bool
+	if cfg.AllowlistFile != "" {
+		removableAllowlist, err = config.LoadAllowlist(cfg.AllowlistFile)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Clean up each target right before applying to it, under the same
+	// heading; the UI shows the targets as cleaned up, so for it all targets
+	// are cleaned up first and tidy is nil afterwards. --emit-systemd only
+	// prints commands, so it neither changes the target nor mixes reports
+	// into them.
+	var tidy func(cfg *config.Config) error
+	if !cfg.EmitSystemd {
+		tidy = func(cfg *config.Config) error {
+			return tidyTarget(cmd.Context(), cfg, fsOpts, theme)
+		}
+	}
+	if tidy != nil && !cfg.NonInteractive() && cfg.SelectJSON == "" {
+		if err := forEachTarget(cfg, theme, tidy); err != nil {
+			return err
+		}
+		tidy = nil
+	}
+
+	// Read the selection non-interactively or show multi-select UI
+	// (loads files asynchronously in Init())
+	var selectedFiles []string
+	var presetFiles []string
+	perTarget := false
+	selectFor := func(*config.Config) ([]string, error) {
+		return selectedFiles, nil
+	}
+	if cfg.Preset != "" {
+		presetFiles, err = loadPreset(cfg, fsOpts)
+		if err != nil {
+			return err
+		}
+		selectOpts.Preselect = presetFiles
+	}
+	if cfg.ApplyPreset {
+		selectedFiles = presetFiles
+	} else if cfg.Stdin {
+		selectedFiles, err = selectFromStdin(cfg, fsOpts)
+		if err != nil {
+			return err
+		}
+	} else if cfg.NonInteractive() {
+		// --enable and --disable change the links each target has
+		perTarget = true
+		selectFor = func(cfg *config.Config) ([]string, error) {
+			return selectFromFlags(cfg, fsOpts)
+		}
+	} else if cfg.SelectJSON != "" {
+		selectedFiles, err = selectFromJSON(cfg, fsOpts)
+		if err != nil {
+			return err
+		}
+	} else {
+		selectedFiles, err = showFileSelect(cfg.SourceDir, cfg.TargetDir, cfg.Title, selectOpts)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Emit the selection for other tools instead of applying it
+	if cfg.PrintSelection {
+		if !perTarget || len(cfg.TargetDirs) <= 1 {
+			if tidy != nil {
+				if err := forEachTarget(cfg, theme, tidy); err != nil {
+					return err
+				}
+			}
+			selection, err := selectFor(cfg)
+			if err != nil {
+				return err
+			}
+			return config.WriteSelection(os.Stdout, selection, cfg.Output)
+		}
+		return printTargetSelections(cfg, theme, tidy, selectFor)
+	}
+
+	// Apply the selection to each target
+	var summaries []targetSummary
+	err = forEachTarget(cfg, theme, func(cfg *config.Config) error {
+		if tidy != nil {
+			if err := tidy(cfg); err != nil {
+				return err
+			}
+		}
+		selection, err := selectFor(cfg)
+		if err != nil {
+			return err
+		}
+		result, err := applySelection(cfg, selection, fsOpts, removableAllowlist, theme)
+		if result != nil {
+			summaries = append(summaries, targetSummary{Target: cfg.TargetDir, ChangeResult: result})
+		}
+		return err
+	})
+	if cfg.Output == config.OutputJSON && len(summaries) > 0 {
+		if writeErr := writeChangeSummaries(os.Stdout, summaries, len(cfg.TargetDirs) > 1); writeErr != nil {
+			return writeErr
+		}
+	}
+	// Totals of the targets that were applied, unless one failed
+	if cfg.Output != config.OutputJSON && len(summaries) > 1 && (err == nil || exitCode(err) == exitCodeChangesPending) {
+		fmt.Fprintln(messageWriter(cfg), formatTotals(summaries, cfg.DryRun))
+	}
+	return err
+}
+
+// printTargetSelections cleans up each target with tidy (unless nil) and
+// prints its selection, in JSON mode as one array of objects with the target
+// and its selection
+func printTargetSelections(cfg *config.Config, theme config.Theme, tidy func(*config.Config) error, selectFor func(*config.Config) ([]string, error)) error {
+	type targetSelection struct {
+		Target    string   `json:"target"`
+		Selection []string `json:"selection"`
+	}
+	var selections []targetSelection
+	err := forEachTarget(cfg, theme, func(cfg *config.Config) error {
+		if tidy != nil {
+			if err := tidy(cfg); err != nil {
+				return err
+			}
+		}
+		selection, err := selectFor(cfg)
+		if err != nil {
+			return err
+		}
+		if cfg.Output == config.OutputJSON {
+			selections = append(selections, targetSelection{Target: cfg.TargetDir, Selection: append([]string{}, selection...)})
+			return nil
+		}
+		return config.WriteSelection(os.Stdout, selection, cfg.Output)
+	})
+	if err != nil || cfg.Output != config.OutputJSON {
+		return err
+	}
+
+	data, err := json.Marshal(selections)
+	if err != nil {
+		return fmt.Errorf("failed to encode selection: %w", err)
+	}
+	_, err = fmt.Fprintln(os.Stdout, string(data))
+	return err
+}
+
+// forEachTarget runs fn with a copy of cfg for each target directory, with a
+// heading in the colors of theme naming the target when there are several. A
+// detailed dry run reports pending changes after all targets, other errors
+// stop at once.
+func forEachTarget(cfg *config.Config, theme config.Theme, fn func(cfg *config.Config) error) error {
+	targets := cfg.TargetDirs
+	if len(targets) == 0 {
+		targets = []string{cfg.TargetDir}
+	}
+
+	home, _ := os.UserHomeDir()
+	style := targetHeadingStyle(theme)
+	var pending error
+	for _, dir := range targets {
+		if len(targets) > 1 && cfg.Output != config.OutputJSON {
+			fmt.Fprintln(messageWriter(cfg), formatTargetHeading(dir, home, style))
+		}
+		targetCfg := *cfg
+		targetCfg.TargetDir = dir
+		err := fn(&targetCfg)
+		var exit *exitError
+		if errors.As(err, &exit) && exit.code == exitCodeChangesPending {
+			pending = err
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return pending
+}
+
+// tidyTarget offers to clean broken and stale symlinks of the target and to re-point
+// symlinks into another directory, after printing the recap if requested
+func tidyTarget(ctx context.Context, cfg *config.Config, fsOpts filesystem.Options, theme config.Theme) error {
+	out := messageWriter(cfg)
+
 	// Check for orphaned symlinks
-	orphaned, err := filesystem.ValidateSymlinks(cfg.SourceDir, cfg.TargetDir)
+	scanCtx, cancelScan := filesystem.TimeoutContext(ctx, cfg.Timeout)
+	defer cancelScan()
+	targetState, err := filesystem.ReadTargetStateContext(scanCtx, cfg.SourceDir, cfg.TargetDir, fsOpts)
 	if err != nil {
 		return fmt.Errorf("failed to validate symlinks: %w", err)
 	}
+	orphaned := targetState.Orphaned
 
-	// If there are orphaned symlinks, ask user if they want to clean them
-	if len(orphaned) > 0 {
-		fmt.Printf("Found %d orphaned symlink(s):\n", len(orphaned))
-		for _, name := range orphaned {
-			fmt.Printf("  - %s\n", name)
+	// Links whose name left the source resolve fine but are leftovers as well
+	stale, err := filesystem.FindStaleSymlinksContext(scanCtx, cfg.SourceDir, cfg.TargetDir, fsOpts)
+	if err != nil {
+		return fmt.Errorf("failed to find stale symlinks: %w", err)
+	}
+	stale = withoutNames(stale, orphaned)
+
+	// Regular files shadowing source files are handled like orphans
+	var shadows []string
+	if cfg.IncludeShadows {
+		shadows, err = filesystem.FindShadowFilesWithOptions(cfg.SourceDir, cfg.TargetDir, fsOpts)
+		if err != nil {
+			return fmt.Errorf("failed to find shadow files: %w", err)
 		}
-		fmt.Println()
+	}
 
-		confirmed, err := ui.ShowConfirmation("Do you want to clean these orphaned symlinks?")
+	// Print recap so a wrong directory is noticed before selecting
+	if cfg.Recap {
+		recap, err := buildRecap(cfg.SourceDir, cfg.TargetDir, len(targetState.Enabled), len(orphaned)+len(stale)+len(shadows), fsOpts)
 		if err != nil {
-			if strings.Contains(err.Error(), "user aborted") {
-				os.Exit(1)
-			}
 			return err
 		}
+		fmt.Fprintln(out, recap)
+	}
+
+	// If there are leftovers, ask user if they want to clean them
+	if len(orphaned) > 0 || len(stale) > 0 || len(shadows) > 0 {
+		fmt.Fprintf(out, "Found %d leftover(s) in the target:\n", len(orphaned)+len(stale)+len(shadows))
+		for _, name := range orphaned {
+			fmt.Fprintf(out, "  - %s (broken)\n", name)
+		}
+		for _, name := range stale {
+			fmt.Fprintf(out, "  - %s (no longer in source)\n", name)
+		}
+		for _, name := range shadows {
+			link := fsOpts.LinkName(name)
+			fmt.Fprintf(out, "  - %s (regular file, replaced by a symlink; backup kept as %s)\n",
+				link, link+filesystem.BackupSuffix)
+		}
+		fmt.Fprintln(out)
+
+		confirmed := cfg.AssumeYes
+		if cfg.DryRun {
+			// Only report what would be cleaned
+			fmt.Fprintf(out, "Would clean %d leftover(s)\n\n", len(orphaned)+len(stale)+len(shadows))
+			confirmed = false
+		} else if !confirmed && cfg.NonInteractive() {
+			fmt.Fprintf(out, "Skipping cleanup, use --yes to clean them without a prompt\n\n")
+		} else if !confirmed {
+			// Removing links is destructive, so declining is the default
+			confirmed, err = showConfirmationWithDefault("Do you want to clean up these leftovers?", false, theme)
+			if err != nil {
+				return err
+			}
+		}
 
 		if confirmed {
-			if err := filesystem.CleanOrphanedSymlinks(cfg.TargetDir, orphaned); err != nil {
+			leftovers := append(append([]string{}, orphaned...), stale...)
+			if err := filesystem.CleanOrphanedSymlinks(cfg.TargetDir, leftovers); err != nil {
 				return fmt.Errorf("failed to clean orphaned symlinks: %w", err)
 			}
-			fmt.Printf("Cleaned %d orphaned symlink(s)\n\n", len(orphaned))
+			if cfg.PruneEmptyDirs {
+				if err := filesystem.PruneEmptyDirs(cfg.TargetDir, leftovers); err != nil {
+					return fmt.Errorf("failed to prune empty directories: %w", err)
+				}
+			}
+			if err := filesystem.ReplaceShadowFiles(cfg.SourceDir, cfg.TargetDir, shadows, fsOpts); err != nil {
+				return err
+			}
+			fmt.Fprintf(out, "Cleaned %d leftover(s)\n\n", len(orphaned)+len(stale)+len(shadows))
 		}
 	}
 
-	// Show multi-select UI (loads files asynchronously in Init())
-	selectedFiles, err := ui.ShowFileSelect(cfg.SourceDir, cfg.TargetDir, cfg.Title)
-	if err != nil {
-		if strings.Contains(err.Error(), "user aborted") {
-			os.Exit(1)
+	// Rewrite links into the source to the form CreateSymlink produces
+	if cfg.Normalize {
+		if err := normalizeLinks(cfg, fsOpts); err != nil {
+			return err
 		}
+	}
+
+	// Offer to re-point symlinks into another directory (e.g. an old source location)
+	// at the file of the same name in the current source
+	mismatched, err := findRepointable(cfg.SourceDir, cfg.TargetDir, fsOpts)
+	if err != nil {
 		return err
 	}
+	if len(mismatched) > 0 {
+		fmt.Fprintf(out, "Found %d symlink(s) pointing outside the source directory:\n", len(mismatched))
+		names := make([]string, 0, len(mismatched))
+		for name := range mismatched {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Fprintf(out, "  - %s -> %s\n", name, mismatched[name])
+		}
+		fmt.Fprintln(out)
+
+		confirmed := cfg.AssumeYes
+		if cfg.DryRun {
+			fmt.Fprintf(out, "Would re-point %d symlink(s)\n\n", len(names))
+			confirmed = false
+		} else if !confirmed && cfg.NonInteractive() {
+			fmt.Fprintf(out, "Skipping re-pointing, use --yes to re-point them without a prompt\n\n")
+		} else if !confirmed {
+			confirmed, err = showConfirmation("Do you want to re-point these symlinks at the source directory?", theme)
+			if err != nil {
+				return err
+			}
+		}
+
+		if confirmed {
+			if err := filesystem.RepointSymlinks(cfg.SourceDir, cfg.TargetDir, names, fsOpts); err != nil {
+				return err
+			}
+			fmt.Fprintf(out, "Re-pointed %d symlink(s)\n\n", len(names))
+		}
+	}
+
+	return nil
+}
+
+// messageWriter returns where to report the cleanup, repairs and applied
+// changes: stdout, stderr when stdout carries the JSON summary, or nowhere
+// with --quiet
+func messageWriter(cfg *config.Config) io.Writer {
+	if cfg.Quiet {
+		return io.Discard
+	}
+	if cfg.Output == config.OutputJSON {
+		return os.Stderr
+	}
+	return os.Stdout
+}
+
+// applySelection links the selected files in the target and removes the
+// other managed links, unless a prompt declines the changes. Returns what was
+// applied (nil = nothing), which JSON mode writes for all targets at once.
+func applySelection(cfg *config.Config, selectedFiles []string, fsOpts filesystem.Options, removableAllowlist map[string]bool, theme config.Theme) (*filesystem.ChangeResult, error) {
+	// Print the plan as systemctl commands without touching the target
+	if cfg.EmitSystemd {
+		changes, err := planChanges(cfg, selectedFiles, fsOpts)
+		if err != nil {
+			return nil, err
+		}
+		for _, line := range formatSystemdPlan(changes) {
+			fmt.Println(line)
+		}
+		return nil, nil
+	}
+
+	out := messageWriter(cfg)
+
+	// Guard against accidentally removing every managed symlink
+	// (additive mode never removes links, dry runs never touch the target)
+	if !cfg.AssumeYes && !cfg.AllowTeardown && !cfg.Add && !cfg.DryRun {
+		previouslyEnabled, err := filesystem.GetEnabledFilesWithOptions(cfg.SourceDir, cfg.TargetDir, fsOpts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get currently enabled files: %w", err)
+		}
+
+		if isFullTeardown(previouslyEnabled, selectedFiles) {
+			if cfg.NonInteractive() {
+				return nil, fmt.Errorf("refusing to remove all %d links without --yes or --allow-teardown", len(previouslyEnabled))
+			}
+			message := fmt.Sprintf("This will remove ALL %d links. Continue?", len(previouslyEnabled))
+			confirmed, err := showDangerConfirmation(message, theme)
+			if err != nil {
+				return nil, err
+			}
+			if !confirmed {
+				fmt.Fprintln(out, "No changes applied")
+				return nil, nil
+			}
+		}
+	}
+
+	// Let the user review large change sets before touching the target
+	if !cfg.AssumeYes && !cfg.DryRun && !cfg.NonInteractive() {
+		changes, err := planChanges(cfg, selectedFiles, fsOpts)
+		if err != nil {
+			return nil, err
+		}
+		if len(changes.Create)+len(changes.Remove)+len(changes.Rename) > reviewChangesThreshold {
+			confirmed, err := showChangeReview(changes)
+			if err != nil {
+				return nil, err
+			}
+			if !confirmed {
+				fmt.Fprintln(out, "No changes applied")
+				return nil, nil
+			}
+		}
+	}
+
+	// Ask before replacing regular files with links (backups keep them instead)
+	if !cfg.Backup && !cfg.DryRun {
+		proceed, err := confirmConflicts(cfg, selectedFiles, &fsOpts, theme)
+		if err != nil {
+			return nil, err
+		}
+		if !proceed {
+			fmt.Fprintln(out, "No changes applied")
+			return nil, &exitError{code: exitCodeConflicts}
+		}
+	}
 
 	// Apply changes
-	if err := filesystem.ApplyChanges(cfg.SourceDir, cfg.TargetDir, selectedFiles); err != nil {
-		return fmt.Errorf("failed to apply changes: %w", err)
+	opts := filesystem.ApplyOptions{
+		Options:            fsOpts,
+		Bootstrap:          cfg.Bootstrap,
+		PruneEmptyDirs:     cfg.PruneEmptyDirs,
+		Strict:             cfg.Strict,
+		Logf:               verboseLogf(cfg.Verbose),
+		RemovableAllowlist: removableAllowlist,
+		ContinueOnError:    cfg.ContinueOnError,
+		Additive:           cfg.Add,
+		VerifyAfter:        cfg.VerifyAfter,
+		OnlyChanged:        cfg.OnlyChanged,
+		DryRun:             cfg.DryRun,
+	}
+
+	// Remember the current links so the apply can be undone
+	var previous []string
+	if !cfg.DryRun {
+		var err error
+		previous, err = filesystem.GetEnabledFilesWithOptions(cfg.SourceDir, cfg.TargetDir, fsOpts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get currently enabled files: %w", err)
+		}
 	}
 
+	result, err := filesystem.ApplyChangesWithOptions(cfg.SourceDir, cfg.TargetDir, selectedFiles, opts)
+	if err == nil && !cfg.DryRun {
+		recordUndo(cfg.SourceDir, cfg.TargetDir, previous, result, fsOpts)
+	}
+	if err != nil {
+		if len(result.Failed) > 0 {
+			fmt.Fprintf(out, "Created %d and removed %d symlink(s), %d failed\n",
+				len(result.Created), len(result.Removed), len(result.Failed))
+		}
+		return nil, fmt.Errorf("failed to apply changes: %w", err)
+	}
+
+	// Machine-readable summaries of all targets replace all other output
+	if cfg.Output != config.OutputJSON {
+		if !cfg.DryRun {
+			for _, name := range result.ModeChanged {
+				fmt.Fprintf(out, "Changed mode of %s to %04o\n", name, cfg.SourceMode)
+			}
+		}
+		for _, path := range result.BackedUp {
+			fmt.Fprintf(out, "Backed up existing file to %s\n", path)
+		}
+		if cfg.DryRun {
+			for _, line := range formatDryRun(result) {
+				fmt.Fprintln(out, line)
+			}
+		} else if cfg.Add {
+			fmt.Fprintf(out, "Added %d link(s), kept the rest\n", len(result.Created))
+		} else {
+			if len(result.Renamed) > 0 {
+				fmt.Fprintf(out, "Renamed %d link(s)\n", len(result.Renamed))
+			}
+			fmt.Fprintf(out, "Created %d and removed %d symlink(s), %d unchanged\n",
+				len(result.Created), len(result.Removed), len(result.Unchanged))
+		}
+	}
+
+	if cfg.DryRun && cfg.DetailedExitCode {
+		if code := dryRunExitCode(&filesystem.ChangeSet{Create: result.Created, Remove: result.Removed, Rename: result.Renamed, Update: result.Relinked, Chmod: result.ModeChanged}); code != 0 {
+			return result, &exitError{code: code}
+		}
+	}
+
+	return result, nil
+}
+
+// recordUndo stores the links before and after an apply in the undo journal
+// Applies without changes keep the previous record; failures only warn.
+func recordUndo(sourceDir, targetDir string, previous []string, result *filesystem.ChangeResult, opts filesystem.Options) {
+	if len(result.Created)+len(result.Removed)+len(result.Relinked)+len(result.Renamed) == 0 {
+		return
+	}
+
+	// The undo may run from another working directory
+	absSource, err := filepath.Abs(sourceDir)
+	if err != nil {
+		warnf("failed to record undo information: %v", err)
+		return
+	}
+
+	applied, err := filesystem.GetEnabledFilesWithOptions(sourceDir, targetDir, opts)
+	if err == nil {
+		err = filesystem.SaveUndoRecord(targetDir, filesystem.UndoRecord{
+			SourceDir:   absSource,
+			Previous:    previous,
+			Applied:     applied,
+			Mode:        opts.Mode,
+			LinkPrefix:  opts.LinkPrefix,
+			Style:       opts.Style,
+			MaxUpLevels: opts.MaxUpLevels,
+			Recursive:   opts.Recursive,
+			Dirs:        opts.Dirs,
+			Hidden:      opts.Hidden,
+			Follow:      opts.Follow,
+			Rename:      opts.Rename,
+			Include:     opts.Include,
+			Exclude:     opts.Exclude,
+
+			LinkNames:         opts.LinkNames,
+			PreviousLinkNames: opts.PreviousLinkNames,
+		})
+	}
+	if err != nil {
+		warnf("failed to record undo information: %v", err)
+	}
+}
+
+// recordedLinkNames returns the custom link names the last apply from the
+// source to the target left (from its undo record), once as the names to
+// keep and once as the names the links have now
+func recordedLinkNames(sourceDir, targetDir string) (map[string]string, map[string]string) {
+	record := loadSourceRecord(sourceDir, targetDir)
+	if record == nil || len(record.LinkNames) == 0 {
+		return nil, nil
+	}
+	return maps.Clone(record.LinkNames), record.LinkNames
+}
+
+// recordedOptions returns the link options of the last apply from the source
+// to the target (from its undo record), or the defaults without one
+func recordedOptions(sourceDir, targetDir string) filesystem.Options {
+	record := loadSourceRecord(sourceDir, targetDir)
+	if record == nil {
+		return filesystem.Options{}
+	}
+	opts := record.Options()
+	opts.PreviousLinkNames = record.LinkNames
+	return opts
+}
+
+// loadSourceRecord returns the undo record of the target if its last apply
+// came from the source, nil otherwise. Unreadable journals only warn.
+func loadSourceRecord(sourceDir, targetDir string) *filesystem.UndoRecord {
+	record, err := filesystem.LoadUndoRecord(targetDir)
+	if err != nil {
+		if !errors.Is(err, filesystem.ErrNoUndoRecord) {
+			warnf("cannot read the undo record: %v", err)
+		}
+		return nil
+	}
+	absSource, err := filepath.Abs(sourceDir)
+	if err != nil || record.SourceDir != absSource {
+		return nil
+	}
+	return record
+}
+
+// targetSummary is the result of applying changes to one of several targets
+type targetSummary struct {
+	Target string `json:"target"`
+	*filesystem.ChangeResult
+}
+
+// writeChangeSummary writes the result of applying changes as a JSON object
+// (lists without entries are written as empty arrays)
+func writeChangeSummary(w io.Writer, result *filesystem.ChangeResult) error {
+	data, err := json.Marshal(withEmptyLists(result))
+	if err != nil {
+		return fmt.Errorf("failed to encode summary: %w", err)
+	}
+	_, err = fmt.Fprintln(w, string(data))
+	return err
+}
+
+// writeChangeSummaries writes the results of all targets: the summary of the
+// only target as an object, or with several targets one array of summaries
+// with their target
+func writeChangeSummaries(w io.Writer, summaries []targetSummary, multiple bool) error {
+	if !multiple {
+		return writeChangeSummary(w, summaries[0].ChangeResult)
+	}
+
+	list := make([]targetSummary, len(summaries))
+	for i, summary := range summaries {
+		list[i] = targetSummary{Target: summary.Target, ChangeResult: withEmptyLists(summary.ChangeResult)}
+	}
+	data, err := json.Marshal(list)
+	if err != nil {
+		return fmt.Errorf("failed to encode summary: %w", err)
+	}
+	_, err = fmt.Fprintln(w, string(data))
+	return err
+}
+
+// formatTargetHeading returns the heading of a target's section in the text
+// output, with the home directory shortened to "~"
+func formatTargetHeading(dir, home string, style lipgloss.Style) string {
+	if home != "" {
+		if dir == home {
+			dir = "~"
+		} else if rest, ok := strings.CutPrefix(dir, home+string(filepath.Separator)); ok {
+			dir = filepath.Join("~", rest)
+		}
+	}
+	return style.Render("==> " + dir)
+}
+
+// targetHeadingStyle colors the target headings like the cursor of the UI
+func targetHeadingStyle(theme config.Theme) lipgloss.Style {
+	if theme.NoColor {
+		return lipgloss.NewStyle()
+	}
+	return lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color(theme.Cursor))
+}
+
+// formatTotals returns the line adding up the changes of all targets
+func formatTotals(summaries []targetSummary, dryRun bool) string {
+	var created, removed int
+	for _, summary := range summaries {
+		created += len(summary.Created)
+		removed += len(summary.Removed)
+	}
+	if dryRun {
+		return fmt.Sprintf("%d targets, %d to create, %d to remove total", len(summaries), created, removed)
+	}
+	return fmt.Sprintf("%d targets, %d created, %d removed total", len(summaries), created, removed)
+}
+
+// withEmptyLists returns a copy of the result with empty lists instead of nil
+func withEmptyLists(result *filesystem.ChangeResult) *filesystem.ChangeResult {
+	summary := *result
+	for _, list := range []*[]string{&summary.Created, &summary.Removed, &summary.Unchanged, &summary.Refused,
+		&summary.Relinked, &summary.Renamed, &summary.ModeChanged, &summary.Failed, &summary.BackedUp} {
+		if *list == nil {
+			*list = []string{}
+		}
+	}
+	return &summary
+}
+
+// confirmConflicts lists the target files that creating the planned links would
+// replace and asks whether to overwrite them. Accepted conflicts set
+// opts.Overwrite, so the links may replace the files.
+func confirmConflicts(cfg *config.Config, selectedFiles []string, opts *filesystem.Options, theme config.Theme) (bool, error) {
+	changes, err := planChanges(cfg, selectedFiles, *opts)
+	if err != nil {
+		return false, err
+	}
+	// Renamed links land on their new name as well
+	conflicts, err := filesystem.DetectConflicts(cfg.SourceDir, cfg.TargetDir, append(changes.Create, changes.Rename...), *opts)
+	if err != nil {
+		return false, fmt.Errorf("failed to detect conflicts: %w", err)
+	}
+	if len(conflicts) == 0 {
+		return true, nil
+	}
+	if cfg.AssumeYes {
+		opts.Overwrite = true
+		return true, nil
+	}
+
+	fmt.Printf("Found %d file(s) in the target that are not symlinks:\n", len(conflicts))
+	for _, name := range conflicts {
+		fmt.Printf("  - %s\n", name)
+	}
+	fmt.Println()
+
+	if cfg.NonInteractive() {
+		err := fmt.Errorf("refusing to overwrite %d conflicting file(s) without --yes or --backup", len(conflicts))
+		return false, &exitError{code: exitCodeConflicts, err: err}
+	}
+
+	confirmed, err := showConfirmation(fmt.Sprintf("%d conflicts found, overwrite?", len(conflicts)), theme)
+	if err != nil {
+		return false, err
+	}
+	opts.Overwrite = confirmed
+	return confirmed, nil
+}
+
+// loadPreset reads the named preset, skipping files no longer available in
+// the source with a warning
+func loadPreset(cfg *config.Config, opts filesystem.Options) ([]string, error) {
+	files, err := preset.LoadPreset(cfg.Preset)
+	if err != nil {
+		return nil, err
+	}
+	available, err := filesystem.ListAvailableFilesWithOptions(cfg.SourceDir, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list available files: %w", err)
+	}
+	return presetSelection(files, available, warnf), nil
+}
+
+// presetSelection keeps the preset files that are available, reporting
+// each skipped file through warn
+func presetSelection(files, available []string, warn func(format string, args ...any)) []string {
+	isAvailable := make(map[string]bool, len(available))
+	for _, name := range available {
+		isAvailable[name] = true
+	}
+
+	selection := make([]string, 0, len(files))
+	for _, name := range files {
+		if !isAvailable[name] {
+			warn("preset file %s is no longer in the source directory, skipping", name)
+			continue
+		}
+		selection = append(selection, name)
+	}
+	return selection
+}
+
+// selectFromStdin reads the selection from stdin, one name per line, and
+// checks it against the available files
+func selectFromStdin(cfg *config.Config, opts filesystem.Options) ([]string, error) {
+	names, err := config.ReadSelectionLines(os.Stdin)
+	if err != nil {
+		return nil, err
+	}
+	available, err := filesystem.ListAvailableFilesWithOptions(cfg.SourceDir, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list available files: %w", err)
+	}
+	return availableSelection(names, available, cfg.IgnoreMissing, warnf)
+}
+
+// selectFromJSON reads the selection from the --select-json file and checks
+// it against the available files
+func selectFromJSON(cfg *config.Config, opts filesystem.Options) ([]string, error) {
+	names, err := config.LoadSelection(cfg.SelectJSON)
+	if err != nil {
+		return nil, err
+	}
+	available, err := filesystem.ListAvailableFilesWithOptions(cfg.SourceDir, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list available files: %w", err)
+	}
+	return availableSelection(names, available, false, warnf)
+}
+
+// availableSelection keeps the names that are available in the source. A missing
+// name is an error, or is skipped with a warning through warn if ignoreMissing.
+func availableSelection(names, available []string, ignoreMissing bool, warn func(format string, args ...any)) ([]string, error) {
+	isAvailable := make(map[string]bool, len(available))
+	for _, name := range available {
+		isAvailable[name] = true
+	}
+
+	selection := make([]string, 0, len(names))
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		if !isAvailable[name] {
+			if !ignoreMissing {
+				return nil, fmt.Errorf("cannot enable %s: not available in source directory", name)
+			}
+			warn("%s is not available in the source directory, skipping", name)
+			continue
+		}
+		if !seen[name] {
+			seen[name] = true
+			selection = append(selection, name)
+		}
+	}
+	return selection, nil
+}
+
+// selectFromFlags computes the selection from the --enable, --disable and
+// --enable-all flags based on the files currently enabled in the target
+func selectFromFlags(cfg *config.Config, opts filesystem.Options) ([]string, error) {
+	available, err := filesystem.ListAvailableFilesWithOptions(cfg.SourceDir, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list available files: %w", err)
+	}
+	enabled, err := filesystem.GetEnabledFilesWithOptions(cfg.SourceDir, cfg.TargetDir, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get currently enabled files: %w", err)
+	}
+	return resolveSelection(available, enabled, cfg.Enable, cfg.Disable, cfg.EnableAll)
+}
+
+// resolveSelection applies enable and disable lists to the currently enabled
+// files. Enabling an unavailable file is an error, disabling a file that is
+// not linked is a no-op. Returns the selection sorted like available.
+func resolveSelection(available, enabled, enable, disable []string, enableAll bool) ([]string, error) {
+	isAvailable := make(map[string]bool, len(available))
+	for _, name := range available {
+		isAvailable[name] = true
+	}
+
+	selected := make(map[string]bool)
+	for _, name := range enabled {
+		selected[name] = true
+	}
+	if enableAll {
+		for _, name := range available {
+			selected[name] = true
+		}
+	}
+	for _, name := range enable {
+		if !isAvailable[name] {
+			return nil, fmt.Errorf("cannot enable %s: not available in source directory", name)
+		}
+		selected[name] = true
+	}
+	for _, name := range disable {
+		delete(selected, name)
+	}
+
+	var result []string
+	for _, name := range available {
+		if selected[name] {
+			result = append(result, name)
+		}
+	}
+	return result, nil
+}
+
+// pickMissingDirs completes the source and target arguments using the
+// interactive directory picker
+func pickMissingDirs(args []string) ([]string, error) {
+	prompts := []string{"Select the source directory", "Select the target directory"}
+	for i := len(args); i < len(prompts); i++ {
+		dir, err := showDirectoryPicker(prompts[i])
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, dir)
+	}
+	return args, nil
+}
+
+// withoutNames returns the names not contained in exclude, keeping their order
+func withoutNames(names, exclude []string) []string {
+	skip := make(map[string]bool, len(exclude))
+	for _, name := range exclude {
+		skip[name] = true
+	}
+	var kept []string
+	for _, name := range names {
+		if !skip[name] {
+			kept = append(kept, name)
+		}
+	}
+	return kept
+}
+
+// isFullTeardown reports whether applying the selection would remove every
+// managed symlink from a target that previously had several of them
+func isFullTeardown(previouslyEnabled, selectedFiles []string) bool {
+	return len(previouslyEnabled) >= teardownMinLinks && len(selectedFiles) == 0
+}
+
+// planChanges computes the changes applying the selection will make
+func planChanges(cfg *config.Config, selectedFiles []string, opts filesystem.Options) (*filesystem.ChangeSet, error) {
+	changes, err := filesystem.PlanChanges(cfg.SourceDir, cfg.TargetDir, selectedFiles, opts)
+	if err != nil {
+		return nil, err
+	}
+	// Additive mode never removes links
+	if cfg.Add {
+		changes.Remove = nil
+	}
+	return changes, nil
+}
+
+// formatSystemdPlan maps a change set to the equivalent systemctl commands
+// Removals are listed first, matching the order ApplyChanges uses
+func formatSystemdPlan(changes *filesystem.ChangeSet) []string {
+	lines := make([]string, 0, len(changes.Remove)+len(changes.Create))
+	for _, name := range changes.Remove {
+		lines = append(lines, "systemctl disable "+name)
+	}
+	for _, name := range changes.Create {
+		lines = append(lines, "systemctl enable "+name)
+	}
+	return lines
+}
+
+// normalizeLinks rewrites links into the source to their canonical form,
+// only reporting them in a dry run
+func normalizeLinks(cfg *config.Config, opts filesystem.Options) error {
+	out := messageWriter(cfg)
+	if cfg.DryRun {
+		names, err := filesystem.FindUnnormalizedSymlinks(cfg.SourceDir, cfg.TargetDir, opts)
+		if err != nil {
+			return fmt.Errorf("failed to find symlinks to normalize: %w", err)
+		}
+		for _, name := range names {
+			fmt.Fprintf(out, "~ would normalize %s\n", name)
+		}
+		return nil
+	}
+
+	names, err := filesystem.NormalizeSymlinksWithOptions(cfg.SourceDir, cfg.TargetDir, opts)
+	if err != nil {
+		return fmt.Errorf("failed to normalize symlinks: %w", err)
+	}
+	if len(names) > 0 {
+		fmt.Fprintf(out, "Normalized %d symlink(s)\n\n", len(names))
+	}
 	return nil
 }
+
+// findRepointable returns the symlinks pointing outside the source directory
+// for which a file of the same name is available in the source
+func findRepointable(sourceDir, targetDir string, opts filesystem.Options) (map[string]string, error) {
+	mismatched, err := filesystem.FindMismatchedSymlinksWithOptions(sourceDir, targetDir, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find mismatched symlinks: %w", err)
+	}
+	if len(mismatched) == 0 {
+		return nil, nil
+	}
+
+	available, err := filesystem.ListAvailableFilesWithOptions(sourceDir, opts)
+	if err != nil {
+		return nil, err
+	}
+	repointable := make(map[string]string)
+	for _, name := range available {
+		if target, ok := mismatched[name]; ok {
+			repointable[name] = target
+		}
+	}
+	return repointable, nil
+}
+
+// buildRecap counts the available files of the source directory and formats
+// them with the given target counts as a recap line
+func buildRecap(sourceDir, targetDir string, enabled, orphaned int, opts filesystem.Options) (string, error) {
+	available, err := filesystem.ListAvailableFilesWithOptions(sourceDir, opts)
+	if err != nil {
+		return "", err
+	}
+
+	// Show absolute paths, falling back to the given path
+	if abs, err := filepath.Abs(sourceDir); err == nil {
+		sourceDir = abs
+	}
+	if abs, err := filepath.Abs(targetDir); err == nil {
+		targetDir = abs
+	}
+
+	return formatRecap(sourceDir, targetDir, len(available), enabled, orphaned), nil
+}
+
+// formatRecap formats the one-line recap printed by --recap
+func formatRecap(sourceDir, targetDir string, available, enabled, orphaned int) string {
+	return fmt.Sprintf("source=%s target=%s available=%d enabled=%d orphaned=%d",
+		sourceDir, targetDir, available, enabled, orphaned)
+}
+
+// warnf prints a warning to stderr
+func warnf(format string, args ...any) {
+	fmt.Fprintf(os.Stderr, "warning: "+format+"\n", args...)
+}
+
+// verboseLogf returns the operation logger for --verbose: it writes to
+// stderr, which is only used after the UI has exited. Returns nil when
+// verbose is off.
+func verboseLogf(verbose bool) func(format string, args ...any) {
+	if !verbose {
+		return nil
+	}
+	return func(format string, args ...any) {
+		fmt.Fprintf(os.Stderr, format+"\n", args...)
+	}
+}
+
+// formatDryRun describes the operations of a dry run, one line per file
+func formatDryRun(result *filesystem.ChangeResult) []string {
+	var lines []string
+	for _, name := range result.Removed {
+		lines = append(lines, "- would unlink "+name)
+	}
+	for _, name := range result.Created {
+		lines = append(lines, "+ would link "+name)
+	}
+	for _, name := range result.Relinked {
+		lines = append(lines, "~ would relink "+name)
+	}
+	for _, name := range result.Renamed {
+		lines = append(lines, "~ would rename the link of "+name)
+	}
+	for _, name := range result.ModeChanged {
+		lines = append(lines, "~ would change the mode of "+name)
+	}
+	if len(lines) == 0 {
+		lines = append(lines, "No changes")
+	}
+	return lines
+}
+
+// dryRunExitCode maps a planned change set to the detailed dry-run exit code:
+// 0 if the target already matches the selection, exitCodeChangesPending otherwise
+func dryRunExitCode(changes *filesystem.ChangeSet) int {
+	if changes.HasChanges() {
+		return exitCodeChangesPending
+	}
+	return 0
+}
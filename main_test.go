@@ -2,10 +2,17 @@ package main
 
 import (
 	"bytes"
+	"encoding/json"
+	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/marco-arnold/lnka/internal/filesystem"
 )
 
 // TestPrintVersion tests the printVersion function
@@ -135,3 +142,469 @@ func TestVersionVariables(t *testing.T) {
 		t.Logf("date = %q (may be overridden by build)", date)
 	}
 }
+
+// TestIsFullTeardown tests detection of selections that remove every managed link
+func TestIsFullTeardown(t *testing.T) {
+	tests := []struct {
+		name     string
+		previous []string
+		selected []string
+		want     bool
+	}{
+		{
+			name:     "all links removed",
+			previous: []string{"a.conf", "b.conf", "c.conf"},
+			selected: []string{},
+			want:     true,
+		},
+		{
+			name:     "some links remain",
+			previous: []string{"a.conf", "b.conf", "c.conf"},
+			selected: []string{"a.conf"},
+			want:     false,
+		},
+		{
+			name:     "single link removed",
+			previous: []string{"a.conf"},
+			selected: []string{},
+			want:     false,
+		},
+		{
+			name:     "nothing linked before",
+			previous: []string{},
+			selected: []string{},
+			want:     false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isFullTeardown(tt.previous, tt.selected); got != tt.want {
+				t.Errorf("isFullTeardown() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestFormatRecap tests the recap line printed by --recap
+func TestFormatRecap(t *testing.T) {
+	got := formatRecap("/etc/nginx/sites-available", "/etc/nginx/sites-enabled", 12, 4, 1)
+	want := "source=/etc/nginx/sites-available target=/etc/nginx/sites-enabled available=12 enabled=4 orphaned=1"
+	if got != want {
+		t.Errorf("formatRecap() = %q, want %q", got, want)
+	}
+}
+
+// TestDryRunExitCode tests the detailed dry-run exit code mapping
+func TestDryRunExitCode(t *testing.T) {
+	tests := []struct {
+		name    string
+		changes filesystem.ChangeSet
+		want    int
+	}{
+		{
+			name:    "no changes",
+			changes: filesystem.ChangeSet{},
+			want:    0,
+		},
+		{
+			name:    "pending creation",
+			changes: filesystem.ChangeSet{Create: []string{"a.conf"}},
+			want:    exitCodeChangesPending,
+		},
+		{
+			name:    "pending removal",
+			changes: filesystem.ChangeSet{Remove: []string{"b.conf"}},
+			want:    exitCodeChangesPending,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := dryRunExitCode(&tt.changes); got != tt.want {
+				t.Errorf("dryRunExitCode() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestFormatSystemdPlan tests the mapping of a mixed plan to systemctl commands
+func TestFormatSystemdPlan(t *testing.T) {
+	changes := &filesystem.ChangeSet{
+		Create: []string{"nginx.service", "backup.timer"},
+		Remove: []string{"old.service"},
+	}
+
+	got := formatSystemdPlan(changes)
+	want := []string{
+		"systemctl disable old.service",
+		"systemctl enable nginx.service",
+		"systemctl enable backup.timer",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("formatSystemdPlan() = %v, want %v", got, want)
+	}
+
+	if got := formatSystemdPlan(&filesystem.ChangeSet{}); len(got) != 0 {
+		t.Errorf("formatSystemdPlan() = %v, want no lines for an empty plan", got)
+	}
+}
+
+// TestFormatDryRun tests the dry-run plan lines
+func TestFormatDryRun(t *testing.T) {
+	result := &filesystem.ChangeResult{
+		Created:     []string{"foo.conf"},
+		Removed:     []string{"bar.conf"},
+		ModeChanged: []string{"foo.conf"},
+	}
+
+	got := formatDryRun(result)
+	want := []string{"- would unlink bar.conf", "+ would link foo.conf", "~ would change the mode of foo.conf"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("formatDryRun() = %v, want %v", got, want)
+	}
+
+	if got := formatDryRun(&filesystem.ChangeResult{}); !reflect.DeepEqual(got, []string{"No changes"}) {
+		t.Errorf("formatDryRun() = %v, want [No changes]", got)
+	}
+}
+
+// TestResolveSelection tests computing the selection from enable/disable flags
+func TestResolveSelection(t *testing.T) {
+	available := []string{"a.conf", "b.conf", "c.conf"}
+	enabled := []string{"b.conf"}
+
+	tests := []struct {
+		name      string
+		enable    []string
+		disable   []string
+		enableAll bool
+		want      []string
+		wantErr   bool
+	}{
+		{name: "enable adds to current links", enable: []string{"c.conf"}, want: []string{"b.conf", "c.conf"}},
+		{name: "disable removes a link", disable: []string{"b.conf"}, want: nil},
+		{name: "disable unlinked is a no-op", disable: []string{"a.conf"}, want: []string{"b.conf"}},
+		{name: "enable all with disable", enableAll: true, disable: []string{"a.conf"}, want: []string{"b.conf", "c.conf"}},
+		{name: "enable unknown file", enable: []string{"missing.conf"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveSelection(available, enabled, tt.enable, tt.disable, tt.enableAll)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("resolveSelection() expected error, got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("resolveSelection() unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("resolveSelection() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestPresetSelection tests skipping preset files missing from the source
+func TestPresetSelection(t *testing.T) {
+	var warnings []string
+	warn := func(format string, args ...any) {
+		warnings = append(warnings, fmt.Sprintf(format, args...))
+	}
+
+	got := presetSelection([]string{"b.conf", "gone.conf", "a.conf"}, []string{"a.conf", "b.conf"}, warn)
+	if want := []string{"b.conf", "a.conf"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("presetSelection() = %v, want %v", got, want)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "gone.conf") {
+		t.Errorf("warnings = %v, want one warning about gone.conf", warnings)
+	}
+}
+
+// TestAvailableSelection tests validating names read with --stdin
+func TestAvailableSelection(t *testing.T) {
+	available := []string{"a.conf", "b.conf"}
+	var warnings []string
+	warn := func(format string, args ...any) {
+		warnings = append(warnings, fmt.Sprintf(format, args...))
+	}
+
+	if _, err := availableSelection([]string{"a.conf", "gone.conf"}, available, false, warn); err == nil {
+		t.Error("availableSelection() expected error for a missing file")
+	}
+
+	got, err := availableSelection([]string{"b.conf", "gone.conf", "b.conf", "a.conf"}, available, true, warn)
+	if err != nil {
+		t.Fatalf("availableSelection() unexpected error: %v", err)
+	}
+	if want := []string{"b.conf", "a.conf"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("availableSelection() = %v, want %v", got, want)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "gone.conf") {
+		t.Errorf("warnings = %v, want one warning about gone.conf", warnings)
+	}
+}
+
+// TestRun_SelectJSON tests that --select-json only accepts available files
+func TestRun_SelectJSON(t *testing.T) {
+	sourceDir, targetDir := t.TempDir(), t.TempDir()
+	if err := os.WriteFile(filepath.Join(sourceDir, "a.conf"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	outside := filepath.Join(filepath.Dir(targetDir), "x")
+
+	for _, names := range []string{`["a.conf", "../x"]`, `["gone.conf"]`} {
+		path := filepath.Join(t.TempDir(), "selection.json")
+		if err := os.WriteFile(path, []byte(names), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if got := runLnka(t, sourceDir, targetDir, "--select-json", path); got != exitCodeError {
+			t.Errorf("selection %s: exit code = %d, want %d", names, got, exitCodeError)
+		}
+		if _, err := os.Lstat(filepath.Join(targetDir, "a.conf")); !os.IsNotExist(err) {
+			t.Errorf("selection %s: a.conf should not have been linked", names)
+		}
+		if _, err := os.Lstat(outside); !os.IsNotExist(err) {
+			t.Errorf("selection %s: nothing should have been created outside the target", names)
+		}
+	}
+}
+
+// TestRun_Format tests that --format and its deprecated alias --output both
+// select the output format
+func TestRun_Format(t *testing.T) {
+	sourceDir, targetDir := t.TempDir(), t.TempDir()
+	if err := os.WriteFile(filepath.Join(sourceDir, "a.conf"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, flag := range []string{"--format", "--output", "-o"} {
+		got, stdout, stderr := runLnkaOutput(t, sourceDir, targetDir, "--enable-all", "--print-selection", flag, "json")
+		if got != exitCodeOK {
+			t.Fatalf("%s: exit code = %d, want %d", flag, got, exitCodeOK)
+		}
+		if stdout != `["a.conf"]`+"\n" {
+			t.Errorf("%s: output = %q, want the JSON selection", flag, stdout)
+		}
+		if deprecated := strings.Contains(stderr, "deprecated"); deprecated != (flag != "--format") {
+			t.Errorf("%s: stderr = %q, deprecation notice %v", flag, stderr, deprecated)
+		}
+	}
+}
+
+// TestRun_Mkdir tests that --mkdir creates the target only when applying
+func TestRun_Mkdir(t *testing.T) {
+	sourceDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(sourceDir, "a.conf"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	targetDir := filepath.Join(t.TempDir(), "etc", "enabled")
+
+	if got := runLnka(t, sourceDir, targetDir, "--mkdir", "--enable", "a.conf", "--dry-run"); got != 0 {
+		t.Errorf("dry run: exit code = %d, want 0", got)
+	}
+	if _, err := os.Stat(targetDir); !os.IsNotExist(err) {
+		t.Errorf("dry run created the target directory: %v", err)
+	}
+
+	if got := runLnka(t, sourceDir, targetDir, "--mkdir", "--enable", "a.conf"); got != 0 {
+		t.Errorf("apply: exit code = %d, want 0", got)
+	}
+	if _, err := os.Lstat(filepath.Join(targetDir, "a.conf")); err != nil {
+		t.Errorf("apply did not link a.conf into the created target: %v", err)
+	}
+}
+
+// TestRun_EmitSystemd tests that emitting the plan leaves the target as it is,
+// leftovers included
+func TestRun_EmitSystemd(t *testing.T) {
+	sourceDir, targetDir := t.TempDir(), t.TempDir()
+	if err := os.WriteFile(filepath.Join(sourceDir, "a.service"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	orphan := filepath.Join(targetDir, "gone.service")
+	if err := os.Symlink(filepath.Join(sourceDir, "gone.service"), orphan); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := runLnka(t, sourceDir, targetDir, "--emit-systemd", "--enable", "a.service", "--yes"); got != 0 {
+		t.Errorf("exit code = %d, want 0", got)
+	}
+	if _, err := os.Lstat(orphan); err != nil {
+		t.Errorf("the broken link was cleaned up: %v", err)
+	}
+	if _, err := os.Lstat(filepath.Join(targetDir, "a.service")); !os.IsNotExist(err) {
+		t.Errorf("a.service was linked: %v", err)
+	}
+}
+
+// TestRun_Quiet tests that a quiet apply prints nothing on success and that
+// the UI cannot be quiet
+func TestRun_Quiet(t *testing.T) {
+	sourceDir, targetDir := t.TempDir(), t.TempDir()
+	if err := os.WriteFile(filepath.Join(sourceDir, "a.conf"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(sourceDir, "gone.conf"), filepath.Join(targetDir, "gone.conf")); err != nil {
+		t.Fatal(err)
+	}
+
+	got, stdout, _ := runLnkaOutput(t, sourceDir, targetDir, "--enable", "a.conf", "--yes", "-q")
+	if got != exitCodeOK {
+		t.Fatalf("exit code = %d, want %d", got, exitCodeOK)
+	}
+	if stdout != "" {
+		t.Errorf("stdout = %q, want nothing", stdout)
+	}
+	if _, err := os.Lstat(filepath.Join(targetDir, "a.conf")); err != nil {
+		t.Errorf("a.conf was not linked: %v", err)
+	}
+	if _, err := os.Lstat(filepath.Join(targetDir, "gone.conf")); !os.IsNotExist(err) {
+		t.Errorf("the broken link was not cleaned up: %v", err)
+	}
+
+	stubPrompts(t, nil, nil, false)
+	got, _, stderr := runLnkaOutput(t, sourceDir, targetDir, "--quiet")
+	if got != exitCodeError || !strings.Contains(stderr, "--quiet requires a selection without the UI") {
+		t.Errorf("quiet UI exit code = %d, stderr = %q, want %d and the error", got, stderr, exitCodeError)
+	}
+}
+
+// TestWriteChangeSummary tests the JSON summary of applied changes
+func TestWriteChangeSummary(t *testing.T) {
+	result := &filesystem.ChangeResult{
+		Created:   []string{"new.conf"},
+		Removed:   []string{"old.conf"},
+		Unchanged: []string{"kept.conf"},
+	}
+
+	var buf bytes.Buffer
+	if err := writeChangeSummary(&buf, result); err != nil {
+		t.Fatalf("writeChangeSummary failed: %v", err)
+	}
+
+	want := `{"created":["new.conf"],"removed":["old.conf"],"unchanged":["kept.conf"],"refused":[],"relinked":[],"renamed":[],"modeChanged":[],"failed":[],"backedUp":[]}` + "\n"
+	if buf.String() != want {
+		t.Errorf("writeChangeSummary() = %s, want %s", buf.String(), want)
+	}
+	if result.Relinked != nil {
+		t.Error("writeChangeSummary() modified the result")
+	}
+}
+
+// TestRun_MultipleTargets tests applying one selection to several targets
+func TestRun_MultipleTargets(t *testing.T) {
+	sourceDir, staging, prod := t.TempDir(), t.TempDir(), t.TempDir()
+	for _, name := range []string{"a.conf", "b.conf"} {
+		if err := os.WriteFile(filepath.Join(sourceDir, name), []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink(filepath.Join(sourceDir, "b.conf"), filepath.Join(staging, "b.conf")); err != nil {
+		t.Fatal(err)
+	}
+
+	// Pending changes in any target are reported
+	if got := runLnka(t, sourceDir, staging, prod, "--dry-run", "--detailed-exitcode", "--enable", "b.conf"); got != exitCodeChangesPending {
+		t.Errorf("dry run exit code = %d, want %d", got, exitCodeChangesPending)
+	}
+
+	// Each target keeps its own links next to the enabled file and is
+	// cleaned up under its own heading
+	orphan := filepath.Join(prod, "gone.conf")
+	if err := os.Symlink(filepath.Join(sourceDir, "gone.conf"), orphan); err != nil {
+		t.Fatal(err)
+	}
+	got, stdout, _ := runLnkaOutput(t, sourceDir, staging, prod, "--enable", "a.conf", "--yes")
+	if got != exitCodeOK {
+		t.Fatalf("exit code = %d, want %d", got, exitCodeOK)
+	}
+	for _, dir := range []string{staging, prod} {
+		if n := strings.Count(stdout, "==> "+dir+"\n"); n != 1 {
+			t.Errorf("heading of %s printed %d times, want once:\n%s", dir, n, stdout)
+		}
+	}
+	if !strings.HasSuffix(stdout, "2 targets, 2 created, 0 removed total\n") {
+		t.Errorf("output does not end with the totals:\n%s", stdout)
+	}
+	if cleaned := strings.Index(stdout, "Cleaned 1 leftover(s)"); cleaned < strings.Index(stdout, "==> "+prod) {
+		t.Errorf("cleanup of %s is not reported under its heading:\n%s", prod, stdout)
+	}
+	if _, err := os.Lstat(orphan); !os.IsNotExist(err) {
+		t.Errorf("broken link in %s was not cleaned up: %v", prod, err)
+	}
+	for dir, want := range map[string][]string{staging: {"a.conf", "b.conf"}, prod: {"a.conf"}} {
+		enabled, err := filesystem.GetEnabledFiles(sourceDir, dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(enabled, want) {
+			t.Errorf("enabled files in %s = %v, want %v", dir, enabled, want)
+		}
+	}
+}
+
+// TestFormatTargetSections tests the headings and the totals line of the
+// text output for two targets
+func TestFormatTargetSections(t *testing.T) {
+	home := filepath.Join(string(filepath.Separator), "home", "me")
+	tests := []struct {
+		dir  string
+		want string
+	}{
+		{dir: filepath.Join(home, "staging"), want: "==> " + filepath.Join("~", "staging")},
+		{dir: home, want: "==> ~"},
+		{dir: home + "-other", want: "==> " + home + "-other"},
+		{dir: filepath.Join(string(filepath.Separator), "srv", "prod"), want: "==> " + filepath.Join(string(filepath.Separator), "srv", "prod")},
+	}
+	for _, tt := range tests {
+		if got := formatTargetHeading(tt.dir, home, lipgloss.NewStyle()); got != tt.want {
+			t.Errorf("formatTargetHeading(%q) = %q, want %q", tt.dir, got, tt.want)
+		}
+	}
+
+	summaries := []targetSummary{
+		{Target: "/staging", ChangeResult: &filesystem.ChangeResult{Created: []string{"a.conf", "b.conf"}, Removed: []string{"old.conf"}}},
+		{Target: "/prod", ChangeResult: &filesystem.ChangeResult{Created: []string{"a.conf"}, Unchanged: []string{"b.conf"}}},
+	}
+	if got, want := formatTotals(summaries, false), "2 targets, 3 created, 1 removed total"; got != want {
+		t.Errorf("formatTotals() = %q, want %q", got, want)
+	}
+	if got, want := formatTotals(summaries, true), "2 targets, 3 to create, 1 to remove total"; got != want {
+		t.Errorf("formatTotals() of a dry run = %q, want %q", got, want)
+	}
+}
+
+// TestWriteChangeSummaries tests that several targets get one JSON array
+func TestWriteChangeSummaries(t *testing.T) {
+	summaries := []targetSummary{
+		{Target: "/staging", ChangeResult: &filesystem.ChangeResult{Created: []string{"a.conf"}}},
+		{Target: "/prod", ChangeResult: &filesystem.ChangeResult{}},
+	}
+
+	var buf bytes.Buffer
+	if err := writeChangeSummaries(&buf, summaries, true); err != nil {
+		t.Fatalf("writeChangeSummaries failed: %v", err)
+	}
+	var got []map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("output %s is no JSON array: %v", buf.String(), err)
+	}
+	if len(got) != 2 || got[0]["target"] != "/staging" || got[1]["target"] != "/prod" {
+		t.Errorf("summaries = %v, want one per target", got)
+	}
+	if created, ok := got[1]["created"].([]any); !ok || len(created) != 0 {
+		t.Errorf("created of /prod = %v, want an empty array", got[1]["created"])
+	}
+
+	buf.Reset()
+	if err := writeChangeSummaries(&buf, summaries[:1], false); err != nil {
+		t.Fatalf("writeChangeSummaries failed: %v", err)
+	}
+	if !strings.HasPrefix(buf.String(), `{"created":["a.conf"]`) {
+		t.Errorf("single target output = %s, want the summary object", buf.String())
+	}
+}
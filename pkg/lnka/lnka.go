@@ -0,0 +1,157 @@
+// Package lnka provides the symlink operations of the lnka CLI for use in
+// other programs, e.g. a different front-end.
+//
+// A source directory holds the available files, a target directory holds
+// links to the enabled ones (like nginx sites-available and sites-enabled).
+// ApplyChanges reconciles the target with a selection of source files,
+// creating the missing links and removing managed links that are no longer
+// selected:
+//
+//	enabled, err := lnka.GetEnabledFiles(sourceDir, targetDir)
+//	if err != nil {
+//	    return err
+//	}
+//	result, err := lnka.ApplyChanges(sourceDir, targetDir, append(enabled, "new.conf"))
+//
+// The *WithOptions variants take the options of the CLI flags (link mode,
+// recursion, filters, renaming, ...). Their zero value gives the default
+// behavior of the variants without options.
+//
+// The types are aliases of those of the CLI's implementation, so their fields
+// follow the CLI and may change between releases until the module reaches
+// v1. Pin a version if you depend on them.
+package lnka
+
+import (
+	"github.com/marco-arnold/lnka/internal/filesystem"
+)
+
+// Options controls how symlinks are created and recognized
+// (see the field documentation for each option)
+type Options = filesystem.Options
+
+// ApplyOptions controls how ApplyChangesWithOptions reconciles the target
+// directory, e.g. DryRun or ContinueOnError
+type ApplyOptions = filesystem.ApplyOptions
+
+// ChangeSet describes the operations needed to reach a selection
+type ChangeSet = filesystem.ChangeSet
+
+// ChangeResult reports the operations performed by ApplyChanges
+type ChangeResult = filesystem.ChangeResult
+
+// LinkMode selects how enabled files are placed in the target directory
+type LinkMode = filesystem.LinkMode
+
+// Link modes of Options.Mode
+const (
+	LinkModeSymlink  = filesystem.LinkModeSymlink  // Symlink to the source file (default)
+	LinkModeCopy     = filesystem.LinkModeCopy     // Regular copy, for filesystems without symlinks
+	LinkModeHardlink = filesystem.LinkModeHardlink // Hard link, source and target must share a filesystem
+)
+
+// LinkStyle selects auto, relative or absolute symlink targets
+type LinkStyle = filesystem.LinkStyle
+
+// Link styles of Options.Style
+const (
+	LinkStyleAuto     = filesystem.LinkStyleAuto     // Relative path, absolute when too many levels up (default)
+	LinkStyleRelative = filesystem.LinkStyleRelative // Path relative to the link's directory
+	LinkStyleAbsolute = filesystem.LinkStyleAbsolute // Absolute path of the source file
+)
+
+// DefaultMaxUpLevels is the ".." limit of relative targets used with the auto
+// style when Options.MaxUpLevels is zero
+const DefaultMaxUpLevels = filesystem.DefaultMaxUpLevels
+
+// RenamePattern maps source file names to different link names
+// (see ParseRenamePattern)
+type RenamePattern = filesystem.RenamePattern
+
+// ParseLinkMode parses a link mode name ("symlink", "copy" or "hardlink")
+func ParseLinkMode(s string) (LinkMode, error) {
+	return filesystem.ParseLinkMode(s)
+}
+
+// ParseLinkStyle parses a link style name ("auto", "relative" or "absolute")
+func ParseLinkStyle(s string) (LinkStyle, error) {
+	return filesystem.ParseLinkStyle(s)
+}
+
+// ParseRenamePattern parses a sed-style substitution s/regex/replacement/[g]
+// for Options.Rename
+func ParseRenamePattern(spec string) (*RenamePattern, error) {
+	return filesystem.ParseRenamePattern(spec)
+}
+
+// ListAvailableFiles lists all files (not directories) in the source
+// directory, which can be enabled
+func ListAvailableFiles(sourceDir string) ([]string, error) {
+	return filesystem.ListAvailableFiles(sourceDir)
+}
+
+// ListAvailableFilesWithOptions lists the available files like
+// ListAvailableFiles, honoring the given options
+func ListAvailableFilesWithOptions(sourceDir string, opts Options) ([]string, error) {
+	return filesystem.ListAvailableFilesWithOptions(sourceDir, opts)
+}
+
+// GetEnabledFiles returns the names of the source files linked in the
+// target directory
+func GetEnabledFiles(sourceDir, targetDir string) ([]string, error) {
+	return filesystem.GetEnabledFiles(sourceDir, targetDir)
+}
+
+// GetEnabledFilesWithOptions returns the enabled files like GetEnabledFiles,
+// honoring the given options
+func GetEnabledFilesWithOptions(sourceDir, targetDir string, opts Options) ([]string, error) {
+	return filesystem.GetEnabledFilesWithOptions(sourceDir, targetDir, opts)
+}
+
+// CreateSymlink links the source file filename into the target directory
+func CreateSymlink(sourceDir, targetDir, filename string) error {
+	return filesystem.CreateSymlink(sourceDir, targetDir, filename)
+}
+
+// CreateSymlinkWithOptions links the file like CreateSymlink, honoring the
+// given options
+func CreateSymlinkWithOptions(sourceDir, targetDir, filename string, opts Options) error {
+	return filesystem.CreateSymlinkWithOptions(sourceDir, targetDir, filename, opts)
+}
+
+// RemoveSymlink removes the symlink filename from the target directory.
+// A missing link is no error, anything but a symlink is never removed.
+func RemoveSymlink(targetDir, filename string) error {
+	return filesystem.RemoveSymlink(targetDir, filename)
+}
+
+// ValidateSymlinks returns the names of the broken symlinks in the target
+// directory (pointing to files missing from the source)
+func ValidateSymlinks(sourceDir, targetDir string) ([]string, error) {
+	return filesystem.ValidateSymlinks(sourceDir, targetDir)
+}
+
+// ValidateSymlinksWithOptions finds broken symlinks like ValidateSymlinks,
+// honoring the given options
+func ValidateSymlinksWithOptions(sourceDir, targetDir string, opts Options) ([]string, error) {
+	return filesystem.ValidateSymlinksWithOptions(sourceDir, targetDir, opts)
+}
+
+// PlanChanges computes the changes ApplyChanges would make for the selection
+// without touching the filesystem
+func PlanChanges(sourceDir, targetDir string, selectedFiles []string, opts Options) (*ChangeSet, error) {
+	return filesystem.PlanChanges(sourceDir, targetDir, selectedFiles, opts)
+}
+
+// ApplyChanges links the selected files in the target directory and removes
+// the links to the other source files
+func ApplyChanges(sourceDir, targetDir string, selectedFiles []string) (*ChangeResult, error) {
+	return filesystem.ApplyChanges(sourceDir, targetDir, selectedFiles)
+}
+
+// ApplyChangesWithOptions applies the selection like ApplyChanges, honoring
+// the given options. The result lists what was done (or would be done with
+// DryRun), even if an error occurred.
+func ApplyChangesWithOptions(sourceDir, targetDir string, selectedFiles []string, opts ApplyOptions) (*ChangeResult, error) {
+	return filesystem.ApplyChangesWithOptions(sourceDir, targetDir, selectedFiles, opts)
+}
@@ -0,0 +1,64 @@
+package lnka
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// TestApplyChanges tests reconciling a target through the public API
+func TestApplyChanges(t *testing.T) {
+	sourceDir, targetDir := t.TempDir(), t.TempDir()
+	for _, name := range []string{"a.conf", "b.conf", "c.conf"} {
+		if err := os.WriteFile(filepath.Join(sourceDir, name), []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := CreateSymlink(sourceDir, targetDir, "c.conf"); err != nil {
+		t.Fatalf("CreateSymlink() error = %v", err)
+	}
+
+	available, err := ListAvailableFiles(sourceDir)
+	if err != nil {
+		t.Fatalf("ListAvailableFiles() error = %v", err)
+	}
+	if want := []string{"a.conf", "b.conf", "c.conf"}; !reflect.DeepEqual(available, want) {
+		t.Errorf("ListAvailableFiles() = %v, want %v", available, want)
+	}
+
+	// A dry run only reports the plan
+	result, err := ApplyChangesWithOptions(sourceDir, targetDir, []string{"a.conf"}, ApplyOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("ApplyChangesWithOptions() error = %v", err)
+	}
+	if !reflect.DeepEqual(result.Created, []string{"a.conf"}) || !reflect.DeepEqual(result.Removed, []string{"c.conf"}) {
+		t.Errorf("dry run result = %+v, want a.conf created and c.conf removed", result)
+	}
+
+	if _, err := ApplyChanges(sourceDir, targetDir, []string{"a.conf"}); err != nil {
+		t.Fatalf("ApplyChanges() error = %v", err)
+	}
+	enabled, err := GetEnabledFiles(sourceDir, targetDir)
+	if err != nil {
+		t.Fatalf("GetEnabledFiles() error = %v", err)
+	}
+	if want := []string{"a.conf"}; !reflect.DeepEqual(enabled, want) {
+		t.Errorf("GetEnabledFiles() = %v, want %v", enabled, want)
+	}
+
+	// Removing the source file breaks its link
+	if err := os.Remove(filepath.Join(sourceDir, "a.conf")); err != nil {
+		t.Fatal(err)
+	}
+	broken, err := ValidateSymlinks(sourceDir, targetDir)
+	if err != nil {
+		t.Fatalf("ValidateSymlinks() error = %v", err)
+	}
+	if want := []string{"a.conf"}; !reflect.DeepEqual(broken, want) {
+		t.Errorf("ValidateSymlinks() = %v, want %v", broken, want)
+	}
+	if err := RemoveSymlink(targetDir, "a.conf"); err != nil {
+		t.Fatalf("RemoveSymlink() error = %v", err)
+	}
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/marco-arnold/lnka/internal/config"
+	"github.com/marco-arnold/lnka/internal/filesystem"
+	"github.com/spf13/cobra"
+)
+
+var statusCmd = &cobra.Command{
+	Use:   "status SOURCE TARGET",
+	Short: "List the link state of all source files without the UI",
+	Args:  cobra.ExactArgs(2),
+	RunE:  quietRunE(runStatus),
+}
+
+func init() {
+	statusCmd.Flags().String("format", config.OutputText, "Output format: text or json")
+	rootCmd.AddCommand(statusCmd)
+}
+
+// statusMarker returns the text label of the state of a file
+func statusMarker(s filesystem.FileStatus) string {
+	switch {
+	case s.Broken:
+		return "broken"
+	case s.Enabled:
+		return "linked"
+	case s.Mismatched:
+		return "mismatched"
+	default:
+		return "not linked"
+	}
+}
+
+func runStatus(cmd *cobra.Command, args []string) error {
+	format, err := cmd.Flags().GetString("format")
+	if err != nil {
+		return fmt.Errorf("failed to get format flag: %w", err)
+	}
+	if format != config.OutputText && format != config.OutputJSON {
+		return fmt.Errorf("invalid format %q: expected %s or %s", format, config.OutputText, config.OutputJSON)
+	}
+
+	sourceDir, targetDir := args[0], args[1]
+	if err := filesystem.CheckDirExists(sourceDir); err != nil {
+		return fmt.Errorf("source directory error: %w", err)
+	}
+	if err := filesystem.CheckDirExists(targetDir); err != nil {
+		return fmt.Errorf("target directory error: %w", err)
+	}
+
+	// Links are recognized with the options of the last apply (e.g. --rename)
+	entries, err := filesystem.CollectStatus(sourceDir, targetDir, recordedOptions(sourceDir, targetDir))
+	if err != nil {
+		return fmt.Errorf("failed to collect status: %w", err)
+	}
+	return writeStatus(os.Stdout, entries, format)
+}
+
+// writeStatus writes the entries as aligned columns or as a JSON array
+func writeStatus(w io.Writer, entries []filesystem.FileStatus, format string) error {
+	if format == config.OutputJSON {
+		data, err := json.Marshal(entries)
+		if err != nil {
+			return fmt.Errorf("failed to encode status: %w", err)
+		}
+		_, err = fmt.Fprintln(w, string(data))
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, e := range entries {
+		if e.Target != "" {
+			fmt.Fprintf(tw, "%s\t%s\t-> %s\n", statusMarker(e), e.Name, e.Target)
+		} else {
+			fmt.Fprintf(tw, "%s\t%s\t\n", statusMarker(e), e.Name)
+		}
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/marco-arnold/lnka/internal/config"
+	"github.com/marco-arnold/lnka/internal/filesystem"
+)
+
+// TestWriteStatus tests the text and JSON status output
+func TestWriteStatus(t *testing.T) {
+	entries := []filesystem.FileStatus{
+		{Name: "a.conf", Enabled: true, Target: "../src/a.conf", Size: 12, ModTime: time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)},
+		{Name: "longer.conf"},
+		{Name: "old.conf", Mismatched: true, Target: "/old/old.conf"},
+	}
+
+	var text bytes.Buffer
+	if err := writeStatus(&text, entries, config.OutputText); err != nil {
+		t.Fatalf("writeStatus(text) failed: %v", err)
+	}
+	lines := strings.Split(strings.TrimRight(text.String(), "\n"), "\n")
+	wantLines := []string{
+		"linked      a.conf       -> ../src/a.conf",
+		"not linked  longer.conf",
+		"mismatched  old.conf     -> /old/old.conf",
+	}
+	for i := range lines {
+		lines[i] = strings.TrimRight(lines[i], " ")
+	}
+	if !reflect.DeepEqual(lines, wantLines) {
+		t.Errorf("text output = %q, want %q", lines, wantLines)
+	}
+
+	var js bytes.Buffer
+	if err := writeStatus(&js, entries, config.OutputJSON); err != nil {
+		t.Fatalf("writeStatus(json) failed: %v", err)
+	}
+	want := `[{"name":"a.conf","enabled":true,"broken":false,"mismatched":false,"target":"../src/a.conf","size":12,"mtime":"2024-05-10T12:00:00Z"},` +
+		`{"name":"longer.conf","enabled":false,"broken":false,"mismatched":false,"target":"","size":0},` +
+		`{"name":"old.conf","enabled":false,"broken":false,"mismatched":true,"target":"/old/old.conf","size":0}]` + "\n"
+	if js.String() != want {
+		t.Errorf("json output = %s, want %s", js.String(), want)
+	}
+}
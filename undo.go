@@ -0,0 +1,83 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/marco-arnold/lnka/internal/filesystem"
+	"github.com/spf13/cobra"
+)
+
+var undoCmd = &cobra.Command{
+	Use:   "undo TARGET",
+	Short: "Restore the links of the target directory before the last apply",
+	Args:  cobra.ExactArgs(1),
+	RunE:  quietRunE(runUndo),
+}
+
+func init() {
+	rootCmd.AddCommand(undoCmd)
+}
+
+func runUndo(cmd *cobra.Command, args []string) error {
+	targetDir := args[0]
+	if err := filesystem.CheckDirExists(targetDir); err != nil {
+		return fmt.Errorf("target directory error: %w", err)
+	}
+
+	record, err := filesystem.LoadUndoRecord(targetDir)
+	if err != nil {
+		if errors.Is(err, filesystem.ErrNoUndoRecord) {
+			return fmt.Errorf("nothing to undo: no apply to %s has been recorded", targetDir)
+		}
+		return err
+	}
+
+	// The links get back the names they had before the apply
+	opts := record.Options()
+	opts.LinkNames, opts.PreviousLinkNames = record.PreviousLinkNames, record.LinkNames
+	opts.Warnf = warnf
+	available, err := filesystem.ListAvailableFilesWithOptions(record.SourceDir, opts)
+	if err != nil {
+		return fmt.Errorf("failed to list available files: %w", err)
+	}
+	selection := undoSelection(record.Previous, available, warnf)
+
+	current, err := filesystem.GetEnabledFilesWithOptions(record.SourceDir, targetDir, opts)
+	if err != nil {
+		return fmt.Errorf("failed to get currently enabled files: %w", err)
+	}
+
+	result, err := filesystem.ApplyChangesWithOptions(record.SourceDir, targetDir, selection, filesystem.ApplyOptions{
+		Options: opts,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to undo changes: %w", err)
+	}
+
+	// Undoing again restores the undone apply
+	recordUndo(record.SourceDir, targetDir, current, result, opts)
+
+	fmt.Printf("Undo: created %d and removed %d symlink(s), %d unchanged\n",
+		len(result.Created), len(result.Removed), len(result.Unchanged))
+	return nil
+}
+
+// undoSelection keeps the previously enabled files that are still available,
+// reporting each skipped file through warn
+func undoSelection(previous, available []string, warn func(format string, args ...any)) []string {
+	isAvailable := make(map[string]bool, len(available))
+	for _, name := range available {
+		isAvailable[name] = true
+	}
+
+	selection := make([]string, 0, len(previous))
+	for _, name := range previous {
+		if !isAvailable[name] {
+			warn("%s was deleted from the source directory, not restoring its link", name)
+			continue
+		}
+		selection = append(selection, name)
+	}
+	return selection
+}
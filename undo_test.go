@@ -0,0 +1,150 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/marco-arnold/lnka/internal/filesystem"
+	"github.com/marco-arnold/lnka/internal/testutil"
+)
+
+// TestUndoSelection tests skipping files deleted from the source since the apply
+func TestUndoSelection(t *testing.T) {
+	var warnings []string
+	warn := func(format string, args ...any) {
+		warnings = append(warnings, fmt.Sprintf(format, args...))
+	}
+
+	got := undoSelection([]string{"a.conf", "gone.conf"}, []string{"a.conf", "b.conf"}, warn)
+	if want := []string{"a.conf"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("undoSelection() = %v, want %v", got, want)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "gone.conf") {
+		t.Errorf("warnings = %v, want one warning about gone.conf", warnings)
+	}
+}
+
+// TestRunUndo tests restoring the links of the last recorded apply
+func TestRunUndo(t *testing.T) {
+	testutil.UseTempConfigDir(t)
+
+	sourceDir := t.TempDir()
+	targetDir := t.TempDir()
+	for _, name := range []string{"a.conf", "b.conf"} {
+		if err := os.WriteFile(filepath.Join(sourceDir, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := runUndo(undoCmd, []string{targetDir}); err == nil || !strings.Contains(err.Error(), "nothing to undo") {
+		t.Fatalf("runUndo() error = %v, want nothing to undo", err)
+	}
+
+	if _, err := filesystem.ApplyChanges(sourceDir, targetDir, []string{"a.conf"}); err != nil {
+		t.Fatal(err)
+	}
+	result, err := filesystem.ApplyChanges(sourceDir, targetDir, []string{"b.conf"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	recordUndo(sourceDir, targetDir, []string{"a.conf"}, result, filesystem.Options{})
+
+	if err := runUndo(undoCmd, []string{targetDir}); err != nil {
+		t.Fatalf("runUndo failed: %v", err)
+	}
+	enabled, err := filesystem.GetEnabledFiles(sourceDir, targetDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"a.conf"}; !reflect.DeepEqual(enabled, want) {
+		t.Errorf("enabled after undo = %v, want %v", enabled, want)
+	}
+
+	// Undoing the undo restores the original apply
+	if err := runUndo(undoCmd, []string{targetDir}); err != nil {
+		t.Fatalf("runUndo failed: %v", err)
+	}
+	enabled, err = filesystem.GetEnabledFiles(sourceDir, targetDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"b.conf"}; !reflect.DeepEqual(enabled, want) {
+		t.Errorf("enabled after second undo = %v, want %v", enabled, want)
+	}
+}
+
+// TestRunUndo_LinkNames tests that undo gives renamed links their old name back
+func TestRunUndo_LinkNames(t *testing.T) {
+	testutil.UseTempConfigDir(t)
+
+	sourceDir := t.TempDir()
+	targetDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(sourceDir, "a.conf"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	old := map[string]string{"a.conf": "old.conf"}
+	if _, err := filesystem.ApplyChangesWithOptions(sourceDir, targetDir, []string{"a.conf"}, filesystem.ApplyOptions{Options: filesystem.Options{LinkNames: old}}); err != nil {
+		t.Fatal(err)
+	}
+	opts := filesystem.Options{LinkNames: map[string]string{"a.conf": "new.conf"}, PreviousLinkNames: old}
+	result, err := filesystem.ApplyChangesWithOptions(sourceDir, targetDir, []string{"a.conf"}, filesystem.ApplyOptions{Options: opts})
+	if err != nil {
+		t.Fatal(err)
+	}
+	recordUndo(sourceDir, targetDir, []string{"a.conf"}, result, opts)
+
+	// The next run keeps the custom name
+	if linkNames, previous := recordedLinkNames(sourceDir, targetDir); !reflect.DeepEqual(linkNames, opts.LinkNames) || !reflect.DeepEqual(previous, opts.LinkNames) {
+		t.Errorf("recordedLinkNames() = %v, %v, want %v", linkNames, previous, opts.LinkNames)
+	}
+
+	if err := runUndo(undoCmd, []string{targetDir}); err != nil {
+		t.Fatalf("runUndo failed: %v", err)
+	}
+	if _, err := os.Lstat(filepath.Join(targetDir, "old.conf")); err != nil {
+		t.Errorf("old.conf should be restored: %v", err)
+	}
+	if _, err := os.Lstat(filepath.Join(targetDir, "new.conf")); !os.IsNotExist(err) {
+		t.Errorf("new.conf should be removed, Lstat err = %v", err)
+	}
+}
+
+// TestRunUndo_Exclude tests that undo leaves the links of files the apply
+// excluded alone
+func TestRunUndo_Exclude(t *testing.T) {
+	testutil.UseTempConfigDir(t)
+
+	sourceDir := t.TempDir()
+	targetDir := t.TempDir()
+	for _, name := range []string{"a.conf", "b.conf"} {
+		if err := os.WriteFile(filepath.Join(sourceDir, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := filesystem.CreateSymlink(sourceDir, targetDir, "b.conf"); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := filesystem.Options{Exclude: []string{"b.*"}}
+	result, err := filesystem.ApplyChangesWithOptions(sourceDir, targetDir, []string{"a.conf"}, filesystem.ApplyOptions{Options: opts})
+	if err != nil {
+		t.Fatal(err)
+	}
+	recordUndo(sourceDir, targetDir, nil, result, opts)
+
+	if err := runUndo(undoCmd, []string{targetDir}); err != nil {
+		t.Fatalf("runUndo failed: %v", err)
+	}
+	enabled, err := filesystem.GetEnabledFiles(sourceDir, targetDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"b.conf"}; !reflect.DeepEqual(enabled, want) {
+		t.Errorf("enabled after undo = %v, want %v", enabled, want)
+	}
+}